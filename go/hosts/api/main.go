@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/mariadbadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archiveapi"
+)
+
+const listenAddr = ":8080"
+
+func main() {
+	log.Println("Connecting to database...")
+	dbconfig := &mariadbadapter.Config{
+		Addr:                  "127.0.0.1:3306",
+		DBName:                "tbtlarchivist",
+		User:                  "root",
+		MaxConnectionLifetime: 60 * time.Second,
+		MaxOpenConnections:    5,
+		MaxIdleConnections:    5,
+	}
+	mariadb := mariadbadapter.New(dbconfig)
+
+	log.Println("Migrating database...")
+	err := mariadb.Migrate(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := mariadb.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{
+		Addr:         listenAddr,
+		Handler:      archiveapi.NewHandler(db),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	log.Printf("Serving the archive API on %v...", listenAddr)
+	log.Fatal(server.ListenAndServe())
+}
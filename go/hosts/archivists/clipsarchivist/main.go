@@ -8,6 +8,7 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/mariadbadapter"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archivists"
 )
 
@@ -21,13 +22,26 @@ func main() {
 		MaxOpenConnections:    5,
 		MaxIdleConnections:    5,
 	}
-	db, err := mariadbadapter.New(dbconfig).Connect()
+	mariadb := mariadbadapter.New(dbconfig)
+
+	log.Println("Migrating database...")
+	err := mariadb.Migrate(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := mariadb.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	busconfig, err := amqpadapter.ConfigFromEnv("clipsarchivist")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	log.Println("Connecting to message bus...")
-	msgbus, err := amqpadapter.Initialize(context.Background(), "curated_clips", 5)
+	msgbus, err := amqpadapter.Initialize(context.Background(), busconfig, amqpadapter.DefaultQueueConfig("curated_clips"), messagebustypes.DirectionReceiveOnly)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -36,8 +50,15 @@ func main() {
 	clipsArchivist := archivists.StartClipsArchivist(context.Background(), msgbus, db)
 
 	log.Println("Running...")
+	busEvents := msgbus.Events()
 	for {
 		select {
+		case event, open := <-busEvents:
+			if !open {
+				busEvents = nil
+				break
+			}
+			log.Println("Message bus", event)
 		case err, open := <-clipsArchivist.Errors:
 			if !open {
 				break
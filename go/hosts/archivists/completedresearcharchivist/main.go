@@ -1,31 +1,71 @@
 package main
 
-// import (
-// 	"context"
-// 	"log"
-
-// )
-
-// func main() {
-
-// 	log.Println("Connecting to message bus...")
-// 	msgbus, err := amqpadapter.Initialize(context.Background(), "completed_research", amqpadapter.DirectionSendOnly)
-// 	if err != nil {
-// 		log.Fatal(err)
-// 	}
-
-// 	log.Println("Starting completed-research archivist...")
-// 	completedResearchArchivist := archivists.StartCompletedResearchArchivist(context.Background(), msgbus, new(fakedatastore.FakeDataStorer))
-
-// 	log.Println("Running...")
-// 	for {
-// 		select {
-// 		case err, open := <-completedResearchArchivist.Errors:
-//			if !open { break }
-// 			log.Println(err)
-// 		case <-completedResearchArchivist.Done:
-// 			log.Println("Done")
-// 			return
-// 		}
-// 	}
-// }
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/mariadbadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archivists"
+)
+
+func main() {
+	log.Println("Connecting to database...")
+	dbconfig := &mariadbadapter.Config{
+		Addr:                  "127.0.0.1:3306",
+		DBName:                "tbtlarchivist",
+		User:                  "root",
+		MaxConnectionLifetime: 60 * time.Second,
+		MaxOpenConnections:    5,
+		MaxIdleConnections:    5,
+	}
+	mariadb := mariadbadapter.New(dbconfig)
+
+	log.Println("Migrating database...")
+	err := mariadb.Migrate(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := mariadb.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	busconfig, err := amqpadapter.ConfigFromEnv("completedresearcharchivist")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Connecting to message bus...")
+	msgbus, err := amqpadapter.Initialize(context.Background(), busconfig, amqpadapter.DefaultQueueConfig("completed_research"), messagebustypes.DirectionReceiveOnly)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Starting completed-research archivist...")
+	completedResearchArchivist := archivists.StartCompletedResearchArchivist(context.Background(), msgbus, db)
+
+	log.Println("Running...")
+	busEvents := msgbus.Events()
+	for {
+		select {
+		case event, open := <-busEvents:
+			if !open {
+				busEvents = nil
+				break
+			}
+			log.Println("Message bus", event)
+		case err, open := <-completedResearchArchivist.Errors:
+			if !open {
+				break
+			}
+			log.Println(err)
+		case <-completedResearchArchivist.Done:
+			log.Println("Done")
+			return
+		}
+	}
+}
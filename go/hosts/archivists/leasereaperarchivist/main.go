@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/mariadbadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archivists"
+)
+
+const reapInterval = 5 * time.Minute
+
+func main() {
+	log.Println("Connecting to database...")
+	dbconfig := &mariadbadapter.Config{
+		Addr:                  "127.0.0.1:3306",
+		DBName:                "tbtlarchivist",
+		User:                  "root",
+		MaxConnectionLifetime: 60 * time.Second,
+		MaxOpenConnections:    5,
+		MaxIdleConnections:    5,
+	}
+	mariadb := mariadbadapter.New(dbconfig)
+
+	log.Println("Migrating database...")
+	err := mariadb.Migrate(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := mariadb.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Starting lease reaper archivist...")
+	leaseReaperArchivist := archivists.StartLeaseReaperArchivist(context.Background(), db, reapInterval)
+
+	log.Println("Running...")
+	for {
+		select {
+		case err, open := <-leaseReaperArchivist.Errors:
+			if !open {
+				break
+			}
+			log.Println(err)
+		case reclaimed, open := <-leaseReaperArchivist.Reclaimed:
+			if !open {
+				break
+			}
+			if reclaimed > 0 {
+				log.Printf("Reclaimed %v expired leases.", reclaimed)
+			}
+		case <-leaseReaperArchivist.Done:
+			log.Println("Done")
+			return
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/mariadbadapter"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archivists"
 )
 
@@ -20,13 +21,26 @@ func main() {
 		MaxOpenConnections:    5,
 		MaxIdleConnections:    5,
 	}
-	db, err := mariadbadapter.New(dbconfig).Connect()
+	mariadb := mariadbadapter.New(dbconfig)
+
+	log.Println("Migrating database...")
+	err := mariadb.Migrate(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := mariadb.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	busconfig, err := amqpadapter.ConfigFromEnv("pendingresearcharchivist")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	log.Println("Connecting to message bus...")
-	msgbus, err := amqpadapter.Initialize(context.Background(), "pending_research", amqpadapter.DirectionSendOnly)
+	msgbus, err := amqpadapter.Initialize(context.Background(), busconfig, amqpadapter.DefaultQueueConfig("pending_research"), messagebustypes.DirectionSendOnly)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -35,8 +49,15 @@ func main() {
 	pendingResearchArchivist := archivists.StartPendingResearchArchivist(context.Background(), msgbus, db)
 
 	log.Println("Running...")
+	busEvents := msgbus.Events()
 	for {
 		select {
+		case event, open := <-busEvents:
+			if !open {
+				busEvents = nil
+				break
+			}
+			log.Println("Message bus", event)
 		case err, open := <-pendingResearchArchivist.Errors:
 			if !open {
 				break
@@ -5,12 +5,18 @@ import (
 	"log"
 
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/curators/clipcurators"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/curators/curatorbase"
 )
 
 func main() {
-	msgbus, err := amqpadapter.Initialize(context.Background(), "curated_clips", amqpadapter.DirectionSendOnly)
+	busconfig, err := amqpadapter.ConfigFromEnv("marsupialgurgle")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	msgbus, err := amqpadapter.Initialize(context.Background(), busconfig, amqpadapter.DefaultQueueConfig("curated_clips"), messagebustypes.DirectionSendOnly)
 	if err != nil {
 		log.Fatal(err)
 	}
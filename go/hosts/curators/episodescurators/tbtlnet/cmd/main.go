@@ -5,12 +5,18 @@ import (
 	"log"
 
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/curators/curatorbase"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/curators/episodecurators"
 )
 
 func main() {
-	msgbus, err := amqpadapter.Initialize(context.Background(), "curated_episodes", amqpadapter.DirectionSendOnly)
+	busconfig, err := amqpadapter.ConfigFromEnv("tbtlnet")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	msgbus, err := amqpadapter.Initialize(context.Background(), busconfig, amqpadapter.DefaultQueueConfig("curated_episodes"), messagebustypes.DirectionSendOnly)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,155 @@
+// Command deadletters lists, inspects, and replays messages that have been
+// dead-lettered from the message bus.
+//
+//	deadletters list <queue>
+//	deadletters inspect <queue> <id>
+//	deadletters replay <queue> [id...]
+//
+// If replay is not supplied any IDs, every dead-lettered message is replayed.
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// messageTypes maps each queue to the type of the messages it carries, for
+// messages whose envelope doesn't name their type (such as those published
+// without an envelope). A queue may carry more than one type (for instance,
+// completed_research also carries heartbeats and failures), so the envelope's
+// type is preferred.
+var messageTypes = map[string]func() proto.Message{
+	"curated_episodes":   func() proto.Message { return new(contracts.EpisodeInfo) },
+	"curated_clips":      func() proto.Message { return new(contracts.ClipInfo) },
+	"pending_research":   func() proto.Message { return new(contracts.PendingResearchItem) },
+	"completed_research": func() proto.Message { return new(contracts.CompletedResearchItem) },
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 3 {
+		usage()
+	}
+	command, queueName, ids := os.Args[1], os.Args[2], os.Args[3:]
+
+	busconfig, err := amqpadapter.ConfigFromEnv("deadletters")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	deadLetters, err := amqpadapter.OpenDeadLetterQueue(busconfig, queueName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer deadLetters.Close()
+
+	switch {
+	case command == "list" && len(ids) == 0:
+		err = list(deadLetters)
+	case command == "inspect" && len(ids) == 1:
+		err = inspect(deadLetters, queueName, ids[0])
+	case command == "replay":
+		var replayed int
+		replayed, err = deadLetters.Replay(ids...)
+		fmt.Printf("Replayed %v message(s).\n", replayed)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	log.Fatal("usage: deadletters list <queue> | inspect <queue> <id> | replay <queue> [id...]")
+}
+
+func list(deadLetters *amqpadapter.DeadLetterQueue) error {
+	messages, err := deadLetters.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tATTEMPTS\tREASON\tDEAD-LETTERED AT\tBYTES")
+	for _, message := range messages {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n",
+			message.ID,
+			message.Attempts,
+			message.Reason,
+			message.DeadLetteredAt.Format(time.RFC3339),
+			len(message.Body))
+	}
+	return w.Flush()
+}
+
+func inspect(deadLetters *amqpadapter.DeadLetterQueue, queueName, id string) error {
+	messages, err := deadLetters.List()
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if message.ID != id {
+			continue
+		}
+
+		fmt.Printf("ID:               %v\n", message.ID)
+		fmt.Printf("Original queue:   %v\n", message.OriginalQueue)
+		fmt.Printf("Reason:           %v\n", message.Reason)
+		fmt.Printf("Attempts:         %v\n", message.Attempts)
+		fmt.Printf("Dead-lettered at: %v\n", message.DeadLetteredAt.Format(time.RFC3339))
+		fmt.Printf("Message type:     %v (schema version %v)\n", message.Envelope.MessageType, message.Envelope.SchemaVersion)
+		fmt.Printf("Content type:     %v\n", message.Envelope.ContentType)
+		fmt.Printf("Sent at:          %v\n", message.Envelope.Timestamp.Format(time.RFC3339))
+		for key, value := range message.Envelope.Headers {
+			fmt.Printf("Header %v: %v\n", key, value)
+		}
+		fmt.Println()
+		fmt.Println(decode(&message.Envelope, queueName, message.Body))
+		return nil
+	}
+
+	return fmt.Errorf("message %v not found in %v", id, amqpadapter.DeadLetterQueueName(queueName))
+}
+
+// decode renders a message body as JSON if it can be unmarshalled as the
+// message type named by its envelope (or, if the envelope doesn't name one,
+// the queue's message type), and as a hex dump otherwise. Messages are often
+// dead-lettered because they can't be unmarshalled, so the fallback matters.
+func decode(envelope *messagebustypes.Envelope, queueName string, body []byte) string {
+	var message proto.Message
+	if envelope.MessageType != "" {
+		messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(envelope.MessageType))
+		if err != nil {
+			return fmt.Sprintf("Unable to decode message: %v\n%v", err, hex.Dump(body))
+		}
+		message = messageType.New().Interface()
+	} else {
+		newMessage, found := messageTypes[queueName]
+		if !found {
+			return hex.Dump(body)
+		}
+		message = newMessage()
+	}
+
+	err := proto.Unmarshal(body, message)
+	if err != nil {
+		return fmt.Sprintf("Unable to decode message: %v\n%v", err, hex.Dump(body))
+	}
+
+	return protojson.MarshalOptions{Multiline: true}.Format(message)
+}
@@ -3,36 +3,100 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst/adapters/cachedanalyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst/adapters/goanalyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/mediacache"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/researcher"
 )
 
 func main() {
+	busconfig, err := amqpadapter.ConfigFromEnv("researcher")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	log.Println("Connecting to pending-research queue...")
-	pendingQueue, err := amqpadapter.Initialize(context.Background(), "pending_research", amqpadapter.DirectionReceiveOnly)
+	pendingQueue, err := amqpadapter.Initialize(context.Background(), busconfig, amqpadapter.DefaultQueueConfig("pending_research"), messagebustypes.DirectionReceiveOnly)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	log.Println("Connecting to completed-research queue...")
-	completedQueue, err := amqpadapter.Initialize(context.Background(), "completed_research", amqpadapter.DirectionSendOnly)
+	completedQueue, err := amqpadapter.Initialize(context.Background(), busconfig, amqpadapter.DefaultQueueConfig("completed_research"), messagebustypes.DirectionSendOnly)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Opening the media cache...")
+	cacheConfig, err := mediacache.DefaultConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cache, err := mediacache.Open(cacheConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	log.Println("Starting the Research Agent...")
-	researchAgent := researcher.StartResearchAgent(context.Background(), pendingQueue, completedQueue, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	log.Println("Starting the Research Pool...")
+	researchPool := researcher.StartResearchPool(ctx, pendingQueue, completedQueue, &researcher.PoolConfig{
+		// Zero uses one analyzer per CPU.
+		Concurrency: 0,
+		NewAnalyzer: func() analyst.Analyzer {
+			// The cache hands media to the analyzer as local file paths.
+			return &cachedanalyst.Adapter{
+				Analyzer: &goanalyst.Adapter{Fetcher: new(goanalyst.FileFetcher)},
+				Cache:    cache,
+			}
+		},
+	})
+
+	// The first SIGINT or SIGTERM drains the pool, allowing research that's
+	// underway to complete. A second abandons it.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	draining := false
 
 	log.Println("Running...")
+	pendingEvents, completedEvents := pendingQueue.Events(), completedQueue.Events()
 	for {
 		select {
-		case err := <-researchAgent.Errors:
+		case event, open := <-pendingEvents:
+			if !open {
+				pendingEvents = nil
+				break
+			}
+			log.Println("Pending-research queue", event)
+		case event, open := <-completedEvents:
+			if !open {
+				completedEvents = nil
+				break
+			}
+			log.Println("Completed-research queue", event)
+		case sig := <-signals:
+			if draining {
+				log.Println("Received", sig, "while draining, abandoning research underway...")
+				cancel()
+				break
+			}
+			log.Println("Received", sig, "draining...")
+			draining = true
+			researchPool.Drain()
+		case err := <-researchPool.Errors:
 			if err == nil {
 				break
 			}
 			log.Println(err)
-		case <-researchAgent.Done:
+		case <-researchPool.Done:
 			log.Println("Done")
 			return
 		}
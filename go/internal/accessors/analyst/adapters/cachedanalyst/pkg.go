@@ -0,0 +1,178 @@
+// Package cachedanalyst provides an analyst.Analyzer that fetches episodes
+// and clips via a media cache, and hands them to another Analyzer as local
+// file paths.
+package cachedanalyst
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/mediacache"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+// The Adapter acquires the media described by a PendingResearchItem from a
+// Cache, and runs an Analyzer against the cached copies.
+type Adapter struct {
+	errorSource         chan (error)
+	completedItemSource chan (*contracts.CompletedResearchItem)
+	done                chan (struct{})
+
+	// Analyzer conducts the research. It must treat media URIs as paths on
+	// the local filesystem.
+	Analyzer analyst.Analyzer
+
+	// Cache supplies the media.
+	Cache *mediacache.Cache
+}
+
+// Run acquires the episode and clips from the Cache, then runs the Analyzer
+// with a copy of pendingResearch whose media URIs have been replaced with the
+// paths of the cached media. The Analyzer's CompletedResearchItems and errors
+// are forwarded, with the original EpisodeInfo and ClipInfo restored. If the
+// episode can't be acquired, a single error is emitted. Errors acquiring a
+// clip are emitted, and the remaining clips are still analyzed. Cached media
+// is verified against the MediaHash of its episode or clip, if it's known. The
+// media is released once the Analyzer is done.
+//
+// Failures to acquire media are emitted as analyst.ResearchErrors, which are
+// permanent if the media's server responded with a client error. The clips of
+// the Analyzer's ResearchErrors are restored in the same way as its
+// CompletedResearchItems.
+func (a *Adapter) Run(ctx context.Context, pendingResearch *contracts.PendingResearchItem) {
+	utils.PanicIfNil(a.Analyzer)
+	if a.Cache == nil {
+		panic("a nil Cache was supplied")
+	}
+
+	a.completedItemSource = make(chan *contracts.CompletedResearchItem)
+	a.errorSource = make(chan error)
+	a.done = make(chan struct{})
+	go func() {
+		defer close(a.completedItemSource)
+		defer close(a.errorSource)
+		defer close(a.done)
+
+		var entries []*mediacache.Entry
+		defer func() {
+			for _, entry := range entries {
+				if err := entry.Release(); err != nil {
+					a.errorSource <- err
+				}
+			}
+		}()
+
+		episode, err := a.Cache.Acquire(ctx, pendingResearch.GetEpisode().GetMediaUri(), pendingResearch.GetEpisode().GetMediaHash())
+		if err != nil {
+			a.errorSource <- &analyst.ResearchError{
+				Err: classify(fmt.Errorf("unable to cache episode: %w", err)),
+			}
+			return
+		}
+		entries = append(entries, episode)
+
+		localResearch := &contracts.PendingResearchItem{
+			LeaseId: pendingResearch.GetLeaseId(),
+			Episode: proto.Clone(pendingResearch.GetEpisode()).(*contracts.EpisodeInfo),
+		}
+		localResearch.Episode.MediaUri = episode.Path
+
+		// Clips with identical media share a path, so each is only analyzed
+		// once, and the results are reported for every clip sharing the path.
+		clipsByPath := make(map[string][]*contracts.ClipInfo)
+		for _, clipInfo := range pendingResearch.GetClips() {
+			clip, err := a.Cache.Acquire(ctx, clipInfo.GetMediaUri(), clipInfo.GetMediaHash())
+			if err != nil {
+				a.errorSource <- &analyst.ResearchError{
+					Clips:           []*contracts.ClipInfo{clipInfo},
+					ClipMediaFailed: true,
+					Err:             classify(fmt.Errorf("unable to cache clip %v: %w", clipInfo.GetTitle(), err)),
+				}
+				continue
+			}
+			entries = append(entries, clip)
+
+			if _, found := clipsByPath[clip.Path]; !found {
+				localClipInfo := proto.Clone(clipInfo).(*contracts.ClipInfo)
+				localClipInfo.MediaUri = clip.Path
+				localResearch.Clips = append(localResearch.Clips, localClipInfo)
+			}
+			clipsByPath[clip.Path] = append(clipsByPath[clip.Path], clipInfo)
+		}
+
+		if len(localResearch.Clips) == 0 {
+			return
+		}
+
+		a.Analyzer.Run(ctx, localResearch)
+
+		completedItemSource, errorSource := a.Analyzer.CompletedWorkItems(), a.Analyzer.Errors()
+		for completedItemSource != nil || errorSource != nil {
+			select {
+			case completedItem, open := <-completedItemSource:
+				if !open {
+					completedItemSource = nil
+					break
+				}
+				completedItem.EpisodeInfo = pendingResearch.GetEpisode()
+				for _, clipInfo := range clipsByPath[completedItem.GetClipInfo().GetMediaUri()] {
+					item := proto.Clone(completedItem).(*contracts.CompletedResearchItem)
+					item.ClipInfo = clipInfo
+					a.completedItemSource <- item
+				}
+			case err, open := <-errorSource:
+				if !open {
+					errorSource = nil
+					break
+				}
+				var researchErr *analyst.ResearchError
+				if errors.As(err, &researchErr) && researchErr.Clips != nil {
+					clips := []*contracts.ClipInfo{}
+					for _, localClipInfo := range researchErr.Clips {
+						clips = append(clips, clipsByPath[localClipInfo.GetMediaUri()]...)
+					}
+					err = &analyst.ResearchError{
+						Clips:           clips,
+						ClipMediaFailed: researchErr.ClipMediaFailed,
+						Err:             researchErr.Err,
+					}
+				}
+				if err != nil {
+					a.errorSource <- err
+				}
+			}
+		}
+		<-a.Analyzer.Done()
+	}()
+}
+
+// classify marks err as permanent if it was caused by a client error response
+// to a request for media.
+func classify(err error) error {
+	var statusErr *mediacache.StatusError
+	if errors.As(err, &statusErr) && analyst.IsPermanentStatus(statusErr.StatusCode) {
+		return analyst.Permanent(err)
+	}
+	return err
+}
+
+// Errors provides access to errors that are produced after Run called.
+func (a *Adapter) Errors() <-chan (error) {
+	return a.errorSource
+}
+
+// CompletedWorkItems provides access to a stream of completed work items.
+func (a *Adapter) CompletedWorkItems() <-chan *contracts.CompletedResearchItem {
+	return a.completedItemSource
+}
+
+// Done returns a channel that blocks until the adapter is done running.
+func (a *Adapter) Done() <-chan (struct{}) {
+	return a.done
+}
+
+var _ analyst.Analyzer = (*Adapter)(nil)
@@ -0,0 +1,113 @@
+package cachedanalyst_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst/adapters/cachedanalyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst/adapters/goanalyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/mediacache"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+func Test_AdapterRun(t *testing.T) {
+	mp3Bytes, err := ioutil.ReadFile("../goanalyst/testdata/125ms_constant_192kbps_joint_stereo.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu := sync.Mutex{}
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == "/missing.mp3" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(mp3Bytes)
+	}))
+	defer server.Close()
+
+	cache, err := mediacache.Open(&mediacache.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pendingResearchItem := &contracts.PendingResearchItem{
+		LeaseId: "lease",
+		Episode: &contracts.EpisodeInfo{MediaUri: server.URL + "/episode.mp3"},
+		Clips: []*contracts.ClipInfo{
+			{Title: "a", MediaUri: server.URL + "/a.mp3"},
+			{Title: "missing", MediaUri: server.URL + "/missing.mp3"},
+			{Title: "b", MediaUri: server.URL + "/b.mp3"},
+		},
+	}
+
+	for run := 0; run < 2; run++ {
+		adapter := &cachedanalyst.Adapter{
+			Analyzer: &goanalyst.Adapter{Fetcher: new(goanalyst.FileFetcher)},
+			Cache:    cache,
+		}
+		adapter.Run(context.Background(), pendingResearchItem)
+
+		var errs []error
+		var titles []string
+		errorSource, itemSource := adapter.Errors(), adapter.CompletedWorkItems()
+		for errorSource != nil || itemSource != nil {
+			select {
+			case err, ok := <-errorSource:
+				if !ok {
+					errorSource = nil
+					continue
+				}
+				errs = append(errs, err)
+			case item, ok := <-itemSource:
+				if !ok {
+					itemSource = nil
+					continue
+				}
+				if item.GetEpisodeInfo().GetMediaUri() != server.URL+"/episode.mp3" {
+					t.Errorf("expected the original episode uri, got %v", item.GetEpisodeInfo().GetMediaUri())
+				}
+				if item.GetClipInfo().GetMediaUri() != server.URL+"/"+item.GetClipInfo().GetTitle()+".mp3" {
+					t.Errorf("expected the original clip uri, got %v", item.GetClipInfo().GetMediaUri())
+				}
+				if len(item.GetClipOffsets()) != 1 {
+					t.Errorf("expected one offset, got %v", item.GetClipOffsets())
+				}
+				titles = append(titles, item.GetClipInfo().GetTitle())
+			}
+		}
+		<-adapter.Done()
+
+		if len(errs) != 1 {
+			t.Fatalf("expected one error for the missing clip, got %v", errs)
+		}
+		var researchErr *analyst.ResearchError
+		if !errors.As(errs[0], &researchErr) || !researchErr.Permanent() {
+			t.Fatalf("expected a permanent ResearchError, got %v", errs[0])
+		}
+		if len(researchErr.Clips) != 1 || researchErr.Clips[0].GetTitle() != "missing" {
+			t.Fatalf("expected a failure of the missing clip, got %v", researchErr.Clips)
+		}
+		sort.Strings(titles)
+		if len(titles) != 2 || titles[0] != "a" || titles[1] != "b" {
+			t.Fatalf("expected items for clips a and b, got %v", titles)
+		}
+	}
+
+	for _, path := range []string{"/episode.mp3", "/a.mp3", "/b.mp3"} {
+		if requests[path] != 1 {
+			t.Errorf("expected %v to be downloaded once, got %v", path, requests[path])
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package goanalyst
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// resampleHalfWidth is the number of zero crossings on either side of the
+// interpolation filter used when resampling.
+const resampleHalfWidth = 8
+
+// MP3ToRaw decodes an mp3 file to 16 bit monaural audio at the
+// TargetSampleRate. Since podcasts are generally monaural, only the left
+// channel of stereo audio is retained. Leading silence is removed.
+func (e *Engine) MP3ToRaw(mp3Bytes []byte) (*Raw, error) {
+	decoder, err := mp3.NewDecoder(bytes.NewReader(mp3Bytes))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode mp3: %w", err)
+	}
+
+	// The decoder always produces interleaved 16 bit little endian stereo.
+	pcm, err := ioutil.ReadAll(decoder)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("unable to decode mp3: %w", err)
+	}
+
+	const frameSize = 4
+	mono := make([]float64, len(pcm)/frameSize)
+	for i := range mono {
+		mono[i] = scaleFromInt16(int16(binary.LittleEndian.Uint16(pcm[i*frameSize:])))
+	}
+
+	resampled := resample(mono, decoder.SampleRate(), e.settings.TargetSampleRate)
+
+	start := 0
+	data := make([]int16, len(resampled))
+	for i, v := range resampled {
+		data[i] = scaleToInt16(v)
+		if data[i] == 0 && start == i {
+			start++
+		}
+	}
+	data = data[start:]
+
+	return &Raw{
+		Data:     data,
+		Duration: indexToNanoseconds(len(data), e.settings.TargetSampleRate),
+	}, nil
+}
+
+// resample converts audio between sample rates using windowed sinc
+// interpolation. When downsampling, the filter's cutoff is lowered to the
+// target's Nyquist frequency to avoid aliasing. Since mp3 sample rates are
+// related to common targets by small ratios, the filter is precomputed for
+// each of the phases at which output samples fall between input samples.
+func resample(in []float64, fromRate, toRate int) []float64 {
+	if fromRate == toRate || len(in) == 0 {
+		return in
+	}
+
+	divisor := gcd(fromRate, toRate)
+	up, down := toRate/divisor, fromRate/divisor
+	cutoff := math.Min(1, float64(up)/float64(down))
+	halfWidth := float64(resampleHalfWidth) / cutoff
+
+	// Output sample j falls at input position (j*down)/up, which is phase
+	// (j*down)%up of up phases beyond input sample (j*down)/up.
+	type filter struct {
+		first   int
+		weights []float64
+	}
+	filters := make([]filter, up)
+	for phase := range filters {
+		position := float64(phase) / float64(up)
+		first := int(math.Ceil(position - halfWidth))
+		last := int(math.Floor(position + halfWidth))
+		weights := make([]float64, last-first+1)
+		for k := range weights {
+			x := float64(first+k) - position
+			weights[k] = cutoff * sinc(cutoff*x) * blackman(x/halfWidth)
+		}
+		filters[phase] = filter{first: first, weights: weights}
+	}
+
+	out := make([]float64, len(in)*up/down)
+	for j := range out {
+		base := j * down / up
+		f := filters[j*down%up]
+		sum := 0.0
+		for k, weight := range f.weights {
+			i := base + f.first + k
+			if i >= 0 && i < len(in) {
+				sum += in[i] * weight
+			}
+		}
+		out[j] = sum
+	}
+	return out
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman returns the value of a Blackman window spanning [-1, 1].
+func blackman(x float64) float64 {
+	if x < -1 || x > 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
+
+func scaleFromInt16(v int16) float64 {
+	return float64(v) / math.MaxInt16
+}
+
+func scaleToInt16(v float64) int16 {
+	scaled := math.Round(v * math.MaxInt16)
+	if scaled > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if scaled < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(scaled)
+}
@@ -0,0 +1,230 @@
+package goanalyst
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Settings are parameters that tune the behavior of an analysis. They mirror
+// the settings of the rust cosim_two_pass engine.
+//
+// The largest of RMSWindowSize, PassOneSampleSize, and PassTwoSampleSize
+// effectively sets the minimum length of a candidate (after it's resampled to
+// TargetSampleRate). FindOffsets returns an error for shorter candidates.
+type Settings struct {
+	// TargetSampleRate is the sample rate to which candidate and target audio
+	// is resampled. 22,050hz retains audio up to 11khz.
+	TargetSampleRate int
+
+	// RMSWindowSize is the size of the window used to find the peak RMS value
+	// of the candidate audio. 2756 samples is approximately 125ms at 22khz.
+	RMSWindowSize int
+
+	// PassOneSampleSize is the number of contiguous samples compared between
+	// the candidate and target for each window in the initial "rough" pass.
+	PassOneSampleSize int
+
+	// PassOneThreshold is the minimum cosine similarity that must be met or
+	// exceeded to be considered a potential match.
+	PassOneThreshold float64
+
+	// PassTwoSampleSize is the number of contiguous samples compared between
+	// the candidate and target for each window in the second pass.
+	PassTwoSampleSize int
+
+	// PassTwoThreshold is the minimum cosine similarity that must be met or
+	// exceeded to be considered a match.
+	PassTwoThreshold float64
+}
+
+// DefaultSettings returns the settings used by the analyzerd binary.
+func DefaultSettings() *Settings {
+	return &Settings{
+		TargetSampleRate:  22050,
+		RMSWindowSize:     2756,
+		PassOneSampleSize: 50,
+		PassOneThreshold:  0.60,
+		PassTwoSampleSize: 500,
+		PassTwoThreshold:  0.9,
+	}
+}
+
+// Validate reports whether the settings are usable.
+func (s *Settings) Validate() error {
+	if s.TargetSampleRate <= 0 {
+		return fmt.Errorf("settings: TargetSampleRate must be positive, got %v", s.TargetSampleRate)
+	}
+	if s.RMSWindowSize < 0 {
+		return fmt.Errorf("settings: RMSWindowSize must not be negative, got %v", s.RMSWindowSize)
+	}
+	if s.PassOneSampleSize <= 0 || s.PassTwoSampleSize <= 0 {
+		return errors.New("settings: PassOneSampleSize and PassTwoSampleSize must be positive")
+	}
+	return nil
+}
+
+// Raw is decoded monaural audio.
+type Raw struct {
+	Data []int16
+
+	// Duration is the duration of Data in nanoseconds.
+	Duration int64
+}
+
+// An Engine performs cosine similarity analysis of audio samples. It's a
+// port of the rust cosim_two_pass engine.
+type Engine struct {
+	settings *Settings
+}
+
+// NewEngine returns an Engine that uses the supplied settings.
+func NewEngine(settings *Settings) (*Engine, error) {
+	err := settings.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{settings: settings}, nil
+}
+
+// FindOffsets identifies positions within target where candidate is likely
+// present. The positions are expressed as nanoseconds, and both candidate and
+// target are assumed to be sampled at the TargetSampleRate.
+//
+// The peak RMS window of the candidate is used as an anchor, since active
+// portions of a candidate tend to be more distinctive than quiet portions.
+// In the first pass, PassOneSampleSize samples from the anchor are compared
+// with every window of the target, and windows that meet PassOneThreshold are
+// compared again in the second pass using PassTwoSampleSize samples. Windows
+// that meet PassTwoThreshold are matches. Matches that are within a
+// candidate's length of each other are reduced to the best of them.
+//
+// Unlike the rust engine, which compares the anchor with the head of each
+// window, the anchor is compared with the same position within each window,
+// so candidates are found even when they are at the head of the target.
+func (e *Engine) FindOffsets(candidate, target []int16) ([]int64, error) {
+	s := e.settings
+	anchor, err := findAnchor(candidate, s.RMSWindowSize)
+	if err != nil {
+		return nil, err
+	}
+	if anchor+s.PassOneSampleSize > len(candidate) || anchor+s.PassTwoSampleSize > len(candidate) {
+		return nil, fmt.Errorf("candidate of %v samples is too short to compare %v samples from its anchor at %v", len(candidate), max(s.PassOneSampleSize, s.PassTwoSampleSize), anchor)
+	}
+
+	passOne := newComparison(candidate[anchor : anchor+s.PassOneSampleSize])
+	passTwo := newComparison(candidate[anchor : anchor+s.PassTwoSampleSize])
+
+	windows := len(target) - len(candidate) + 1
+	possibilities := []int{}
+	for i := 0; i < windows; i++ {
+		if passOne.similarity(target[i+anchor:]) >= s.PassOneThreshold {
+			possibilities = append(possibilities, i)
+		}
+	}
+
+	offsets := []int64{}
+	peakScore := -math.MaxFloat64
+	peakIndex := -len(candidate)
+	for _, i := range possibilities {
+		score := passTwo.similarity(target[i+anchor:])
+		if score < s.PassTwoThreshold {
+			continue
+		}
+
+		if i > peakIndex+len(candidate) {
+			// The window is beyond the bounds of the current peak, so it
+			// starts a new peak.
+			if peakScore > -math.MaxFloat64 {
+				offsets = append(offsets, indexToNanoseconds(peakIndex, s.TargetSampleRate))
+			}
+			peakScore = score
+			peakIndex = i
+			continue
+		}
+
+		if score > peakScore {
+			peakScore = score
+			peakIndex = i
+		}
+	}
+	if peakScore > -math.MaxFloat64 {
+		offsets = append(offsets, indexToNanoseconds(peakIndex, s.TargetSampleRate))
+	}
+
+	return offsets, nil
+}
+
+// findAnchor returns the index of the window of raw with the highest RMS
+// value. As in the rust engine, the final window isn't considered.
+func findAnchor(raw []int16, windowSize int) (int, error) {
+	if len(raw) < windowSize {
+		return 0, fmt.Errorf("candidate of %v samples is shorter than the RMS window of %v samples", len(raw), windowSize)
+	}
+	if windowSize == 0 {
+		return 0, nil
+	}
+
+	// Sums of squares of int16 values are exact in an int64, so a running
+	// sum yields the same result as summing each window.
+	sum := int64(0)
+	for _, v := range raw[:windowSize] {
+		sum += int64(v) * int64(v)
+	}
+
+	anchor, peak := 0, int64(0)
+	for i := 0; i < len(raw)-windowSize; i++ {
+		if i > 0 {
+			leaving, entering := int64(raw[i-1]), int64(raw[i+windowSize-1])
+			sum += entering*entering - leaving*leaving
+		}
+		if sum > peak {
+			anchor, peak = i, sum
+		}
+	}
+	return anchor, nil
+}
+
+// A comparison computes the cosine similarity between a fixed sample and
+// other samples of the same length.
+type comparison struct {
+	sample []int16
+	norm   float64
+}
+
+func newComparison(sample []int16) *comparison {
+	return &comparison{
+		sample: sample,
+		norm:   math.Sqrt(sumOfSquares(sample)),
+	}
+}
+
+// similarity returns the cosine similarity between the comparison's sample
+// and the leading samples of other. The result is NaN if either is silent.
+func (c *comparison) similarity(other []int16) float64 {
+	other = other[:len(c.sample)]
+	dot := 0.0
+	for i, v := range c.sample {
+		dot += float64(v) * float64(other[i])
+	}
+	return dot / (c.norm * math.Sqrt(sumOfSquares(other)))
+}
+
+func sumOfSquares(raw []int16) float64 {
+	sum := 0.0
+	for _, v := range raw {
+		sum += float64(v) * float64(v)
+	}
+	return sum
+}
+
+func indexToNanoseconds(i, sampleRate int) int64 {
+	return int64(i) * int64(1e9) / int64(sampleRate)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,149 @@
+package goanalyst_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst/adapters/goanalyst"
+)
+
+func Test_FindOffsets(t *testing.T) {
+	ones := func(t []int16, from, to int) []int16 {
+		for i := from; i < to; i++ {
+			t[i] = 1
+		}
+		return t
+	}
+
+	testCases := []struct {
+		name       string
+		target     []int16
+		candidate  []int16
+		expOffsets []int64
+		expErr     bool
+	}{
+		{
+			name:       "single candidate",
+			target:     ones(make([]int16, 1024*10), 0, 10),
+			candidate:  ones(make([]int16, 10), 0, 10),
+			expOffsets: []int64{0},
+		},
+		{
+			name:       "overlapping candidates",
+			target:     ones(ones(make([]int16, 1024*10), 20, 30), 25, 35),
+			candidate:  ones(make([]int16, 10), 0, 10),
+			expOffsets: []int64{907029, 1405895},
+		},
+		{
+			name:       "adjacent candidates",
+			target:     ones(ones(ones(make([]int16, 1024*10), 20, 30), 31, 41), 42, 52),
+			candidate:  ones(make([]int16, 10), 0, 10),
+			expOffsets: []int64{907029, 1405895, 1904761},
+		},
+		{
+			name:       "multiple candidates",
+			target:     ones(ones(ones(make([]int16, 1024*10), 20, 30), 40, 50), 60, 70),
+			candidate:  ones(make([]int16, 10), 0, 10),
+			expOffsets: []int64{907029, 1814058, 2721088},
+		},
+		{
+			name:       "tail candidate",
+			target:     ones(make([]int16, 100), 89, 99),
+			candidate:  ones(make([]int16, 10), 0, 10),
+			expOffsets: []int64{4036281},
+		},
+		{
+			name:       "candidate not present",
+			target:     make([]int16, 1024*10),
+			candidate:  ones(make([]int16, 10), 0, 10),
+			expOffsets: []int64{},
+		},
+		{
+			name:      "candidate shorter than sample size",
+			target:    make([]int16, 1024*10),
+			candidate: ones(make([]int16, 4), 0, 4),
+			expErr:    true,
+		},
+	}
+
+	engine, err := goanalyst.NewEngine(&goanalyst.Settings{
+		TargetSampleRate:  22050,
+		RMSWindowSize:     0,
+		PassOneSampleSize: 5,
+		PassOneThreshold:  0.5,
+		PassTwoSampleSize: 5,
+		PassTwoThreshold:  0.7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			offsets, err := engine.FindOffsets(testCase.candidate, testCase.target)
+			if testCase.expErr {
+				if err == nil {
+					t.Fatal("expected an error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(offsets) != len(testCase.expOffsets) {
+				t.Fatalf("expected offsets %v, got %v", testCase.expOffsets, offsets)
+			}
+			for i := range offsets {
+				if offsets[i] != testCase.expOffsets[i] {
+					t.Fatalf("expected offsets %v, got %v", testCase.expOffsets, offsets)
+				}
+			}
+		})
+	}
+}
+
+func Test_NewEngineInvalidSettings(t *testing.T) {
+	settings := goanalyst.DefaultSettings()
+	settings.TargetSampleRate = 0
+	if _, err := goanalyst.NewEngine(settings); err == nil {
+		t.Fatal("expected an error but got nil")
+	}
+}
+
+func Test_MP3ToRaw(t *testing.T) {
+	mp3Bytes, err := ioutil.ReadFile("testdata/125ms_constant_192kbps_joint_stereo.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := goanalyst.NewEngine(goanalyst.DefaultSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := engine.MP3ToRaw(mp3Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(raw.Data) == 0 {
+		t.Fatal("expected decoded samples")
+	}
+	if raw.Data[0] == 0 {
+		t.Error("expected leading silence to be trimmed")
+	}
+	expDuration := int64(len(raw.Data)) * 1e9 / 22050
+	if raw.Duration != expDuration {
+		t.Errorf("expected duration %v, got %v", expDuration, raw.Duration)
+	}
+}
+
+func Test_MP3ToRawInvalid(t *testing.T) {
+	engine, err := goanalyst.NewEngine(goanalyst.DefaultSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.MP3ToRaw([]byte("not an mp3")); err == nil {
+		t.Fatal("expected an error but got nil")
+	}
+}
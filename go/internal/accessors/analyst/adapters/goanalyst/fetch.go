@@ -0,0 +1,66 @@
+package goanalyst
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
+)
+
+// A Fetcher retrieves the media identified by a URI.
+type Fetcher interface {
+	Fetch(ctx context.Context, uri string) ([]byte, error)
+}
+
+// HTTPFetcher fetches media via HTTP GET.
+type HTTPFetcher struct {
+	// Client is used to make requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Fetch returns the body of the response to a GET request for uri. An error
+// is returned if the response status isn't 200, or if the body is truncated.
+// Client error responses (other than timeouts and rate limiting) are marked
+// with analyst.Permanent, since the request would fail again.
+func (f *HTTPFetcher) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("received a non-200 http response %v for %v", response.StatusCode, uri)
+		if analyst.IsPermanentStatus(response.StatusCode) {
+			err = analyst.Permanent(err)
+		}
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %v: %w", uri, err)
+	}
+	return body, nil
+}
+
+// FileFetcher reads media from the local filesystem, treating each URI as a
+// path.
+type FileFetcher struct{}
+
+// Fetch returns the content of the file at uri.
+func (*FileFetcher) Fetch(_ context.Context, uri string) ([]byte, error) {
+	return ioutil.ReadFile(uri)
+}
@@ -0,0 +1,158 @@
+// Package goanalyst provides an analyst.Analyzer that conducts research
+// in-process, rather than in an analyzerd child process. Its analysis is a
+// port of the rust cosim_two_pass engine.
+package goanalyst
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The Adapter fetches the episode and clips described by a
+// PendingResearchItem, and searches the episode for each clip.
+type Adapter struct {
+	errorSource         chan (error)
+	completedItemSource chan (*contracts.CompletedResearchItem)
+	done                chan (struct{})
+
+	// Settings tune the analysis. If nil, DefaultSettings is used.
+	Settings *Settings
+
+	// Fetcher retrieves episodes and clips. If nil, an HTTPFetcher is used.
+	Fetcher Fetcher
+}
+
+// media is a decoded episode or clip.
+type media struct {
+	raw  *Raw
+	hash string
+}
+
+// Run fetches and decodes the episode, then fetches, decodes, and searches
+// for each clip in turn, emitting a CompletedResearchItem for each clip. The
+// CompletedResearchItem and error channels remain open until all work is
+// completed, at which time they are both closed. If the episode can't be
+// analyzed, a single error is emitted. Errors analyzing a clip are emitted,
+// and the remaining clips are still analyzed. If ctx is done, no further
+// clips are analyzed.
+//
+// Failures to fetch or analyze media are emitted as analyst.ResearchErrors.
+// Media that can't be decoded, clips that are too short to analyze, and
+// client error responses are permanent failures.
+//
+// Each CompletedResearchItem's hashes are the hex encoded MD5 digests of the
+// episode's and clip's media.
+func (a *Adapter) Run(ctx context.Context, pendingResearch *contracts.PendingResearchItem) {
+	if a.Settings == nil {
+		a.Settings = DefaultSettings()
+	}
+
+	if a.Fetcher == nil {
+		a.Fetcher = new(HTTPFetcher)
+	}
+
+	a.completedItemSource = make(chan *contracts.CompletedResearchItem)
+	a.errorSource = make(chan error)
+	a.done = make(chan struct{})
+	go func() {
+		defer close(a.completedItemSource)
+		defer close(a.errorSource)
+		defer close(a.done)
+
+		engine, err := NewEngine(a.Settings)
+		if err != nil {
+			a.errorSource <- err
+			return
+		}
+
+		episode, err := a.load(ctx, engine, pendingResearch.GetEpisode().GetMediaUri())
+		if err != nil {
+			a.errorSource <- &analyst.ResearchError{
+				Err: fmt.Errorf("unable to analyze episode: %w", err),
+			}
+			return
+		}
+
+		for _, clipInfo := range pendingResearch.GetClips() {
+			if ctx.Err() != nil {
+				a.errorSource <- ctx.Err()
+				return
+			}
+
+			clip, err := a.load(ctx, engine, clipInfo.GetMediaUri())
+			if err != nil {
+				a.errorSource <- &analyst.ResearchError{
+					Clips:           []*contracts.ClipInfo{clipInfo},
+					ClipMediaFailed: true,
+					Err:             fmt.Errorf("unable to analyze clip %v: %w", clipInfo.GetTitle(), err),
+				}
+				continue
+			}
+
+			offsets, err := engine.FindOffsets(clip.raw.Data, episode.raw.Data)
+			if err != nil {
+				a.errorSource <- &analyst.ResearchError{
+					Clips: []*contracts.ClipInfo{clipInfo},
+					Err:   analyst.Permanent(fmt.Errorf("unable to analyze clip %v: %w", clipInfo.GetTitle(), err)),
+				}
+				continue
+			}
+
+			a.completedItemSource <- &contracts.CompletedResearchItem{
+				ResearchDate:    timestamppb.Now(),
+				EpisodeInfo:     pendingResearch.GetEpisode(),
+				ClipInfo:        clipInfo,
+				EpisodeDuration: episode.raw.Duration,
+				EpisodeHash:     episode.hash,
+				ClipDuration:    clip.raw.Duration,
+				ClipHash:        clip.hash,
+				ClipOffsets:     offsets,
+				LeaseId:         pendingResearch.GetLeaseId(),
+				RevokeLease:     false,
+			}
+		}
+	}()
+}
+
+// load fetches and decodes the media identified by uri. Decoding errors are
+// permanent.
+func (a *Adapter) load(ctx context.Context, engine *Engine, uri string) (*media, error) {
+	mp3Bytes, err := a.Fetcher.Fetch(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := engine.MP3ToRaw(mp3Bytes)
+	if err != nil {
+		return nil, analyst.Permanent(err)
+	}
+
+	digest := md5.Sum(mp3Bytes)
+	return &media{
+		raw:  raw,
+		hash: hex.EncodeToString(digest[:]),
+	}, nil
+}
+
+// Errors provides access to errors that are produced after Run called.
+func (a *Adapter) Errors() <-chan (error) {
+	return a.errorSource
+}
+
+// CompletedWorkItems provides access to a stream of completed work items.
+func (a *Adapter) CompletedWorkItems() <-chan *contracts.CompletedResearchItem {
+	return a.completedItemSource
+}
+
+// Done returns a channel that blocks until the adapter is done running.
+func (a *Adapter) Done() <-chan (struct{}) {
+	return a.done
+}
+
+var _ analyst.Analyzer = (*Adapter)(nil)
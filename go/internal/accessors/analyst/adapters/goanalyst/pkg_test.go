@@ -0,0 +1,143 @@
+package goanalyst_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst/adapters/goanalyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+func Test_AdapterRun(t *testing.T) {
+	mp3Bytes, err := ioutil.ReadFile("testdata/125ms_constant_192kbps_joint_stereo.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := md5.Sum(mp3Bytes)
+	expHash := hex.EncodeToString(digest[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.mp3" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(mp3Bytes)
+	}))
+	defer server.Close()
+
+	pendingResearchItem := &contracts.PendingResearchItem{
+		LeaseId: "lease",
+		Episode: &contracts.EpisodeInfo{MediaUri: server.URL + "/episode.mp3"},
+		Clips: []*contracts.ClipInfo{
+			{Title: "missing", MediaUri: server.URL + "/missing.mp3"},
+			{Title: "clip", MediaUri: server.URL + "/clip.mp3"},
+		},
+	}
+
+	adapter := new(goanalyst.Adapter)
+	adapter.Run(context.Background(), pendingResearchItem)
+
+	items, errs := drain(adapter)
+
+	if len(errs) != 1 {
+		t.Fatalf("expected one error for the missing clip, got %v", errs)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected one completed item, got %v", len(items))
+	}
+
+	item := items[0]
+	if item.GetLeaseId() != "lease" {
+		t.Errorf("expected lease id %q, got %q", "lease", item.GetLeaseId())
+	}
+	if item.GetClipInfo().GetTitle() != "clip" {
+		t.Errorf("expected clip %q, got %q", "clip", item.GetClipInfo().GetTitle())
+	}
+	if item.GetEpisodeHash() != expHash || item.GetClipHash() != expHash {
+		t.Errorf("expected hashes %v, got %v and %v", expHash, item.GetEpisodeHash(), item.GetClipHash())
+	}
+	if item.GetEpisodeDuration() == 0 || item.GetEpisodeDuration() != item.GetClipDuration() {
+		t.Errorf("expected equal non-zero durations, got %v and %v", item.GetEpisodeDuration(), item.GetClipDuration())
+	}
+	if len(item.GetClipOffsets()) != 1 || item.GetClipOffsets()[0] != 0 {
+		t.Errorf("expected offsets [0], got %v", item.GetClipOffsets())
+	}
+	if item.GetResearchDate() == nil {
+		t.Error("expected a research date")
+	}
+}
+
+func Test_AdapterRunEpisodeError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	adapter := new(goanalyst.Adapter)
+	adapter.Run(context.Background(), &contracts.PendingResearchItem{
+		Episode: &contracts.EpisodeInfo{MediaUri: server.URL + "/episode.mp3"},
+		Clips:   []*contracts.ClipInfo{{MediaUri: server.URL + "/clip.mp3"}},
+	})
+
+	err, ok := <-adapter.Errors()
+	if !ok || err == nil {
+		t.Fatal("expected an error")
+	}
+	var researchErr *analyst.ResearchError
+	if !errors.As(err, &researchErr) {
+		t.Fatalf("expected a ResearchError, got %v", err)
+	}
+	if researchErr.Clips != nil || !researchErr.Permanent() {
+		t.Errorf("expected a permanent failure of every clip, got %v permanent for %v", researchErr.Permanent(), researchErr.Clips)
+	}
+	if _, ok := <-adapter.CompletedWorkItems(); ok {
+		t.Fatal("expected no completed items")
+	}
+	<-adapter.Done()
+}
+
+func Test_AdapterRunFileFetcher(t *testing.T) {
+	path := "testdata/125ms_constant_192kbps_joint_stereo.mp3"
+	adapter := &goanalyst.Adapter{Fetcher: new(goanalyst.FileFetcher)}
+	adapter.Run(context.Background(), &contracts.PendingResearchItem{
+		Episode: &contracts.EpisodeInfo{MediaUri: path},
+		Clips:   []*contracts.ClipInfo{{MediaUri: path}},
+	})
+
+	items, errs := drain(adapter)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if len(items) != 1 || len(items[0].GetClipOffsets()) != 1 {
+		t.Fatalf("expected one item with one offset, got %v", items)
+	}
+}
+
+func drain(adapter *goanalyst.Adapter) ([]*contracts.CompletedResearchItem, []error) {
+	var errs []error
+	var items []*contracts.CompletedResearchItem
+	errorSource, itemSource := adapter.Errors(), adapter.CompletedWorkItems()
+	for errorSource != nil || itemSource != nil {
+		select {
+		case err, ok := <-errorSource:
+			if !ok {
+				errorSource = nil
+				continue
+			}
+			errs = append(errs, err)
+		case item, ok := <-itemSource:
+			if !ok {
+				itemSource = nil
+				continue
+			}
+			items = append(items, item)
+		}
+	}
+	<-adapter.Done()
+	return items, errs
+}
@@ -35,6 +35,7 @@ func Test_AdapterRun(t *testing.T) {
 	cmd.EXPECT().Wait().Return(nil).Times(1)
 	cmd.EXPECT().StdinPipe().Return(writeCloser, nil).Times(1)
 	cmd.EXPECT().StdoutPipe().Return(readCloser, nil).Times(1)
+	cmd.EXPECT().StderrPipe().Return(readCloser, nil).Times(1)
 	cmdBuilder := mock_analyst.NewMockCommandBuilder(ctrl)
 	cmdBuilder.EXPECT().CommandContext(gomock.Any(), gomock.Any()).
 		Return(cmd).
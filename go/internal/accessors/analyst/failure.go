@@ -0,0 +1,90 @@
+package analyst
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// A ResearchError is emitted by an Analyzer when it's unable to research some
+// or all of the clips of a PendingResearchItem, so that the failure can be
+// reported against those clips. Errors that aren't ResearchErrors are assumed
+// to be transient, and aren't attributed to any particular clip.
+type ResearchError struct {
+	// Clips are the clips whose research failed. If nil, research failed for
+	// every clip that hadn't already been completed or reported as failed
+	// (for instance, because the episode couldn't be fetched).
+	Clips []*contracts.ClipInfo
+
+	// ClipMediaFailed is true if the clips' own media is at fault (for
+	// instance, because it couldn't be fetched or decoded), rather than the
+	// research of the clips within the episode. A failure of the episode is
+	// always attributed to the episode's media.
+	ClipMediaFailed bool
+
+	Err error
+}
+
+func (e *ResearchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResearchError) Unwrap() error {
+	return e.Err
+}
+
+// FailedMedia returns the media that the failure is attributed to.
+func (e *ResearchError) FailedMedia() contracts.FailedMedia {
+	switch {
+	case e.Clips == nil:
+		return contracts.FailedMedia_EPISODE
+	case e.ClipMediaFailed:
+		return contracts.FailedMedia_CLIPS
+	}
+	return contracts.FailedMedia_UNATTRIBUTED
+}
+
+// Permanent returns true if the research is expected to fail in the same way
+// if it's retried.
+func (e *ResearchError) Permanent() bool {
+	return IsPermanent(e.Err)
+}
+
+// permanentError marks an error as permanent.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err to indicate that research that failed with err is
+// expected to fail in the same way if it's retried (for instance, because the
+// media doesn't exist, or can't be decoded).
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent returns true if err, or any error that it wraps, was wrapped
+// with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// IsPermanentStatus returns true if a request for media that received an http
+// response with statusCode would receive the same response if it was retried.
+// Client errors are permanent, other than timeouts and rate limiting.
+func IsPermanentStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return statusCode >= 400 && statusCode < 500
+}
@@ -0,0 +1,170 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const selectEpisodeColumns = `
+	ce.initial_date_curated,
+	ce.last_date_curated,
+	ce.curator_info,
+	ce.date_aired,
+	ce.title,
+	ce.description,
+	ce.media_uri,
+	ce.media_type,
+	ce.priority
+`
+
+const selectClipColumns = `
+	cc.initial_date_curated,
+	cc.last_date_curated,
+	cc.curator_info,
+	cc.title,
+	cc.description,
+	cc.media_uri,
+	cc.media_type,
+	cc.priority
+`
+
+// ListEpisodes returns a page of curated episodes, ordered by the date each
+// episode aired (newest first).
+func (s *Store) ListEpisodes(page datastore.Page) ([]*contracts.EpisodeInfo, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := newArgs(s.dialect)
+	selectStmt := `
+		SELECT ` + selectEpisodeColumns + `
+		FROM curated_episodes ce
+		ORDER BY ce.date_aired DESC, ce.episode_id
+		LIMIT ` + args.add(page.Limit) + ` OFFSET ` + args.add(page.Offset) + `;
+	`
+	rows, err := s.db.Query(selectStmt, args.values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	episodes := []*contracts.EpisodeInfo{}
+	for rows.Next() {
+		episode, err := scanEpisodeInfo(rows)
+		if err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, episode)
+	}
+	return episodes, rows.Err()
+}
+
+// ListClips returns a page of curated clips, ordered by title.
+func (s *Store) ListClips(page datastore.Page) ([]*contracts.ClipInfo, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := newArgs(s.dialect)
+	selectStmt := `
+		SELECT ` + selectClipColumns + `
+		FROM curated_clips cc
+		ORDER BY cc.title, cc.clip_id
+		LIMIT ` + args.add(page.Limit) + ` OFFSET ` + args.add(page.Offset) + `;
+	`
+	rows, err := s.db.Query(selectStmt, args.values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clips := []*contracts.ClipInfo{}
+	for rows.Next() {
+		clip, err := scanClipInfo(rows)
+		if err != nil {
+			return nil, err
+		}
+		clips = append(clips, clip)
+	}
+	return clips, rows.Err()
+}
+
+// GetResearchStatistics summarizes the state of the archive.
+func (s *Store) GetResearchStatistics() (*datastore.ResearchStatistics, error) {
+	const selectStmt = `
+		SELECT
+			(SELECT COUNT(*) FROM curated_episodes),
+			(SELECT COUNT(*) FROM curated_clips),
+			(SELECT COUNT(*) FROM research_backlog),
+			(
+				SELECT COUNT(*)
+				FROM research_backlog rb
+					JOIN research_leases rl ON rb.research_id = rl.research_id
+			),
+			(SELECT COUNT(*) FROM research_complete);
+	`
+	statistics := new(datastore.ResearchStatistics)
+	err := s.db.QueryRow(selectStmt).Scan(
+		&statistics.Episodes,
+		&statistics.Clips,
+		&statistics.BacklogItems,
+		&statistics.LeasedBacklogItems,
+		&statistics.CompletedItems,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return statistics, nil
+}
+
+// scanEpisodeInfo scans a row containing the columns in selectEpisodeColumns.
+func scanEpisodeInfo(rows *sql.Rows) (*contracts.EpisodeInfo, error) {
+	episode := new(contracts.EpisodeInfo)
+	var initialDateCurated, lastDateCurated, dateAired time.Time
+	err := rows.Scan(
+		&initialDateCurated,
+		&lastDateCurated,
+		&episode.CuratorInformation,
+		&dateAired,
+		&episode.Title,
+		&episode.Description,
+		&episode.MediaUri,
+		&episode.MediaType,
+		&episode.Priority,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	episode.InitialDateCurated = timestamppb.New(initialDateCurated)
+	episode.LastDateCurated = timestamppb.New(lastDateCurated)
+	episode.DateAired = timestamppb.New(dateAired)
+	return episode, nil
+}
+
+// scanClipInfo scans a row containing the columns in selectClipColumns.
+func scanClipInfo(rows *sql.Rows) (*contracts.ClipInfo, error) {
+	clip := new(contracts.ClipInfo)
+	var initialDateCurated, lastDateCurated time.Time
+	err := rows.Scan(
+		&initialDateCurated,
+		&lastDateCurated,
+		&clip.CuratorInformation,
+		&clip.Title,
+		&clip.Description,
+		&clip.MediaUri,
+		&clip.MediaType,
+		&clip.Priority,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	clip.InitialDateCurated = timestamppb.New(initialDateCurated)
+	clip.LastDateCurated = timestamppb.New(lastDateCurated)
+	return clip, nil
+}
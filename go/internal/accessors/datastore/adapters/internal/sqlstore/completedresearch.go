@@ -0,0 +1,198 @@
+package sqlstore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FindClipAppearances returns the completed research for every episode in
+// which the clip was found, ordered by the date the episode aired (newest
+// first). Each item includes every offset at which the clip occurs within the
+// episode. If the clip does not exist, an error wrapping datastore.ErrNotFound
+// is returned.
+func (s *Store) FindClipAppearances(clip *contracts.ClipInfo, page datastore.Page) ([]*contracts.CompletedResearchItem, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	found, clipID, err := s.getClipInfoID(clip)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("clip %v: %w", clip.Title, datastore.ErrNotFound)
+	}
+
+	args := newArgs(s.dialect)
+	selectStmt := selectCompletedResearchStmt + `
+		WHERE rc.clip_id = ` + args.add(clipID) + `
+		ORDER BY ce.date_aired DESC, ce.episode_id
+		LIMIT ` + args.add(page.Limit) + ` OFFSET ` + args.add(page.Offset) + `;
+	`
+	return s.queryCompletedResearch(selectStmt, args.values...)
+}
+
+// FindEpisodeClips returns the completed research for every clip that was
+// found within the episode, ordered by clip title. Each item includes every
+// offset at which the clip occurs within the episode. If the episode does not
+// exist, an error wrapping datastore.ErrNotFound is returned.
+func (s *Store) FindEpisodeClips(episode *contracts.EpisodeInfo, page datastore.Page) ([]*contracts.CompletedResearchItem, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	found, episodeID, err := s.getEpisodeInfoID(episode)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("episode %v: %w", episode.Title, datastore.ErrNotFound)
+	}
+
+	args := newArgs(s.dialect)
+	selectStmt := selectCompletedResearchStmt + `
+		WHERE rc.episode_id = ` + args.add(episodeID) + `
+		ORDER BY cc.title, cc.clip_id
+		LIMIT ` + args.add(page.Limit) + ` OFFSET ` + args.add(page.Offset) + `;
+	`
+	return s.queryCompletedResearch(selectStmt, args.values...)
+}
+
+const selectCompletedResearchStmt = `
+	SELECT
+		rc.research_id,
+		rc.research_date,
+		rc.episode_duration_ns,
+		rc.clip_duration_ns,
+		COALESCE(eh.hash, ''),
+		COALESCE(ch.hash, ''),
+		ce.initial_date_curated,
+		ce.last_date_curated,
+		ce.curator_info,
+		ce.date_aired,
+		ce.title,
+		ce.description,
+		ce.media_uri,
+		ce.media_type,
+		ce.priority,
+		cc.initial_date_curated,
+		cc.last_date_curated,
+		cc.curator_info,
+		cc.title,
+		cc.description,
+		cc.media_uri,
+		cc.media_type,
+		cc.priority
+	FROM
+		research_complete rc
+		JOIN curated_episodes ce ON rc.episode_id = ce.episode_id
+		JOIN curated_clips cc ON rc.clip_id = cc.clip_id
+		LEFT JOIN episode_hashes eh ON rc.episode_id = eh.episode_id
+		LEFT JOIN clip_hashes ch ON rc.clip_id = ch.clip_id
+`
+
+// queryCompletedResearch runs a query that selects the columns in
+// selectCompletedResearchStmt, and then looks up the offsets of every item in
+// a single query. The rows are fully read before the offsets are queried so
+// that a connection is never held by more than one query at a time.
+func (s *Store) queryCompletedResearch(query string, args ...interface{}) ([]*contracts.CompletedResearchItem, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	itemsByResearchID := map[int]*contracts.CompletedResearchItem{}
+	researchIDs := []interface{}{}
+	items := []*contracts.CompletedResearchItem{}
+	for rows.Next() {
+		var researchID int
+		var researchDate time.Time
+		var episodeInitialDateCurated, episodeLastDateCurated, dateAired time.Time
+		var clipInitialDateCurated, clipLastDateCurated time.Time
+		item := &contracts.CompletedResearchItem{
+			EpisodeInfo: new(contracts.EpisodeInfo),
+			ClipInfo:    new(contracts.ClipInfo),
+			ClipOffsets: []int64{},
+		}
+		err = rows.Scan(
+			&researchID,
+			&researchDate,
+			&item.EpisodeDuration,
+			&item.ClipDuration,
+			&item.EpisodeHash,
+			&item.ClipHash,
+			&episodeInitialDateCurated,
+			&episodeLastDateCurated,
+			&item.EpisodeInfo.CuratorInformation,
+			&dateAired,
+			&item.EpisodeInfo.Title,
+			&item.EpisodeInfo.Description,
+			&item.EpisodeInfo.MediaUri,
+			&item.EpisodeInfo.MediaType,
+			&item.EpisodeInfo.Priority,
+			&clipInitialDateCurated,
+			&clipLastDateCurated,
+			&item.ClipInfo.CuratorInformation,
+			&item.ClipInfo.Title,
+			&item.ClipInfo.Description,
+			&item.ClipInfo.MediaUri,
+			&item.ClipInfo.MediaType,
+			&item.ClipInfo.Priority,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		item.ResearchDate = timestamppb.New(researchDate)
+		item.EpisodeInfo.InitialDateCurated = timestamppb.New(episodeInitialDateCurated)
+		item.EpisodeInfo.LastDateCurated = timestamppb.New(episodeLastDateCurated)
+		item.EpisodeInfo.DateAired = timestamppb.New(dateAired)
+		item.ClipInfo.InitialDateCurated = timestamppb.New(clipInitialDateCurated)
+		item.ClipInfo.LastDateCurated = timestamppb.New(clipLastDateCurated)
+
+		itemsByResearchID[researchID] = item
+		researchIDs = append(researchIDs, researchID)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if len(researchIDs) == 0 {
+		return items, nil
+	}
+
+	offsetArgs := newArgs(s.dialect)
+	selectOffsetsStmt := `
+		SELECT research_id, offset_ns
+		FROM episode_clip_offsets
+		WHERE research_id IN (` + offsetArgs.addAll(researchIDs...) + `)
+		ORDER BY research_id, offset_ns;
+	`
+	offsetRows, err := s.db.Query(selectOffsetsStmt, offsetArgs.values...)
+	if err != nil {
+		return nil, err
+	}
+	defer offsetRows.Close()
+
+	for offsetRows.Next() {
+		var researchID int
+		var offset int64
+		if err := offsetRows.Scan(&researchID, &offset); err != nil {
+			return nil, err
+		}
+		item := itemsByResearchID[researchID]
+		item.ClipOffsets = append(item.ClipOffsets, offset)
+	}
+	if err := offsetRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,240 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/search"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// UpsertEpisodeInfo inserts or updates episode info. If the episode already
+// exists, it will be updated, but its InitialDateCurated value is ignored. If
+// the episode does not already exist, both InitialDateCurated and
+// LastDateCurated are evaluated, but the insert will fail and an error will be
+// returned if LastDateCurated is earlier than InitialDateCurated. A new
+// episode is added to the research backlog with every curated clip.
+func (s *Store) UpsertEpisodeInfo(episodeInfo *contracts.EpisodeInfo) error {
+	episodeExists, episodeID, err := s.getEpisodeInfoID(episodeInfo)
+	if err != nil {
+		return err
+	}
+	if episodeExists {
+		return s.updateEpisodeInfo(episodeID, episodeInfo)
+	}
+	return s.insertEpisodeInfo(episodeInfo)
+}
+
+func (s *Store) getEpisodeInfoID(episodeInfo *contracts.EpisodeInfo) (bool, int, error) {
+	args := newArgs(s.dialect)
+	selectStmt := `
+		SELECT episode_id
+		FROM curated_episodes
+		WHERE title = ` + args.add(episodeInfo.Title) + ` AND date_aired = ` + args.add(asDatetime(episodeInfo.DateAired)) + `;
+	`
+	return s.queryID(selectStmt, args.values...)
+}
+
+func (s *Store) updateEpisodeInfo(episodeID int, episodeInfo *contracts.EpisodeInfo) error {
+	// Note that on updates, we update the `last_date_curated` field and ignore
+	// the  `initial_date_curated` field.
+	args := newArgs(s.dialect)
+	updateStmt := `
+		UPDATE curated_episodes
+		SET last_date_curated = ` + args.add(asDatetime(episodeInfo.LastDateCurated)) + `,
+			curator_info = ` + args.add(episodeInfo.CuratorInformation) + `,
+			date_aired = ` + args.add(asDatetime(episodeInfo.DateAired)) + `,
+			title = ` + args.add(episodeInfo.Title) + `,
+			description = ` + args.add(episodeInfo.Description) + `,
+			media_uri = ` + args.add(episodeInfo.MediaUri) + `,
+			media_type = ` + args.add(episodeInfo.MediaType) + `,
+			priority = ` + args.add(episodeInfo.Priority) + `,
+			title_tokens = ` + args.add(search.Index(episodeInfo.Title)) + `,
+			description_tokens = ` + args.add(search.Index(episodeInfo.Description)) + `
+		WHERE episode_id = ` + args.add(episodeID) + `;
+	`
+	result, err := s.db.Exec(updateStmt, args.values...)
+	return expectOneRowAffected(result, err)
+}
+
+func (s *Store) insertEpisodeInfo(episodeInfo *contracts.EpisodeInfo) error {
+	if episodeInfo.LastDateCurated.AsTime().Before(episodeInfo.InitialDateCurated.AsTime()) {
+		return fmt.Errorf("LastDateCurated must not be earlier than InitialDateCurated. %v", episodeInfo)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	args := newArgs(s.dialect)
+	insertCuratedEpisodeStmt := `
+		INSERT INTO curated_episodes (
+			initial_date_curated,
+			last_date_curated,
+			curator_info,
+			date_aired,
+			title,
+			description,
+			media_uri,
+			media_type,
+			priority,
+			title_tokens,
+			description_tokens
+		)
+		VALUES (` + args.addAll(
+		asDatetime(episodeInfo.InitialDateCurated),
+		asDatetime(episodeInfo.LastDateCurated),
+		episodeInfo.CuratorInformation,
+		asDatetime(episodeInfo.DateAired),
+		episodeInfo.Title,
+		episodeInfo.Description,
+		episodeInfo.MediaUri,
+		episodeInfo.MediaType,
+		episodeInfo.Priority,
+		search.Index(episodeInfo.Title),
+		search.Index(episodeInfo.Description),
+	) + `);
+	`
+	result, err := tx.Exec(insertCuratedEpisodeStmt, args.values...)
+	if err := expectOneRowAffected(result, err); err != nil {
+		return tryTxRollback(tx, err)
+	}
+
+	// The new episode is selected by its key rather than its id, since not
+	// every driver reports the ids of inserted rows.
+	args = newArgs(s.dialect)
+	insertEpisodeBacklog := `
+		INSERT INTO research_backlog (episode_id, clip_id)
+		SELECT ce.episode_id, cc.clip_id
+		FROM curated_episodes ce CROSS JOIN curated_clips cc
+		WHERE ce.title = ` + args.add(episodeInfo.Title) + ` AND ce.date_aired = ` + args.add(asDatetime(episodeInfo.DateAired)) + `;
+	`
+	_, err = tx.Exec(insertEpisodeBacklog, args.values...)
+	if err != nil {
+		return tryTxRollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+// UpsertClipInfo inserts or updates clip info. If the clip already exists, it
+// will be updated, but its InitialDateCurated value is ignored. If the clip
+// does not already exist, both InitialDateCurated and LastDateCurated are
+// evaluated, but the insert will fail and an error will be returned if
+// LastDateCurated is earlier than InitialDateCurated. A new clip is added to
+// the research backlog with every curated episode.
+func (s *Store) UpsertClipInfo(clipInfo *contracts.ClipInfo) error {
+	clipExists, clipID, err := s.getClipInfoID(clipInfo)
+	if err != nil {
+		return err
+	}
+	if clipExists {
+		return s.updateClipInfo(clipID, clipInfo)
+	}
+	return s.insertClipInfo(clipInfo)
+}
+
+func (s *Store) getClipInfoID(clipInfo *contracts.ClipInfo) (bool, int, error) {
+	args := newArgs(s.dialect)
+	selectStmt := `
+		SELECT clip_id
+		FROM curated_clips
+		WHERE title = ` + args.add(clipInfo.Title) + `;
+	`
+	return s.queryID(selectStmt, args.values...)
+}
+
+func (s *Store) updateClipInfo(clipID int, clipInfo *contracts.ClipInfo) error {
+	// Note that on updates, we update the `last_date_curated` field and ignore
+	// the  `initial_date_curated` field.
+	args := newArgs(s.dialect)
+	updateStmt := `
+		UPDATE curated_clips
+		SET last_date_curated = ` + args.add(asDatetime(clipInfo.LastDateCurated)) + `,
+			curator_info = ` + args.add(clipInfo.CuratorInformation) + `,
+			title = ` + args.add(clipInfo.Title) + `,
+			description = ` + args.add(clipInfo.Description) + `,
+			media_uri = ` + args.add(clipInfo.MediaUri) + `,
+			media_type = ` + args.add(clipInfo.MediaType) + `,
+			priority = ` + args.add(clipInfo.Priority) + `,
+			title_tokens = ` + args.add(search.Index(clipInfo.Title)) + `,
+			description_tokens = ` + args.add(search.Index(clipInfo.Description)) + `
+		WHERE clip_id = ` + args.add(clipID) + `;
+	`
+	result, err := s.db.Exec(updateStmt, args.values...)
+	return expectOneRowAffected(result, err)
+}
+
+func (s *Store) insertClipInfo(clipInfo *contracts.ClipInfo) error {
+	if clipInfo.LastDateCurated.AsTime().Before(clipInfo.InitialDateCurated.AsTime()) {
+		return fmt.Errorf("LastDateCurated must not be earlier than InitialDateCurated. %v", clipInfo)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	args := newArgs(s.dialect)
+	insertCuratedClipStmt := `
+		INSERT INTO curated_clips (
+			initial_date_curated,
+			last_date_curated,
+			curator_info,
+			title,
+			description,
+			media_uri,
+			media_type,
+			priority,
+			title_tokens,
+			description_tokens
+		)
+		VALUES (` + args.addAll(
+		asDatetime(clipInfo.InitialDateCurated),
+		asDatetime(clipInfo.LastDateCurated),
+		clipInfo.CuratorInformation,
+		clipInfo.Title,
+		clipInfo.Description,
+		clipInfo.MediaUri,
+		clipInfo.MediaType,
+		clipInfo.Priority,
+		search.Index(clipInfo.Title),
+		search.Index(clipInfo.Description),
+	) + `);
+	`
+	result, err := tx.Exec(insertCuratedClipStmt, args.values...)
+	if err := expectOneRowAffected(result, err); err != nil {
+		return tryTxRollback(tx, err)
+	}
+
+	// The new clip is selected by its key rather than its id, since not every
+	// driver reports the ids of inserted rows.
+	args = newArgs(s.dialect)
+	insertClipBacklog := `
+		INSERT INTO research_backlog (episode_id, clip_id)
+		SELECT ce.episode_id, cc.clip_id
+		FROM curated_clips cc CROSS JOIN curated_episodes ce
+		WHERE cc.title = ` + args.add(clipInfo.Title) + `;
+	`
+	_, err = tx.Exec(insertClipBacklog, args.values...)
+	if err != nil {
+		return tryTxRollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+// queryID runs a query that selects a single id. If no row is selected, this
+// returns false.
+func (s *Store) queryID(query string, args ...interface{}) (bool, int, error) {
+	var id int
+	err := s.db.QueryRow(query, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, 0, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	return true, id, nil
+}
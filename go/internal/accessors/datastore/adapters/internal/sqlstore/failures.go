@@ -0,0 +1,149 @@
+package sqlstore
+
+import (
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// RecordResearchFailure counts a failed attempt to research each of the
+// failure's episode/clip pairs, and releases the pairs from the failure's
+// lease. Pairs that are no longer in the research backlog (because they've
+// since been researched) are ignored. If the episode or any clip doesn't
+// exist, nothing is recorded and an error is returned.
+func (s *Store) RecordResearchFailure(failure *contracts.ResearchFailure) error {
+	found, episodeID, err := s.getEpisodeInfoID(failure.EpisodeInfo)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("episodeID not found for episode: %v", failure.EpisodeInfo)
+	}
+
+	clipIDs := []int{}
+	researchIDs := []int{}
+	for _, clip := range failure.ClipInfos {
+		found, clipID, err := s.getClipInfoID(clip)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("clipID not found for clip: %v", clip)
+		}
+		clipIDs = append(clipIDs, clipID)
+
+		found, researchID, err := s.getResearchIDFromBacklog(episodeID, clipID)
+		if err != nil {
+			return err
+		}
+		if found {
+			researchIDs = append(researchIDs, researchID)
+		}
+	}
+
+	permanentAttempts := 0
+	if failure.FailureClass == contracts.FailureClass_PERMANENT {
+		permanentAttempts = 1
+	}
+
+	failureDate := asDatetime(failure.FailureDate)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	upsertFailureStmt := fmt.Sprintf(`
+		INSERT INTO research_failures (
+			research_id,
+			attempts,
+			permanent_attempts,
+			failure_class,
+			message,
+			last_failure_date
+		) VALUES (%v,1,%v,%v,%v,%v)
+		%v
+			attempts = research_failures.attempts + 1,
+			permanent_attempts = research_failures.permanent_attempts + %v,
+			failure_class = %v,
+			message = %v,
+			last_failure_date = %v;
+	`,
+		s.dialect.Placeholder(1),
+		s.dialect.Placeholder(2),
+		s.dialect.Placeholder(3),
+		s.dialect.Placeholder(4),
+		s.dialect.Placeholder(5),
+		s.dialect.OnConflict("research_id"),
+		s.dialect.Excluded("permanent_attempts"),
+		s.dialect.Excluded("failure_class"),
+		s.dialect.Excluded("message"),
+		s.dialect.Excluded("last_failure_date"),
+	)
+
+	// Only the failure's own lease is released, in case the pair has since
+	// been leased to another researcher.
+	deleteLeaseStmt := fmt.Sprintf(`
+		DELETE FROM research_leases
+		WHERE research_id = %v AND lease_id = %v;
+	`, s.dialect.Placeholder(1), s.dialect.Placeholder(2))
+
+	for _, researchID := range researchIDs {
+		_, err = tx.Exec(upsertFailureStmt,
+			researchID,
+			permanentAttempts,
+			failure.FailureClass.String(),
+			failure.Message,
+			failureDate,
+		)
+		if err != nil {
+			return tryTxRollback(tx, err)
+		}
+
+		_, err = tx.Exec(deleteLeaseStmt, researchID, failure.LeaseId)
+		if err != nil {
+			return tryTxRollback(tx, err)
+		}
+	}
+
+	// Permanent failures that are attributed to the episode or clips
+	// themselves are also counted against that media, so that it's no longer
+	// offered for research with any other clip or episode.
+	mediaIDs, table, idColumn := []int{}, "", ""
+	if permanentAttempts > 0 {
+		switch failure.FailedMedia {
+		case contracts.FailedMedia_EPISODE:
+			mediaIDs, table, idColumn = []int{episodeID}, "episode_failures", "episode_id"
+		case contracts.FailedMedia_CLIPS:
+			mediaIDs, table, idColumn = clipIDs, "clip_failures", "clip_id"
+		}
+	}
+
+	if len(mediaIDs) > 0 {
+		upsertMediaFailureStmt := fmt.Sprintf(`
+			INSERT INTO %[1]v (
+				%[2]v,
+				permanent_attempts,
+				last_failure_date
+			) VALUES (%[3]v,1,%[4]v)
+			%[5]v
+				permanent_attempts = %[1]v.permanent_attempts + 1,
+				last_failure_date = %[6]v;
+		`,
+			table,
+			idColumn,
+			s.dialect.Placeholder(1),
+			s.dialect.Placeholder(2),
+			s.dialect.OnConflict(idColumn),
+			s.dialect.Excluded("last_failure_date"),
+		)
+		for _, mediaID := range mediaIDs {
+			_, err = tx.Exec(upsertMediaFailureStmt, mediaID, failureDate)
+			if err != nil {
+				return tryTxRollback(tx, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
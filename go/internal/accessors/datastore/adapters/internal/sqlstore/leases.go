@@ -0,0 +1,139 @@
+package sqlstore
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// CreateResearchLease attempts to create a lease that is shared between the
+// episode and all provided clips.  This method will panic if clips is empty or
+// nil. If there are no clips to lease for an episode, that should be handled
+// without attempting to call this method.
+//
+// The backlog items are locked while the lease is created (see
+// Dialect.LockRows). If any of the requested items is leased, locked by a
+// concurrent lease request, or not in the backlog, no lease is created and an
+// error is returned.
+func (s *Store) CreateResearchLease(newLeaseID *uuid.UUID, episode *contracts.EpisodeInfo, clips []*contracts.ClipInfo, expiration time.Time) error {
+	if len(clips) == 0 {
+		panic("a non-zero number of clips must be supplied to this method")
+	}
+
+	found, episodeID, err := s.getEpisodeInfoID(episode)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("episode not found in database %v", episode)
+	}
+
+	clipTitles := make([]interface{}, 0, len(clips))
+	for _, clip := range clips {
+		clipTitles = append(clipTitles, clip.Title)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	args := newArgs(s.dialect)
+	selectStmt := `
+		SELECT rb.research_id
+		FROM research_backlog rb
+			JOIN curated_clips cc ON rb.clip_id = cc.clip_id
+			LEFT JOIN research_leases rl ON rb.research_id = rl.research_id
+		WHERE rb.episode_id = ` + args.add(episodeID) + `
+			AND cc.title IN (` + args.addAll(clipTitles...) + `)
+			AND rl.research_id IS NULL
+		` + s.dialect.LockRows("rb") + `;
+	`
+	rows, err := tx.Query(selectStmt, args.values...)
+	if err != nil {
+		return tryTxRollback(tx, err)
+	}
+
+	researchIDs := []int{}
+	for rows.Next() {
+		var researchID int
+		if err := rows.Scan(&researchID); err != nil {
+			rows.Close()
+			return tryTxRollback(tx, err)
+		}
+		researchIDs = append(researchIDs, researchID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return tryTxRollback(tx, err)
+	}
+
+	if len(researchIDs) != len(clips) {
+		err := fmt.Errorf("expected %v backlog items to be available for lease, but %v were available", len(clips), len(researchIDs))
+		return tryTxRollback(tx, err)
+	}
+
+	args = newArgs(s.dialect)
+	values := []string{}
+	for _, researchID := range researchIDs {
+		values = append(values, "("+args.addAll(newLeaseID.String(), researchID, expiration.UTC().Truncate(time.Second))+")")
+	}
+	insertStmt := `
+		INSERT INTO research_leases (lease_id, research_id, expiration)
+		VALUES ` + strings.Join(values, ",") + `;
+	`
+	_, err = tx.Exec(insertStmt, args.values...)
+	if err != nil {
+		return tryTxRollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+// RenewResearchLease updates the deadline for an existing lease. If the lease
+// doesn't exist, no action is taken.
+func (s *Store) RenewResearchLease(leaseID uuid.UUID, expiration time.Time) error {
+	args := newArgs(s.dialect)
+	updateStmt := `
+		UPDATE research_leases
+		SET expiration = ` + args.add(expiration.UTC().Truncate(time.Second)) + `
+		WHERE lease_id = ` + args.add(leaseID.String()) + `;
+	`
+	// We ignore the returned SQLResult value since we're not concerned with
+	// how many (if any) leases were renewed.
+	_, err := s.db.Exec(updateStmt, args.values...)
+	return err
+}
+
+// RevokeResearchLease removes the leases for all items assigned to the
+// specified leaseID. If the leaseID doesn't exist, no action is taken.
+func (s *Store) RevokeResearchLease(leaseID uuid.UUID) error {
+	deleteStmt := `
+		DELETE FROM research_leases
+		WHERE lease_id = ` + s.dialect.Placeholder(1) + `;
+	`
+	// We ignore the returned SQLResult value since we're not concerned with
+	// how many (if any) leases were revoked.
+	_, err := s.db.Exec(deleteStmt, leaseID.String())
+	return err
+}
+
+// ReapExpiredLeases removes every lease that expired before now, which
+// returns the leased items to the research backlog. The number of leased
+// items that were reclaimed is returned.
+func (s *Store) ReapExpiredLeases(now time.Time) (int, error) {
+	deleteStmt := `
+		DELETE FROM research_leases
+		WHERE expiration < ` + s.dialect.Placeholder(1) + `;
+	`
+	result, err := s.db.Exec(deleteStmt, now.UTC().Truncate(time.Second))
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return int(rowsAffected), err
+}
@@ -1,10 +1,11 @@
-package mariadbadapter
+package sqlstore
 
 import (
 	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -12,9 +13,10 @@ import (
 // GetHighestPriorityEpisode identifies and returns the highest priority
 // episode to be researched. If no episodes are available, this returns nil,
 // nil.
-func (m *MariaDbConnection) GetHighestPriorityEpisode() (*contracts.EpisodeInfo, error) {
-	const selectStmt = `
-		SELECT 
+func (s *Store) GetHighestPriorityEpisode() (*contracts.EpisodeInfo, error) {
+	args := newArgs(s.dialect)
+	selectStmt := `
+		SELECT
 			ce.initial_date_curated,
 			ce.last_date_curated,
 			ce.curator_info,
@@ -23,13 +25,19 @@ func (m *MariaDbConnection) GetHighestPriorityEpisode() (*contracts.EpisodeInfo,
 			ce.description,
 			ce.media_uri,
 			ce.media_type,
-			ce.priority
-		FROM 
+			ce.priority,
+			COALESCE(eh.hash, '')
+		FROM
 			research_backlog rb
 			LEFT JOIN research_leases rl ON rb.research_id = rl.research_id
+			LEFT JOIN research_failures rf ON rb.research_id = rf.research_id
+			LEFT JOIN episode_failures ef ON rb.episode_id = ef.episode_id
+			LEFT JOIN clip_failures cf ON rb.clip_id = cf.clip_id
 			JOIN curated_episodes ce ON rb.episode_id = ce.episode_id
+			LEFT JOIN episode_hashes eh ON ce.episode_id = eh.episode_id
 		WHERE
 			rl.research_id IS NULL
+			AND ` + belowPermanentFailureLimit(args) + `
 		ORDER BY
 			ce.priority DESC,
 			ce.date_aired DESC,
@@ -37,11 +45,9 @@ func (m *MariaDbConnection) GetHighestPriorityEpisode() (*contracts.EpisodeInfo,
 		LIMIT 1;
 	`
 
-	row := m.db.QueryRow(selectStmt)
+	row := s.db.QueryRow(selectStmt, args.values...)
 	episodeInfo := contracts.EpisodeInfo{}
-	initialDateCurated := new(time.Time)
-	lastDateCurated := new(time.Time)
-	dateAired := new(time.Time)
+	var initialDateCurated, lastDateCurated, dateAired time.Time
 	err := row.Scan(
 		&initialDateCurated,
 		&lastDateCurated,
@@ -52,12 +58,9 @@ func (m *MariaDbConnection) GetHighestPriorityEpisode() (*contracts.EpisodeInfo,
 		&episodeInfo.MediaUri,
 		&episodeInfo.MediaType,
 		&episodeInfo.Priority,
+		&episodeInfo.MediaHash,
 	)
 
-	episodeInfo.InitialDateCurated = timestamppb.New(*initialDateCurated)
-	episodeInfo.LastDateCurated = timestamppb.New(*lastDateCurated)
-	episodeInfo.DateAired = timestamppb.New(*dateAired)
-
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -66,6 +69,10 @@ func (m *MariaDbConnection) GetHighestPriorityEpisode() (*contracts.EpisodeInfo,
 		return nil, err
 	}
 
+	episodeInfo.InitialDateCurated = timestamppb.New(initialDateCurated)
+	episodeInfo.LastDateCurated = timestamppb.New(lastDateCurated)
+	episodeInfo.DateAired = timestamppb.New(dateAired)
+
 	return &episodeInfo, nil
 }
 
@@ -73,8 +80,8 @@ func (m *MariaDbConnection) GetHighestPriorityEpisode() (*contracts.EpisodeInfo,
 // priority clips to be researched for given episode. The number of clips
 // returned is limited to `clipLimit`. If no clips are available for the
 // supplied episode, this returns nil, nil.
-func (m *MariaDbConnection) GetHighestPriorityClipsForEpisode(episode *contracts.EpisodeInfo, clipLimit int) ([]*contracts.ClipInfo, error) {
-	found, episodeID, err := m.getEpisodeInfoID(episode)
+func (s *Store) GetHighestPriorityClipsForEpisode(episode *contracts.EpisodeInfo, clipLimit int) ([]*contracts.ClipInfo, error) {
+	found, episodeID, err := s.getEpisodeInfoID(episode)
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +89,8 @@ func (m *MariaDbConnection) GetHighestPriorityClipsForEpisode(episode *contracts
 		return nil, fmt.Errorf("episode not found: %v", episode)
 	}
 
-	const selectStmt = `
+	args := newArgs(s.dialect)
+	selectStmt := `
 		SELECT
 			cc.initial_date_curated,
 			cc.last_date_curated,
@@ -91,57 +99,58 @@ func (m *MariaDbConnection) GetHighestPriorityClipsForEpisode(episode *contracts
 			cc.description,
 			cc.media_uri,
 			cc.media_type,
-			cc.priority
-		FROM 
+			cc.priority,
+			COALESCE(ch.hash, '')
+		FROM
 			research_backlog rb
 			LEFT JOIN research_leases rl ON rb.research_id = rl.research_id
+			LEFT JOIN research_failures rf ON rb.research_id = rf.research_id
+			LEFT JOIN episode_failures ef ON rb.episode_id = ef.episode_id
+			LEFT JOIN clip_failures cf ON rb.clip_id = cf.clip_id
 			JOIN curated_clips cc ON rb.clip_id = cc.clip_id
+			LEFT JOIN clip_hashes ch ON cc.clip_id = ch.clip_id
 		WHERE
 			rl.research_id IS NULL
-			AND rb.episode_id = ?
+			AND rb.episode_id = ` + args.add(episodeID) + `
+			AND ` + belowPermanentFailureLimit(args) + `
 		ORDER BY
 			cc.priority DESC,
 			cc.initial_date_curated DESC
-		LIMIT ?;
+		LIMIT ` + args.add(clipLimit) + `;
 	`
-	rows, err := m.db.Query(selectStmt, episodeID, clipLimit)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	rows, err := s.db.Query(selectStmt, args.values...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	clips := []*contracts.ClipInfo{}
 	for rows.Next() {
-		err := rows.Err()
-		if err != nil {
-			return nil, err
-		}
-
 		clip := new(contracts.ClipInfo)
-		initialDateCurated := new(time.Time)
-		lastDateCurated := new(time.Time)
+		var initialDateCurated, lastDateCurated time.Time
 		err = rows.Scan(
-			initialDateCurated,
-			lastDateCurated,
+			&initialDateCurated,
+			&lastDateCurated,
 			&clip.CuratorInformation,
 			&clip.Title,
 			&clip.Description,
 			&clip.MediaUri,
 			&clip.MediaType,
 			&clip.Priority,
+			&clip.MediaHash,
 		)
-
-		clip.InitialDateCurated = timestamppb.New(*initialDateCurated)
-		clip.LastDateCurated = timestamppb.New(*lastDateCurated)
-
 		if err != nil {
 			return nil, err
 		}
 
+		clip.InitialDateCurated = timestamppb.New(initialDateCurated)
+		clip.LastDateCurated = timestamppb.New(lastDateCurated)
+
 		clips = append(clips, clip)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	if len(clips) == 0 {
 		return nil, nil
@@ -150,6 +159,15 @@ func (m *MariaDbConnection) GetHighestPriorityClipsForEpisode(episode *contracts
 	return clips, nil
 }
 
+// belowPermanentFailureLimit returns a condition that excludes backlog items
+// whose episode/clip pair, episode, or clip has failed permanently too many
+// times.
+func belowPermanentFailureLimit(args *args) string {
+	return `(rf.research_id IS NULL OR rf.permanent_attempts < ` + args.add(datastore.PermanentFailureLimit) + `)
+			AND (ef.episode_id IS NULL OR ef.permanent_attempts < ` + args.add(datastore.PermanentFailureLimit) + `)
+			AND (cf.clip_id IS NULL OR cf.permanent_attempts < ` + args.add(datastore.PermanentFailureLimit) + `)`
+}
+
 // RecordCompletedResearch inserts a research item. The system currently
 // presumes that research is only assigned and conducted from the backlog
 // (episodes/clip pairs that have not previously been researched). Submitting
@@ -167,8 +185,8 @@ func (m *MariaDbConnection) GetHighestPriorityClipsForEpisode(episode *contracts
 // clip/episode pairs are non-matches.  Researched but negative pairings can be
 // inferred as pairs that are in neither the backlog table nor the completed
 // table.
-func (m *MariaDbConnection) RecordCompletedResearch(completedResearchItem *contracts.CompletedResearchItem) error {
-	found, episodeID, err := m.getEpisodeInfoID(completedResearchItem.EpisodeInfo)
+func (s *Store) RecordCompletedResearch(completedResearchItem *contracts.CompletedResearchItem) error {
+	found, episodeID, err := s.getEpisodeInfoID(completedResearchItem.EpisodeInfo)
 	if err != nil {
 		return err
 	}
@@ -176,7 +194,7 @@ func (m *MariaDbConnection) RecordCompletedResearch(completedResearchItem *contr
 		return fmt.Errorf("episodeID not found for episode: %v", completedResearchItem.EpisodeInfo)
 	}
 
-	found, clipID, err := m.getClipInfoID(completedResearchItem.ClipInfo)
+	found, clipID, err := s.getClipInfoID(completedResearchItem.ClipInfo)
 	if err != nil {
 		return err
 	}
@@ -184,7 +202,7 @@ func (m *MariaDbConnection) RecordCompletedResearch(completedResearchItem *contr
 		return fmt.Errorf("clipID not found for clip: %v", completedResearchItem.ClipInfo)
 	}
 
-	found, researchID, err := m.getResearchIDFromBacklog(episodeID, clipID)
+	found, researchID, err := s.getResearchIDFromBacklog(episodeID, clipID)
 	if err != nil {
 		return err
 	}
@@ -192,92 +210,68 @@ func (m *MariaDbConnection) RecordCompletedResearch(completedResearchItem *contr
 		return fmt.Errorf("researchID not found for researchItem: %v", completedResearchItem)
 	}
 
-	foundClipHash := true
-	const selectClipHashStmt = `SELECT 1 FROM clip_hashes WHERE clip_id = ?;`
-	row := m.db.QueryRow(selectClipHashStmt, clipID)
-	if row == nil || row.Err() == sql.ErrNoRows {
-		foundClipHash = false
-	} else if row.Err() != nil {
-		return row.Err()
-	}
-
-	foundEpisodeHash := true
-	const selectEpisodeHashStmt = `SELECT 1 FROM episode_hashes WHERE episode_id = ?;`
-	row = m.db.QueryRow(selectEpisodeHashStmt, episodeID)
-	if row == nil || row.Err() == sql.ErrNoRows {
-		foundEpisodeHash = false
-	} else if row.Err() != nil {
-		return row.Err()
-	}
-
-	tx, err := m.db.Begin()
+	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
 
-	if !foundClipHash {
-		const insertClipHashStmt = `INSERT INTO clip_hashes (clip_id, hash) VALUES (?,?);`
-		sqlResult, err := tx.Exec(insertClipHashStmt, clipID, completedResearchItem.ClipHash)
-		if err != nil {
-			return tryTxRollback(tx, err)
-		}
-		if err := expectOneRowAffected(sqlResult, nil); err != nil {
-			return tryTxRollback(tx, err)
-		}
+	// Hashes are only inserted the first time an episode or clip is
+	// researched, so a conflicting hash is left as it is.
+	insertClipHashStmt := fmt.Sprintf(`
+		INSERT INTO clip_hashes (clip_id, hash) VALUES (%v,%v)
+		%v hash = clip_hashes.hash;
+	`, s.dialect.Placeholder(1), s.dialect.Placeholder(2), s.dialect.OnConflict("clip_id"))
+	_, err = tx.Exec(insertClipHashStmt, clipID, completedResearchItem.ClipHash)
+	if err != nil {
+		return tryTxRollback(tx, err)
 	}
 
-	if !foundEpisodeHash {
-		const insertEpisodeHashStmt = `INSERT INTO episode_hashes (episode_id, hash) VALUES (?,?);`
-		sqlResult, err := tx.Exec(insertEpisodeHashStmt, episodeID, completedResearchItem.EpisodeHash)
-		if err != nil {
-			return tryTxRollback(tx, err)
-		}
-		if err := expectOneRowAffected(sqlResult, nil); err != nil {
-			return tryTxRollback(tx, err)
-		}
+	insertEpisodeHashStmt := fmt.Sprintf(`
+		INSERT INTO episode_hashes (episode_id, hash) VALUES (%v,%v)
+		%v hash = episode_hashes.hash;
+	`, s.dialect.Placeholder(1), s.dialect.Placeholder(2), s.dialect.OnConflict("episode_id"))
+	_, err = tx.Exec(insertEpisodeHashStmt, episodeID, completedResearchItem.EpisodeHash)
+	if err != nil {
+		return tryTxRollback(tx, err)
 	}
 
-	const deleteStmt = `DELETE FROM research_backlog WHERE research_id = ?`
+	deleteStmt := `DELETE FROM research_backlog WHERE research_id = ` + s.dialect.Placeholder(1) + `;`
 	_, err = tx.Exec(deleteStmt, researchID)
 	if err != nil {
 		return tryTxRollback(tx, err)
 	}
 
 	if len(completedResearchItem.ClipOffsets) > 0 {
-		const insertStmt = `
-		INSERT INTO research_complete (
-			research_id,
-			episode_id,
-			clip_id,
-			episode_duration_ns,
-			research_date
-		) VALUES (?,?,?,?,?);
-	`
-		sqlResult, err := tx.Exec(insertStmt,
+		args := newArgs(s.dialect)
+		insertStmt := `
+			INSERT INTO research_complete (
+				research_id,
+				episode_id,
+				clip_id,
+				episode_duration_ns,
+				clip_duration_ns,
+				research_date
+			) VALUES (` + args.addAll(
 			researchID,
 			episodeID,
 			clipID,
 			completedResearchItem.EpisodeDuration,
 			completedResearchItem.ClipDuration,
-			completedResearchItem.ResearchDate.AsTime(),
-		)
-		if err != nil {
-			return tryTxRollback(tx, err)
-		}
-		if err := expectOneRowAffected(sqlResult, nil); err != nil {
+			asDatetime(completedResearchItem.ResearchDate),
+		) + `);
+		`
+		sqlResult, err := tx.Exec(insertStmt, args.values...)
+		if err := expectOneRowAffected(sqlResult, err); err != nil {
 			return tryTxRollback(tx, err)
 		}
 
-		const insertOffsetStmt = `
+		insertOffsetStmt := fmt.Sprintf(`
 			INSERT INTO episode_clip_offsets (research_id, offset_ns)
-			VALUES (?, ?);
-		`
+			VALUES (%v, %v);
+		`, s.dialect.Placeholder(1), s.dialect.Placeholder(2))
 		for _, offset := range completedResearchItem.ClipOffsets {
 			sqlResult, err = tx.Exec(insertOffsetStmt, researchID, offset)
-			if err != nil {
-				return tryTxRollback(tx, err)
-			}
-			if err := expectOneRowAffected(sqlResult, nil); err != nil {
+			if err := expectOneRowAffected(sqlResult, err); err != nil {
 				return tryTxRollback(tx, err)
 			}
 		}
@@ -286,20 +280,12 @@ func (m *MariaDbConnection) RecordCompletedResearch(completedResearchItem *contr
 	return tx.Commit()
 }
 
-func (m *MariaDbConnection) getResearchIDFromBacklog(episodeID, clipID int) (bool, int, error) {
-	const selectStmt = `
-		SELECT research_id 
-		FROM research_backlog 
-		WHERE episode_id = ? AND clip_id = ?;
+func (s *Store) getResearchIDFromBacklog(episodeID, clipID int) (bool, int, error) {
+	args := newArgs(s.dialect)
+	selectStmt := `
+		SELECT research_id
+		FROM research_backlog
+		WHERE episode_id = ` + args.add(episodeID) + ` AND clip_id = ` + args.add(clipID) + `;
 	`
-	row := m.db.QueryRow(selectStmt, episodeID, clipID)
-	var researchID int
-	err := row.Scan(&researchID)
-	if err == sql.ErrNoRows {
-		return false, 0, nil
-	}
-	if err != nil {
-		return false, 0, err
-	}
-	return true, researchID, nil
+	return s.queryID(selectStmt, args.values...)
 }
@@ -0,0 +1,211 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/search"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// SearchEpisodes returns a page of the curated episodes that match the query,
+// ranked by how well they match (see the search package). Episodes are
+// matched, ranked, and paged by the database, using the title_tokens and
+// description_tokens columns.
+func (s *Store) SearchEpisodes(query *datastore.SearchQuery) ([]*contracts.EpisodeInfo, error) {
+	if err := query.Page.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := newArgs(s.dialect)
+	conditions := []string{}
+	if query.Curator != "" {
+		conditions = append(conditions, "ce.curator_info = "+args.add(query.Curator))
+	}
+	if !query.AiredFrom.IsZero() {
+		conditions = append(conditions, "ce.date_aired >= "+args.add(query.AiredFrom.UTC()))
+	}
+	if !query.AiredTo.IsZero() {
+		conditions = append(conditions, "ce.date_aired <= "+args.add(query.AiredTo.UTC()))
+	}
+
+	terms := search.NewMatcher(query.Text).Terms()
+	orderBy := "ce.date_aired DESC, ce.episode_id"
+	if len(terms) > 0 {
+		conditions = append(conditions, matchAny(args, "ce", terms))
+		orderBy = score(args, "ce", terms) + " DESC, " + orderBy
+	}
+
+	selectStmt := `
+		SELECT ` + selectEpisodeColumns + `
+		FROM curated_episodes ce
+		WHERE ` + where(conditions) + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + args.add(query.Page.Limit) + ` OFFSET ` + args.add(query.Page.Offset) + `;
+	`
+	rows, err := s.db.Query(selectStmt, args.values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	episodes := []*contracts.EpisodeInfo{}
+	for rows.Next() {
+		episode, err := scanEpisodeInfo(rows)
+		if err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, episode)
+	}
+	return episodes, rows.Err()
+}
+
+// SearchClips returns a page of the curated clips that match the query,
+// ranked by how well they match (see the search package). Clips are matched,
+// ranked, and paged by the database, using the title_tokens and
+// description_tokens columns. Clips have no air date, so an error is returned
+// if the query has an air date range.
+func (s *Store) SearchClips(query *datastore.SearchQuery) ([]*contracts.ClipInfo, error) {
+	if err := query.Page.Validate(); err != nil {
+		return nil, err
+	}
+	if query.HasAirDateRange() {
+		return nil, errors.New("clips cannot be filtered by air date")
+	}
+
+	args := newArgs(s.dialect)
+	conditions := []string{}
+	if query.Curator != "" {
+		conditions = append(conditions, "cc.curator_info = "+args.add(query.Curator))
+	}
+
+	terms := search.NewMatcher(query.Text).Terms()
+	orderBy := "cc.title, cc.clip_id"
+	if len(terms) > 0 {
+		conditions = append(conditions, matchAny(args, "cc", terms))
+		orderBy = score(args, "cc", terms) + " DESC, " + orderBy
+	}
+
+	selectStmt := `
+		SELECT ` + selectClipColumns + `
+		FROM curated_clips cc
+		WHERE ` + where(conditions) + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + args.add(query.Page.Limit) + ` OFFSET ` + args.add(query.Page.Offset) + `;
+	`
+	rows, err := s.db.Query(selectStmt, args.values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clips := []*contracts.ClipInfo{}
+	for rows.Next() {
+		clip, err := scanClipInfo(rows)
+		if err != nil {
+			return nil, err
+		}
+		clips = append(clips, clip)
+	}
+	return clips, rows.Err()
+}
+
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return "1 = 1"
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// like returns a condition that matches rows in which the clause's field
+// contains its substring.
+func like(args *args, table string, clause search.Clause) string {
+	column := table + ".title_tokens"
+	if clause.Field == search.Description {
+		column = table + ".description_tokens"
+	}
+	return fmt.Sprintf("%v LIKE %v", column, args.add("%"+clause.Substring+"%"))
+}
+
+// matchAny returns a condition that matches rows that satisfy any of the
+// terms' clauses. Every clause has a positive weight, so these are the rows
+// with a score greater than zero.
+func matchAny(args *args, table string, terms []search.Term) string {
+	conditions := []string{}
+	for _, term := range terms {
+		for _, clause := range term {
+			conditions = append(conditions, like(args, table, clause))
+		}
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// score returns an expression that computes the same score as
+// search.Matcher.Score.
+func score(args *args, table string, terms []search.Term) string {
+	expressions := []string{}
+	for _, term := range terms {
+		expression := "CASE"
+		for _, clause := range term {
+			weight := strconv.FormatFloat(clause.Weight, 'f', -1, 64)
+			expression += fmt.Sprintf(" WHEN %v THEN %v", like(args, table, clause), weight)
+		}
+		expressions = append(expressions, expression+" ELSE 0 END")
+	}
+	return "(" + strings.Join(expressions, " + ") + ")"
+}
+
+// IndexSearchTokens populates the title_tokens and description_tokens
+// columns of any curated episodes and clips that were curated before the
+// columns were added. Adapters call it after migrating their schema.
+func IndexSearchTokens(ctx context.Context, db *sql.DB, dialect *Dialect) error {
+	for _, table := range []struct{ name, idColumn string }{
+		{"curated_episodes", "episode_id"},
+		{"curated_clips", "clip_id"},
+	} {
+		selectStmt := fmt.Sprintf(`
+			SELECT %v, title, description
+			FROM %v
+			WHERE title_tokens IS NULL OR description_tokens IS NULL;
+		`, table.idColumn, table.name)
+		rows, err := db.QueryContext(ctx, selectStmt)
+		if err != nil {
+			return err
+		}
+
+		type unindexedRow struct {
+			id                 int
+			title, description string
+		}
+		unindexed := []unindexedRow{}
+		for rows.Next() {
+			row := unindexedRow{}
+			if err := rows.Scan(&row.id, &row.title, &row.description); err != nil {
+				rows.Close()
+				return err
+			}
+			unindexed = append(unindexed, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		updateStmt := fmt.Sprintf(
+			"UPDATE %v SET title_tokens = %v, description_tokens = %v WHERE %v = %v;",
+			table.name, dialect.Placeholder(1), dialect.Placeholder(2), table.idColumn, dialect.Placeholder(3),
+		)
+		for _, row := range unindexed {
+			_, err := db.ExecContext(ctx, updateStmt, search.Index(row.title), search.Index(row.description), row.id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Package sqlstore implements datastore.DataStorer for the mariadb, postgres,
+// and sqlite adapters. Their schemas are the same, so the queries only differ
+// in the syntax described by each adapter's Dialect.
+package sqlstore
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// A Dialect describes the syntax that differs between the sql adapters.
+type Dialect struct {
+	// Placeholder returns the placeholder for the nth argument of a
+	// statement, counting from one.
+	Placeholder func(n int) string
+
+	// OnConflict returns the clause that follows an INSERT statement's values
+	// to update, rather than insert, a row with the same key. The clause is
+	// followed by the assignments of the update.
+	OnConflict func(key string) string
+
+	// Excluded returns a reference, within the assignments of an OnConflict
+	// clause, to the value that would have been inserted into column.
+	Excluded func(column string) string
+
+	// LockRows returns the clause that follows a SELECT statement to lock the
+	// selected rows of the table with the supplied alias until the end of
+	// the transaction. Where the database supports it, rows that are already
+	// locked are skipped rather than waited for.
+	LockRows func(alias string) string
+}
+
+// The dialects of the sql adapters.
+var (
+	MariaDB = &Dialect{
+		Placeholder: func(int) string { return "?" },
+		OnConflict:  func(string) string { return "ON DUPLICATE KEY UPDATE" },
+		Excluded:    func(column string) string { return "VALUES(" + column + ")" },
+		LockRows:    func(string) string { return "FOR UPDATE" },
+	}
+
+	Postgres = &Dialect{
+		Placeholder: func(n int) string { return fmt.Sprintf("$%v", n) },
+		OnConflict:  onConflictDoUpdate,
+		Excluded:    excluded,
+		LockRows:    func(alias string) string { return "FOR UPDATE OF " + alias + " SKIP LOCKED" },
+	}
+
+	// sqlite only permits a single writer at a time, so rows never need to
+	// be locked.
+	SQLite = &Dialect{
+		Placeholder: func(int) string { return "?" },
+		OnConflict:  onConflictDoUpdate,
+		Excluded:    excluded,
+		LockRows:    func(string) string { return "" },
+	}
+)
+
+func onConflictDoUpdate(key string) string {
+	return fmt.Sprintf("ON CONFLICT (%v) DO UPDATE SET", key)
+}
+
+func excluded(column string) string {
+	return "excluded." + column
+}
+
+// A Store implements the methods of datastore.DataStorer that query the
+// database. The sql adapters embed a Store in their connections.
+type Store struct {
+	db      *sql.DB
+	dialect *Dialect
+}
+
+// NewStore returns a Store that queries db using the supplied dialect.
+func NewStore(db *sql.DB, dialect *Dialect) *Store {
+	return &Store{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+// args accumulates the arguments of a statement.
+type args struct {
+	placeholder func(n int) string
+	values      []interface{}
+}
+
+func newArgs(dialect *Dialect) *args {
+	return &args{
+		placeholder: dialect.Placeholder,
+		values:      []interface{}{},
+	}
+}
+
+// add appends an argument, and returns its placeholder. Arguments must be
+// added in the order in which their placeholders appear in the statement.
+func (a *args) add(value interface{}) string {
+	a.values = append(a.values, value)
+	return a.placeholder(len(a.values))
+}
+
+// addAll appends each of the values, and returns their placeholders separated
+// by commas.
+func (a *args) addAll(values ...interface{}) string {
+	placeholders := make([]string, 0, len(values))
+	for _, value := range values {
+		placeholders = append(placeholders, a.add(value))
+	}
+	return strings.Join(placeholders, ",")
+}
+
+// asDatetime converts a timestamp to the precision of mariadb's DATETIME
+// columns. Every adapter persists timestamps with this precision, which keeps
+// their behavior consistent, and ensures equality comparisons against stored
+// values are reliable.
+func asDatetime(ts *timestamppb.Timestamp) time.Time {
+	return ts.AsTime().UTC().Truncate(time.Second)
+}
+
+// expectOneRowAffected evaluates a sql.Result and an error. If err is not nil,
+// the function immediately returns err. If err is nil, then the function
+// evaluates sql.Result. If sql.Result.Error is not nil, that error is
+// returned.  If sql.Result.Error is nil, then sql.Result.RowsAffected is
+// checked. If the value is not 1, then an error is returned. Else, nil is
+// returned.
+func expectOneRowAffected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected != 1 {
+		return fmt.Errorf("expected one row to be affected, but %v were affected", rowsAffected)
+	}
+
+	return nil
+}
+
+// tryTxRollback attempts to roll back the supplied transaction. If
+// tx.Rollback returns an error, the rollback error and previousErr are joined
+// and returned as a single error. Otherwise previousErr is returned.
+func tryTxRollback(tx *sql.Tx, previousErr error) error {
+	if err := tx.Rollback(); err != nil {
+		return fmt.Errorf("%v\n%v", previousErr, err)
+	}
+	return previousErr
+}
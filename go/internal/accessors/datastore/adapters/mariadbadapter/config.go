@@ -18,6 +18,8 @@ type Config struct {
 
 func (c *Config) formatDSN() string {
 	dbconfig := mysql.NewConfig()
+	// The driver ignores Addr unless Net is also set.
+	dbconfig.Net = "tcp"
 	dbconfig.Addr = c.Addr
 	dbconfig.DBName = c.DBName
 	dbconfig.User = c.User
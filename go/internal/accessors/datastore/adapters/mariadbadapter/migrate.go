@@ -0,0 +1,98 @@
+package mariadbadapter
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/internal/sqlstore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/migrations"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+var dialect = &migrations.Dialect{
+	CreateHistoryTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT NOT NULL,
+			description VARCHAR(200) NOT NULL,
+			applied_on DATETIME NOT NULL,
+			PRIMARY KEY (version)
+		)
+	`,
+	HistoryTableExists: `
+		SELECT COUNT(*) > 0
+		FROM information_schema.tables
+		WHERE table_schema = DATABASE()
+			AND table_name = 'schema_migrations'
+	`,
+	SelectVersion: `SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1`,
+	InsertVersion: `INSERT INTO schema_migrations (version, description, applied_on) VALUES (?, ?, ?)`,
+	AcquireLock:   `SELECT GET_LOCK('tbtlarchivist_schema_migrations', 300)`,
+	ReleaseLock:   `SELECT RELEASE_LOCK('tbtlarchivist_schema_migrations')`,
+
+	// Databases that were bootstrapped before migrations were embedded were
+	// migrated with flyway, so we adopt whatever flyway has already applied.
+	BaselineVersion: func(ctx context.Context, conn *sql.Conn) (int, error) {
+		const flywayHistoryExistsSQL = `
+			SELECT COUNT(*)
+			FROM information_schema.tables
+			WHERE table_schema = DATABASE()
+				AND table_name = 'flyway_schema_history'
+		`
+		var flywayHistoryExists int
+		err := conn.QueryRowContext(ctx, flywayHistoryExistsSQL).Scan(&flywayHistoryExists)
+		if err != nil || flywayHistoryExists == 0 {
+			return -1, err
+		}
+
+		const flywayVersionSQL = `
+			SELECT CAST(version AS SIGNED) AS v
+			FROM flyway_schema_history
+			WHERE success = 1
+			ORDER BY v DESC
+			LIMIT 1
+		`
+		var version int
+		err = conn.QueryRowContext(ctx, flywayVersionSQL).Scan(&version)
+		if err == sql.ErrNoRows {
+			return -1, nil
+		}
+		return version, err
+	},
+}
+
+func newMigrator(db *sql.DB) (*migrations.Migrator, error) {
+	migrationsFS, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrations.New(db, dialect, migrationsFS)
+}
+
+// Migrate applies any embedded schema migrations that have not yet been
+// applied to the database. It is safe to call Migrate concurrently from
+// multiple hosts. Once the schema is up to date, any curated episodes and clips
+// that predate the storage of search tokens are indexed.
+func (m *MariaDb) Migrate(ctx context.Context) error {
+	db, err := sql.Open("mysql", m.config.formatDSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+	err = migrator.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Episodes and clips curated before search tokens were stored are indexed
+	// once the columns exist.
+	return sqlstore.IndexSearchTokens(ctx, db, sqlstore.MariaDB)
+}
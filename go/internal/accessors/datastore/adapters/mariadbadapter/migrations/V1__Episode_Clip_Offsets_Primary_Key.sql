@@ -0,0 +1,5 @@
+-- A clip can appear more than once within an episode, so each research item
+-- may have many offsets.
+ALTER TABLE `episode_clip_offsets`
+  DROP PRIMARY KEY,
+  ADD PRIMARY KEY (`research_id`, `offset_ns`);
@@ -0,0 +1,11 @@
+-- Failed attempts to research each episode/clip pair. A pair that has failed
+-- permanently too many times is no longer offered for research.
+CREATE TABLE `research_failures` (
+  `research_id` int(11) NOT NULL,
+  `attempts` int(11) NOT NULL,
+  `permanent_attempts` int(11) NOT NULL,
+  `failure_class` varchar(16) NOT NULL,
+  `message` longtext NOT NULL,
+  `last_failure_date` datetime NOT NULL,
+  PRIMARY KEY (`research_id`)
+);
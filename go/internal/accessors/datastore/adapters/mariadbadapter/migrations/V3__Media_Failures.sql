@@ -0,0 +1,16 @@
+-- Permanent failures attributed to an episode or clip itself, rather than to
+-- an episode/clip pair. An episode or clip that has failed permanently too many
+-- times is no longer offered for research with any clip or episode.
+CREATE TABLE `episode_failures` (
+  `episode_id` int(11) NOT NULL,
+  `permanent_attempts` int(11) NOT NULL,
+  `last_failure_date` datetime NOT NULL,
+  PRIMARY KEY (`episode_id`)
+);
+
+CREATE TABLE `clip_failures` (
+  `clip_id` int(11) NOT NULL,
+  `permanent_attempts` int(11) NOT NULL,
+  `last_failure_date` datetime NOT NULL,
+  PRIMARY KEY (`clip_id`)
+);
@@ -0,0 +1,10 @@
+-- The tokens of each title and description, as indexed by the search package,
+-- so that searches can be ranked and paged by the database. Rows that existed
+-- before this migration are indexed by the adapter once it has been applied.
+ALTER TABLE `curated_episodes`
+  ADD COLUMN `title_tokens` longtext DEFAULT NULL,
+  ADD COLUMN `description_tokens` longtext DEFAULT NULL;
+
+ALTER TABLE `curated_clips`
+  ADD COLUMN `title_tokens` longtext DEFAULT NULL,
+  ADD COLUMN `description_tokens` longtext DEFAULT NULL;
@@ -1,11 +1,12 @@
 package mariadbadapter
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
 	"time"
 
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/internal/sqlstore"
 )
 
 // MariaDb is an adapter that plugs into a mariadb instance.
@@ -17,7 +18,10 @@ type MariaDb struct {
 }
 
 // MariaDbConnection represents a successful connection to a mariadb instance.
+// Its queries are shared with the other sql adapters (see the sqlstore
+// package).
 type MariaDbConnection struct {
+	*sqlstore.Store
 	db *sql.DB
 }
 
@@ -29,6 +33,9 @@ func New(config *Config) *MariaDb {
 }
 
 // Connect attempts to open a connection to the underlaying mariadb instance.
+// If the database's schema is not at the latest embedded migration, a
+// *migrations.OutOfDateError is returned, and the schema must be brought up
+// to date via Migrate.
 func (m *MariaDb) Connect() (*MariaDbConnection, error) {
 	db, err := sql.Open("mysql", m.config.formatDSN())
 	if err != nil {
@@ -37,54 +44,20 @@ func (m *MariaDb) Connect() (*MariaDbConnection, error) {
 	db.SetConnMaxLifetime(m.config.MaxConnectionLifetime)
 	db.SetMaxOpenConns(m.config.MaxOpenConnections)
 	db.SetMaxIdleConns(m.config.MaxIdleConnections)
-	return &MariaDbConnection{
-		db: db,
-	}, nil
-}
-
-// expectOneRowAffected evaluates a sql.Result and an error. If err is not nil,
-// the function immediately returns err. If err is not nil, then the function
-// evaluates sql.Result. If sql.Result.Error is not nil, that error is
-// returned.  If sql.Result.Error is nil, then sql.Result.RowsAffected is
-// checked. If the value is not 1, then an error is returned. Else, nil is
-// returned.
-func expectOneRowAffected(result sql.Result, err error) error {
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected != 1 {
-		return fmt.Errorf("expected one row to be affected, but %v were affected", rowsAffected)
-	}
-
-	return nil
-}
-
-// tryTxRollback attempts to roll back the supplied transaction. If tx is nil,
-// the function will return previousErr. If tx is not nil, tx.Rollback is
-// called. If tx.Rollback returns an error, the rollback error and previousErr
-// are joined and returned as a single error. If the rollback succeeds without
-// error, previousErr is returned. previousErr is permitted to be nil.
-func tryTxRollback(tx *sql.Tx, previousErr error) error {
-	if tx == nil {
-		return previousErr
-	}
 
-	err := tx.Rollback()
+	migrator, err := newMigrator(db)
 	if err == nil {
-		return previousErr
+		err = migrator.CheckVersion(context.Background())
 	}
-
-	if err != nil && previousErr != nil {
-		err = fmt.Errorf("%v\n%v", previousErr, err)
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
 
-	return err
+	return &MariaDbConnection{
+		Store: sqlstore.NewStore(db, sqlstore.MariaDB),
+		db:    db,
+	}, nil
 }
 
 var _ datastore.DataStorer = (*MariaDbConnection)(nil)
@@ -0,0 +1,89 @@
+package mariadbadapter_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/mariadbadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/datastoretest"
+)
+
+// The conformance suite requires a running mariadb instance, so it only runs
+// when TBTLARCHIVIST_TEST_MARIADB_ADDR is set (e.g. "127.0.0.1:3306"). The
+// embedded migrations are applied before the suite runs, and every table in
+// the test database is truncated before each test, so the database should be
+// dedicated to testing.
+// The database name defaults to "tbtlarchivist_test" and may be overridden
+// with TBTLARCHIVIST_TEST_MARIADB_DBNAME.
+func Test_DataStorerConformance(t *testing.T) {
+	addr := os.Getenv("TBTLARCHIVIST_TEST_MARIADB_ADDR")
+	if addr == "" {
+		t.Skip("TBTLARCHIVIST_TEST_MARIADB_ADDR is not set")
+	}
+
+	dbName := os.Getenv("TBTLARCHIVIST_TEST_MARIADB_DBNAME")
+	if dbName == "" {
+		dbName = "tbtlarchivist_test"
+	}
+
+	config := &mariadbadapter.Config{
+		Addr:                  addr,
+		DBName:                dbName,
+		User:                  "root",
+		MaxConnectionLifetime: 60 * time.Second,
+		MaxOpenConnections:    5,
+		MaxIdleConnections:    5,
+	}
+
+	if err := mariadbadapter.New(config).Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	datastoretest.RunDataStorerSuite(t, func(t *testing.T) datastore.DataStorer {
+		truncateAllTables(t, config)
+		db, err := mariadbadapter.New(config).Connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	})
+}
+
+func truncateAllTables(t *testing.T, config *mariadbadapter.Config) {
+	t.Helper()
+
+	dsn := mysql.NewConfig()
+	dsn.Net = "tcp"
+	dsn.Addr = config.Addr
+	dsn.DBName = config.DBName
+	dsn.User = config.User
+	db, err := sql.Open("mysql", dsn.FormatDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tables := []string{
+		"curated_clips",
+		"curated_episodes",
+		"research_backlog",
+		"research_leases",
+		"research_failures",
+		"research_complete",
+		"episode_clip_offsets",
+		"episode_hashes",
+		"clip_hashes",
+		"episode_failures",
+		"clip_failures",
+	}
+	for _, table := range tables {
+		if _, err := db.Exec("TRUNCATE TABLE " + table); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
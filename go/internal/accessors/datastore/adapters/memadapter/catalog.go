@@ -0,0 +1,99 @@
+package memadapter
+
+import (
+	"sort"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// ListEpisodes returns a page of curated episodes, ordered by the date each
+// episode aired (newest first).
+func (m *MemoryDb) ListEpisodes(page datastore.Page) ([]*contracts.EpisodeInfo, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := make([]*episodeRow, 0, len(m.curatedEpisodes))
+	for _, row := range m.curatedEpisodes {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].dateAired.Equal(rows[j].dateAired) {
+			return rows[i].dateAired.After(rows[j].dateAired)
+		}
+		return rows[i].episodeID < rows[j].episodeID
+	})
+
+	start, end := pageBounds(len(rows), page)
+	episodes := make([]*contracts.EpisodeInfo, 0, end-start)
+	for _, row := range rows[start:end] {
+		episodes = append(episodes, row.toEpisodeInfo())
+	}
+	return episodes, nil
+}
+
+// ListClips returns a page of curated clips, ordered by title.
+func (m *MemoryDb) ListClips(page datastore.Page) ([]*contracts.ClipInfo, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := make([]*clipRow, 0, len(m.curatedClips))
+	for _, row := range m.curatedClips {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].title != rows[j].title {
+			return rows[i].title < rows[j].title
+		}
+		return rows[i].clipID < rows[j].clipID
+	})
+
+	start, end := pageBounds(len(rows), page)
+	clips := make([]*contracts.ClipInfo, 0, end-start)
+	for _, row := range rows[start:end] {
+		clips = append(clips, row.toClipInfo())
+	}
+	return clips, nil
+}
+
+// GetResearchStatistics summarizes the state of the archive.
+func (m *MemoryDb) GetResearchStatistics() (*datastore.ResearchStatistics, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	leasedBacklogItems := 0
+	for researchID := range m.researchBacklog {
+		if _, leased := m.researchLeases[researchID]; leased {
+			leasedBacklogItems++
+		}
+	}
+
+	return &datastore.ResearchStatistics{
+		Episodes:           len(m.curatedEpisodes),
+		Clips:              len(m.curatedClips),
+		BacklogItems:       len(m.researchBacklog),
+		LeasedBacklogItems: leasedBacklogItems,
+		CompletedItems:     len(m.researchComplete),
+	}, nil
+}
+
+// pageBounds returns the start and end indices of a page within a sorted
+// result of length n.
+func pageBounds(n int, page datastore.Page) (int, int) {
+	if page.Offset >= n {
+		return n, n
+	}
+	end := page.Offset + page.Limit
+	if end > n {
+		end = n
+	}
+	return page.Offset, end
+}
@@ -0,0 +1,104 @@
+package memadapter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FindClipAppearances returns the completed research for every episode in
+// which the clip was found, ordered by the date the episode aired (newest
+// first). Each item includes every offset at which the clip occurs within the
+// episode. If the clip does not exist, an error wrapping datastore.ErrNotFound
+// is returned.
+func (m *MemoryDb) FindClipAppearances(clip *contracts.ClipInfo, page datastore.Page) ([]*contracts.CompletedResearchItem, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found, clipID := m.getClipInfoID(clip)
+	if !found {
+		return nil, fmt.Errorf("clip %v: %w", clip.Title, datastore.ErrNotFound)
+	}
+
+	matches := []*completeRow{}
+	for _, row := range m.researchComplete {
+		if row.clipID == clipID {
+			matches = append(matches, row)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := m.curatedEpisodes[matches[i].episodeID], m.curatedEpisodes[matches[j].episodeID]
+		if !a.dateAired.Equal(b.dateAired) {
+			return a.dateAired.After(b.dateAired)
+		}
+		return a.episodeID < b.episodeID
+	})
+
+	start, end := pageBounds(len(matches), page)
+	return m.toCompletedResearchItems(matches[start:end]), nil
+}
+
+// FindEpisodeClips returns the completed research for every clip that was
+// found within the episode, ordered by clip title. Each item includes every
+// offset at which the clip occurs within the episode. If the episode does not
+// exist, an error wrapping datastore.ErrNotFound is returned.
+func (m *MemoryDb) FindEpisodeClips(episode *contracts.EpisodeInfo, page datastore.Page) ([]*contracts.CompletedResearchItem, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found, episodeID := m.getEpisodeInfoID(episode)
+	if !found {
+		return nil, fmt.Errorf("episode %v: %w", episode.Title, datastore.ErrNotFound)
+	}
+
+	matches := []*completeRow{}
+	for _, row := range m.researchComplete {
+		if row.episodeID == episodeID {
+			matches = append(matches, row)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := m.curatedClips[matches[i].clipID], m.curatedClips[matches[j].clipID]
+		if a.title != b.title {
+			return a.title < b.title
+		}
+		return a.clipID < b.clipID
+	})
+
+	start, end := pageBounds(len(matches), page)
+	return m.toCompletedResearchItems(matches[start:end]), nil
+}
+
+func (m *MemoryDb) toCompletedResearchItems(rows []*completeRow) []*contracts.CompletedResearchItem {
+	items := make([]*contracts.CompletedResearchItem, 0, len(rows))
+	for _, row := range rows {
+		offsets := make([]int64, len(m.episodeClipOffsets[row.researchID]))
+		copy(offsets, m.episodeClipOffsets[row.researchID])
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+		items = append(items, &contracts.CompletedResearchItem{
+			ResearchDate:    timestamppb.New(row.researchDate),
+			EpisodeInfo:     m.curatedEpisodes[row.episodeID].toEpisodeInfo(),
+			ClipInfo:        m.curatedClips[row.clipID].toClipInfo(),
+			EpisodeDuration: row.episodeDurationNs,
+			EpisodeHash:     m.episodeHashes[row.episodeID],
+			ClipDuration:    row.clipDurationNs,
+			ClipHash:        m.clipHashes[row.clipID],
+			ClipOffsets:     offsets,
+		})
+	}
+	return items
+}
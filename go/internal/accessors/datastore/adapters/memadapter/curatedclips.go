@@ -0,0 +1,90 @@
+package memadapter
+
+import (
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// UpsertClipInfo inserts or updates clip info. If the clip already exists, it
+// will be updated, but its InitialDateCurated value is ignored. If the clip
+// does not already exist, both InitialDateCurated and LastDateCurated are
+// evaluated, but the insert will fail and an error will be returned if
+// LastDateCurated is earlier than InitialDateCurated. Inserting a new clip
+// adds the clip to the research backlog for every known episode.
+func (m *MemoryDb) UpsertClipInfo(clipInfo *contracts.ClipInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clipExists, clipID := m.getClipInfoID(clipInfo)
+	if clipExists {
+		return m.updateClipInfo(clipID, clipInfo)
+	}
+	return m.insertClipInfo(clipInfo)
+}
+
+func (m *MemoryDb) getClipInfoID(clipInfo *contracts.ClipInfo) (bool, int) {
+	for _, row := range m.curatedClips {
+		if row.title == clipInfo.Title {
+			return true, row.clipID
+		}
+	}
+	return false, 0
+}
+
+func (m *MemoryDb) checkClipMediaURIIsUnique(clipID int, mediaURI string) error {
+	for _, row := range m.curatedClips {
+		if row.clipID != clipID && row.mediaURI == mediaURI {
+			return duplicateEntryError(mediaURI, "media_uri_UNIQUE")
+		}
+	}
+	return nil
+}
+
+func (m *MemoryDb) updateClipInfo(clipID int, clipInfo *contracts.ClipInfo) error {
+	if err := m.checkClipMediaURIIsUnique(clipID, clipInfo.MediaUri); err != nil {
+		return err
+	}
+
+	// Note that on updates, we update the `last_date_curated` field and ignore
+	// the  `initial_date_curated` field.
+	row := m.curatedClips[clipID]
+	row.lastDateCurated = asDatetime(clipInfo.LastDateCurated)
+	row.curatorInfo = clipInfo.CuratorInformation
+	row.title = clipInfo.Title
+	row.description = clipInfo.Description
+	row.mediaURI = clipInfo.MediaUri
+	row.mediaType = clipInfo.MediaType
+	row.priority = clipInfo.Priority
+	return nil
+}
+
+func (m *MemoryDb) insertClipInfo(clipInfo *contracts.ClipInfo) error {
+	if clipInfo.LastDateCurated.AsTime().Before(clipInfo.InitialDateCurated.AsTime()) {
+		return fmt.Errorf("LastDateCurated must not be earlier than InitialDateCurated. %v", clipInfo)
+	}
+
+	if err := m.checkClipMediaURIIsUnique(0, clipInfo.MediaUri); err != nil {
+		return err
+	}
+
+	m.lastClipID++
+	newClipID := m.lastClipID
+	m.curatedClips[newClipID] = &clipRow{
+		clipID:             newClipID,
+		initialDateCurated: asDatetime(clipInfo.InitialDateCurated),
+		lastDateCurated:    asDatetime(clipInfo.LastDateCurated),
+		curatorInfo:        clipInfo.CuratorInformation,
+		title:              clipInfo.Title,
+		description:        clipInfo.Description,
+		mediaURI:           clipInfo.MediaUri,
+		mediaType:          clipInfo.MediaType,
+		priority:           clipInfo.Priority,
+	}
+
+	for episodeID := range m.curatedEpisodes {
+		m.insertBacklogRow(episodeID, newClipID)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package memadapter
+
+import (
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// UpsertEpisodeInfo inserts or updates episode info. If the episode already
+// exists, it will be updated, but its InitialDateCurated value is ignored. If
+// the episode does not already exist, both InitialDateCurated and
+// LastDateCurated are evaluated, but the insert will fail and an error will be
+// returned if LastDateCurated is earlier than InitialDateCurated. Inserting a
+// new episode adds the episode to the research backlog for every known clip.
+func (m *MemoryDb) UpsertEpisodeInfo(episodeInfo *contracts.EpisodeInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	episodeExists, episodeID := m.getEpisodeInfoID(episodeInfo)
+	if episodeExists {
+		return m.updateEpisodeInfo(episodeID, episodeInfo)
+	}
+	return m.insertEpisodeInfo(episodeInfo)
+}
+
+func (m *MemoryDb) getEpisodeInfoID(episodeInfo *contracts.EpisodeInfo) (bool, int) {
+	dateAired := asDatetime(episodeInfo.DateAired)
+	for _, row := range m.curatedEpisodes {
+		if row.title == episodeInfo.Title && row.dateAired.Equal(dateAired) {
+			return true, row.episodeID
+		}
+	}
+	return false, 0
+}
+
+func (m *MemoryDb) checkEpisodeMediaURIIsUnique(episodeID int, mediaURI string) error {
+	for _, row := range m.curatedEpisodes {
+		if row.episodeID != episodeID && row.mediaURI == mediaURI {
+			return duplicateEntryError(mediaURI, "media_uri_UNIQUE")
+		}
+	}
+	return nil
+}
+
+func (m *MemoryDb) updateEpisodeInfo(episodeID int, episodeInfo *contracts.EpisodeInfo) error {
+	if err := m.checkEpisodeMediaURIIsUnique(episodeID, episodeInfo.MediaUri); err != nil {
+		return err
+	}
+
+	// Note that on updates, we update the `last_date_curated` field and ignore
+	// the  `initial_date_curated` field.
+	row := m.curatedEpisodes[episodeID]
+	row.lastDateCurated = asDatetime(episodeInfo.LastDateCurated)
+	row.curatorInfo = episodeInfo.CuratorInformation
+	row.dateAired = asDatetime(episodeInfo.DateAired)
+	row.title = episodeInfo.Title
+	row.description = episodeInfo.Description
+	row.mediaURI = episodeInfo.MediaUri
+	row.mediaType = episodeInfo.MediaType
+	row.priority = episodeInfo.Priority
+	return nil
+}
+
+func (m *MemoryDb) insertEpisodeInfo(episodeInfo *contracts.EpisodeInfo) error {
+	if episodeInfo.LastDateCurated.AsTime().Before(episodeInfo.InitialDateCurated.AsTime()) {
+		return fmt.Errorf("LastDateCurated must not be earlier than InitialDateCurated. %v", episodeInfo)
+	}
+
+	if err := m.checkEpisodeMediaURIIsUnique(0, episodeInfo.MediaUri); err != nil {
+		return err
+	}
+
+	m.lastEpisodeID++
+	newEpisodeID := m.lastEpisodeID
+	m.curatedEpisodes[newEpisodeID] = &episodeRow{
+		episodeID:          newEpisodeID,
+		initialDateCurated: asDatetime(episodeInfo.InitialDateCurated),
+		lastDateCurated:    asDatetime(episodeInfo.LastDateCurated),
+		curatorInfo:        episodeInfo.CuratorInformation,
+		dateAired:          asDatetime(episodeInfo.DateAired),
+		title:              episodeInfo.Title,
+		description:        episodeInfo.Description,
+		mediaURI:           episodeInfo.MediaUri,
+		mediaType:          episodeInfo.MediaType,
+		priority:           episodeInfo.Priority,
+	}
+
+	for clipID := range m.curatedClips {
+		m.insertBacklogRow(newEpisodeID, clipID)
+	}
+
+	return nil
+}
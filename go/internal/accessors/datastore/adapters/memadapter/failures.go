@@ -0,0 +1,85 @@
+package memadapter
+
+import (
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// RecordResearchFailure counts a failed attempt to research each of the
+// failure's episode/clip pairs, and releases the pairs from the failure's
+// lease. Pairs that are no longer in the research backlog (because they've
+// since been researched) are ignored. If the episode or any clip doesn't
+// exist, nothing is recorded and an error is returned.
+func (m *MemoryDb) RecordResearchFailure(failure *contracts.ResearchFailure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found, episodeID := m.getEpisodeInfoID(failure.EpisodeInfo)
+	if !found {
+		return fmt.Errorf("episodeID not found for episode: %v", failure.EpisodeInfo)
+	}
+
+	clipIDs := []int{}
+	researchIDs := []int{}
+	for _, clip := range failure.ClipInfos {
+		found, clipID := m.getClipInfoID(clip)
+		if !found {
+			return fmt.Errorf("clipID not found for clip: %v", clip)
+		}
+		clipIDs = append(clipIDs, clipID)
+		if found, researchID := m.getResearchIDFromBacklog(episodeID, clipID); found {
+			researchIDs = append(researchIDs, researchID)
+		}
+	}
+
+	for _, researchID := range researchIDs {
+		row, found := m.researchFailures[researchID]
+		if !found {
+			row = &failureRow{researchID: researchID}
+			m.researchFailures[researchID] = row
+		}
+		row.attempts++
+		if failure.FailureClass == contracts.FailureClass_PERMANENT {
+			row.permanentAttempts++
+		}
+		row.failureClass = failure.FailureClass
+		row.message = failure.Message
+		row.lastFailureDate = asDatetime(failure.FailureDate)
+
+		if lease, leased := m.researchLeases[researchID]; leased && lease.leaseID.String() == failure.LeaseId {
+			delete(m.researchLeases, researchID)
+		}
+	}
+
+	// Permanent failures that are attributed to the episode or clips
+	// themselves are also counted against that media, so that it's no longer
+	// offered for research with any other clip or episode.
+	if failure.FailureClass == contracts.FailureClass_PERMANENT {
+		switch failure.FailedMedia {
+		case contracts.FailedMedia_EPISODE:
+			m.episodeFailures[episodeID]++
+		case contracts.FailedMedia_CLIPS:
+			for _, clipID := range clipIDs {
+				m.clipFailures[clipID]++
+			}
+		}
+	}
+
+	return nil
+}
+
+// failedPermanently returns true if research of the backlog item, or of its
+// episode or clip, has failed permanently too many times for it to be
+// attempted again. The caller must hold the lock.
+func (m *MemoryDb) failedPermanently(backlogItem *backlogRow) bool {
+	if m.episodeFailures[backlogItem.episodeID] >= datastore.PermanentFailureLimit {
+		return true
+	}
+	if m.clipFailures[backlogItem.clipID] >= datastore.PermanentFailureLimit {
+		return true
+	}
+	row, found := m.researchFailures[backlogItem.researchID]
+	return found && row.permanentAttempts >= datastore.PermanentFailureLimit
+}
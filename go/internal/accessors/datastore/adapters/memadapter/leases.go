@@ -0,0 +1,109 @@
+package memadapter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// CreateResearchLease attempts to create a lease that is shared between the
+// episode and all provided clips.  This method will panic if clips is empty or
+// nil. If there are no clips to lease for an episode, that should be handled
+// without attempting to call this method. If any of the episode/clip pairs is
+// not in the research backlog, or is already leased, no leases are created and
+// an error is returned.
+func (m *MemoryDb) CreateResearchLease(newLeaseID *uuid.UUID, episode *contracts.EpisodeInfo, clips []*contracts.ClipInfo, expiration time.Time) error {
+	if len(clips) == 0 {
+		panic("a non-zero number of clips must be supplied to this method")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found, episodeID := m.getEpisodeInfoID(episode)
+	if !found {
+		return fmt.Errorf("episode not found in database %v", episode)
+	}
+
+	// All pairs are validated before any lease is written so that the
+	// operation is all-or-nothing.
+	newLeases := map[int]*leaseRow{}
+	for _, clip := range clips {
+		found, clipID := m.getClipInfoID(clip)
+		if !found {
+			return fmt.Errorf("clip not found in database %v", clip)
+		}
+
+		found, researchID := m.getResearchIDFromBacklog(episodeID, clipID)
+		if !found {
+			return fmt.Errorf("episode/clip pair not found in research backlog %v %v", episode, clip)
+		}
+
+		if _, leased := m.researchLeases[researchID]; leased {
+			return duplicateEntryError(researchID, "research_id_UNIQUE")
+		}
+		if _, duplicate := newLeases[researchID]; duplicate {
+			return duplicateEntryError(fmt.Sprintf("%v-%v", newLeaseID, researchID), "PRIMARY")
+		}
+
+		newLeases[researchID] = &leaseRow{
+			leaseID:    *newLeaseID,
+			researchID: researchID,
+			expiration: expiration.UTC().Truncate(time.Second),
+		}
+	}
+
+	for researchID, lease := range newLeases {
+		m.researchLeases[researchID] = lease
+	}
+
+	return nil
+}
+
+// RenewResearchLease updates the deadline for an existing lease. If the lease
+// doesn't exist, no action is taken.
+func (m *MemoryDb) RenewResearchLease(leaseID uuid.UUID, expiration time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, lease := range m.researchLeases {
+		if lease.leaseID == leaseID {
+			lease.expiration = expiration.UTC().Truncate(time.Second)
+		}
+	}
+	return nil
+}
+
+// RevokeResearchLease removes the leases for all items assigned to the
+// specified leaseID. If the leaseID doesn't exist, no action is taken.
+func (m *MemoryDb) RevokeResearchLease(leaseID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for researchID, lease := range m.researchLeases {
+		if lease.leaseID == leaseID {
+			delete(m.researchLeases, researchID)
+		}
+	}
+	return nil
+}
+
+// ReapExpiredLeases removes every lease that expired before now, which
+// returns the leased items to the research backlog. The number of leased
+// items that were reclaimed is returned.
+func (m *MemoryDb) ReapExpiredLeases(now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now = now.UTC().Truncate(time.Second)
+	reclaimed := 0
+	for researchID, lease := range m.researchLeases {
+		if lease.expiration.Before(now) {
+			delete(m.researchLeases, researchID)
+			reclaimed++
+		}
+	}
+	return reclaimed, nil
+}
@@ -0,0 +1,182 @@
+// Package memadapter provides an in-process implementation of
+// datastore.DataStorer. The adapter models the same tables as the mariadb
+// schema (see the mariadbadapter's migrations), and mirrors the behavior of
+// the mariadbadapter, which makes it suitable for unit tests and for running
+// the archive locally without a database server. Nothing is persisted beyond
+// the lifetime of the process.
+package memadapter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MemoryDb is an in-memory datastore. The zero value is not usable; use New
+// to create an instance. All methods are safe for concurrent use, and each
+// method is applied atomically.
+type MemoryDb struct {
+	mu sync.Mutex
+
+	curatedClips    map[int]*clipRow
+	curatedEpisodes map[int]*episodeRow
+	researchBacklog map[int]*backlogRow
+
+	// researchLeases is keyed by research_id, which is unique within the
+	// research_leases table.
+	researchLeases     map[int]*leaseRow
+	researchComplete   map[int]*completeRow
+	episodeClipOffsets map[int][]int64
+	episodeHashes      map[int]string
+	clipHashes         map[int]string
+
+	// researchFailures is keyed by research_id.
+	researchFailures map[int]*failureRow
+
+	// episodeFailures and clipFailures count the permanent failures that were
+	// attributed to an episode or clip, keyed by episode_id and clip_id.
+	episodeFailures map[int]int
+	clipFailures    map[int]int
+
+	lastClipID     int
+	lastEpisodeID  int
+	lastResearchID int
+}
+
+type clipRow struct {
+	clipID             int
+	initialDateCurated time.Time
+	lastDateCurated    time.Time
+	curatorInfo        string
+	title              string
+	description        string
+	mediaURI           string
+	mediaType          string
+	priority           int32
+}
+
+type episodeRow struct {
+	episodeID          int
+	initialDateCurated time.Time
+	lastDateCurated    time.Time
+	curatorInfo        string
+	dateAired          time.Time
+	title              string
+	description        string
+	mediaURI           string
+	mediaType          string
+	priority           int32
+}
+
+type backlogRow struct {
+	researchID int
+	episodeID  int
+	clipID     int
+}
+
+type leaseRow struct {
+	leaseID    uuid.UUID
+	researchID int
+	expiration time.Time
+}
+
+type completeRow struct {
+	researchID        int
+	episodeID         int
+	clipID            int
+	episodeDurationNs int64
+	clipDurationNs    int64
+	researchDate      time.Time
+}
+
+type failureRow struct {
+	researchID        int
+	attempts          int
+	permanentAttempts int
+	failureClass      contracts.FailureClass
+	message           string
+	lastFailureDate   time.Time
+}
+
+// New returns a reference to a new, empty MemoryDb instance.
+func New() *MemoryDb {
+	return &MemoryDb{
+		curatedClips:       map[int]*clipRow{},
+		curatedEpisodes:    map[int]*episodeRow{},
+		researchBacklog:    map[int]*backlogRow{},
+		researchLeases:     map[int]*leaseRow{},
+		researchComplete:   map[int]*completeRow{},
+		episodeClipOffsets: map[int][]int64{},
+		episodeHashes:      map[int]string{},
+		clipHashes:         map[int]string{},
+		researchFailures:   map[int]*failureRow{},
+		episodeFailures:    map[int]int{},
+		clipFailures:       map[int]int{},
+	}
+}
+
+// asDatetime converts a timestamp to the precision of a `datetime` column,
+// which is how timestamps are persisted by the sql adapters.
+func asDatetime(ts *timestamppb.Timestamp) time.Time {
+	return ts.AsTime().UTC().Truncate(time.Second)
+}
+
+func (c *clipRow) toClipInfo() *contracts.ClipInfo {
+	return &contracts.ClipInfo{
+		InitialDateCurated: timestamppb.New(c.initialDateCurated),
+		LastDateCurated:    timestamppb.New(c.lastDateCurated),
+		CuratorInformation: c.curatorInfo,
+		Title:              c.title,
+		Description:        c.description,
+		MediaUri:           c.mediaURI,
+		MediaType:          c.mediaType,
+		Priority:           c.priority,
+	}
+}
+
+func (e *episodeRow) toEpisodeInfo() *contracts.EpisodeInfo {
+	return &contracts.EpisodeInfo{
+		InitialDateCurated: timestamppb.New(e.initialDateCurated),
+		LastDateCurated:    timestamppb.New(e.lastDateCurated),
+		CuratorInformation: e.curatorInfo,
+		DateAired:          timestamppb.New(e.dateAired),
+		Title:              e.title,
+		Description:        e.description,
+		MediaUri:           e.mediaURI,
+		MediaType:          e.mediaType,
+		Priority:           e.priority,
+	}
+}
+
+// getResearchIDFromBacklog returns the research_id of the backlog row for the
+// supplied episode/clip pair. The caller must hold the lock.
+func (m *MemoryDb) getResearchIDFromBacklog(episodeID, clipID int) (bool, int) {
+	for _, row := range m.researchBacklog {
+		if row.episodeID == episodeID && row.clipID == clipID {
+			return true, row.researchID
+		}
+	}
+	return false, 0
+}
+
+// insertBacklogRow adds an episode/clip pair to the research backlog. The
+// caller must hold the lock.
+func (m *MemoryDb) insertBacklogRow(episodeID, clipID int) {
+	m.lastResearchID++
+	m.researchBacklog[m.lastResearchID] = &backlogRow{
+		researchID: m.lastResearchID,
+		episodeID:  episodeID,
+		clipID:     clipID,
+	}
+}
+
+func duplicateEntryError(value interface{}, key string) error {
+	return fmt.Errorf("duplicate entry '%v' for key '%v'", value, key)
+}
+
+var _ datastore.DataStorer = (*MemoryDb)(nil)
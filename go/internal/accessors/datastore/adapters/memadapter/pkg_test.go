@@ -0,0 +1,15 @@
+package memadapter_test
+
+import (
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/memadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/datastoretest"
+)
+
+func Test_DataStorerConformance(t *testing.T) {
+	datastoretest.RunDataStorerSuite(t, func(t *testing.T) datastore.DataStorer {
+		return memadapter.New()
+	})
+}
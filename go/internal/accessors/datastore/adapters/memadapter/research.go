@@ -0,0 +1,163 @@
+package memadapter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// GetHighestPriorityEpisode identifies and returns the highest priority
+// episode to be researched. Only episodes that have at least one unleased item
+// in the research backlog are considered. If no episodes are available, this
+// returns nil, nil.
+func (m *MemoryDb) GetHighestPriorityEpisode() (*contracts.EpisodeInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var best *episodeRow
+	for _, backlogItem := range m.researchBacklog {
+		if _, leased := m.researchLeases[backlogItem.researchID]; leased {
+			continue
+		}
+		if m.failedPermanently(backlogItem) {
+			continue
+		}
+		candidate := m.curatedEpisodes[backlogItem.episodeID]
+		if best == nil || episodeHasHigherPriority(candidate, best) {
+			best = candidate
+		}
+	}
+
+	if best == nil {
+		return nil, nil
+	}
+
+	episodeInfo := best.toEpisodeInfo()
+	episodeInfo.MediaHash = m.episodeHashes[best.episodeID]
+	return episodeInfo, nil
+}
+
+// episodeHasHigherPriority orders episodes by priority, date aired, and
+// initial date curated (all descending). The episode_id is used as a final
+// tie-breaker so that results are deterministic.
+func episodeHasHigherPriority(a, b *episodeRow) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	if !a.dateAired.Equal(b.dateAired) {
+		return a.dateAired.After(b.dateAired)
+	}
+	if !a.initialDateCurated.Equal(b.initialDateCurated) {
+		return a.initialDateCurated.After(b.initialDateCurated)
+	}
+	return a.episodeID < b.episodeID
+}
+
+// GetHighestPriorityClipsForEpisode identifies and returns the highest
+// priority clips to be researched for given episode. The number of clips
+// returned is limited to `clipLimit`. If no clips are available for the
+// supplied episode, this returns nil, nil.
+func (m *MemoryDb) GetHighestPriorityClipsForEpisode(episode *contracts.EpisodeInfo, clipLimit int) ([]*contracts.ClipInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found, episodeID := m.getEpisodeInfoID(episode)
+	if !found {
+		return nil, fmt.Errorf("episode not found: %v", episode)
+	}
+
+	candidates := []*clipRow{}
+	for _, backlogItem := range m.researchBacklog {
+		if backlogItem.episodeID != episodeID {
+			continue
+		}
+		if _, leased := m.researchLeases[backlogItem.researchID]; leased {
+			continue
+		}
+		if m.failedPermanently(backlogItem) {
+			continue
+		}
+		candidates = append(candidates, m.curatedClips[backlogItem.clipID])
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		if !a.initialDateCurated.Equal(b.initialDateCurated) {
+			return a.initialDateCurated.After(b.initialDateCurated)
+		}
+		return a.clipID < b.clipID
+	})
+
+	if len(candidates) > clipLimit {
+		candidates = candidates[:clipLimit]
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	clips := make([]*contracts.ClipInfo, 0, len(candidates))
+	for _, candidate := range candidates {
+		clip := candidate.toClipInfo()
+		clip.MediaHash = m.clipHashes[candidate.clipID]
+		clips = append(clips, clip)
+	}
+
+	return clips, nil
+}
+
+// RecordCompletedResearch records a research item, following the same rules
+// as the mariadbadapter. The episode/clip pair must be in the research backlog.
+// Episode and clip hashes are only inserted; never updated. The pair is
+// always removed from the backlog, but the research is only added to the
+// completed research table if the clip was found within the episode (the item
+// has at least one offset).
+func (m *MemoryDb) RecordCompletedResearch(completedResearchItem *contracts.CompletedResearchItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found, episodeID := m.getEpisodeInfoID(completedResearchItem.EpisodeInfo)
+	if !found {
+		return fmt.Errorf("episodeID not found for episode: %v", completedResearchItem.EpisodeInfo)
+	}
+
+	found, clipID := m.getClipInfoID(completedResearchItem.ClipInfo)
+	if !found {
+		return fmt.Errorf("clipID not found for clip: %v", completedResearchItem.ClipInfo)
+	}
+
+	found, researchID := m.getResearchIDFromBacklog(episodeID, clipID)
+	if !found {
+		return fmt.Errorf("researchID not found for researchItem: %v", completedResearchItem)
+	}
+
+	if _, foundClipHash := m.clipHashes[clipID]; !foundClipHash {
+		m.clipHashes[clipID] = completedResearchItem.ClipHash
+	}
+
+	if _, foundEpisodeHash := m.episodeHashes[episodeID]; !foundEpisodeHash {
+		m.episodeHashes[episodeID] = completedResearchItem.EpisodeHash
+	}
+
+	delete(m.researchBacklog, researchID)
+
+	if len(completedResearchItem.ClipOffsets) > 0 {
+		m.researchComplete[researchID] = &completeRow{
+			researchID:        researchID,
+			episodeID:         episodeID,
+			clipID:            clipID,
+			episodeDurationNs: completedResearchItem.EpisodeDuration,
+			clipDurationNs:    completedResearchItem.ClipDuration,
+			researchDate:      asDatetime(completedResearchItem.ResearchDate),
+		}
+		offsets := make([]int64, len(completedResearchItem.ClipOffsets))
+		copy(offsets, completedResearchItem.ClipOffsets)
+		m.episodeClipOffsets[researchID] = offsets
+	}
+
+	return nil
+}
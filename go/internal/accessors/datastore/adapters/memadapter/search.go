@@ -0,0 +1,106 @@
+package memadapter
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/search"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+// SearchEpisodes returns a page of the curated episodes that match the query,
+// ranked by how well they match (see the search package).
+func (m *MemoryDb) SearchEpisodes(query *datastore.SearchQuery) ([]*contracts.EpisodeInfo, error) {
+	if err := query.Page.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matcher := search.NewMatcher(query.Text)
+	rows := []*episodeRow{}
+	scores := map[*episodeRow]float64{}
+	for _, row := range m.curatedEpisodes {
+		if query.Curator != "" && row.curatorInfo != query.Curator {
+			continue
+		}
+		if !query.AiredFrom.IsZero() && row.dateAired.Before(query.AiredFrom) {
+			continue
+		}
+		if !query.AiredTo.IsZero() && row.dateAired.After(query.AiredTo) {
+			continue
+		}
+		if !matcher.Matches(row.title, row.description) {
+			continue
+		}
+		scores[row] = matcher.Score(row.title, row.description)
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		if !a.dateAired.Equal(b.dateAired) {
+			return a.dateAired.After(b.dateAired)
+		}
+		return a.episodeID < b.episodeID
+	})
+
+	start, end := pageBounds(len(rows), query.Page)
+	episodes := make([]*contracts.EpisodeInfo, 0, end-start)
+	for _, row := range rows[start:end] {
+		episodes = append(episodes, row.toEpisodeInfo())
+	}
+	return episodes, nil
+}
+
+// SearchClips returns a page of the curated clips that match the query,
+// ranked by how well they match (see the search package). Clips have no air
+// date, so an error is returned if the query has an air date range.
+func (m *MemoryDb) SearchClips(query *datastore.SearchQuery) ([]*contracts.ClipInfo, error) {
+	if err := query.Page.Validate(); err != nil {
+		return nil, err
+	}
+	if query.HasAirDateRange() {
+		return nil, errors.New("clips cannot be filtered by air date")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matcher := search.NewMatcher(query.Text)
+	rows := []*clipRow{}
+	scores := map[*clipRow]float64{}
+	for _, row := range m.curatedClips {
+		if query.Curator != "" && row.curatorInfo != query.Curator {
+			continue
+		}
+		if !matcher.Matches(row.title, row.description) {
+			continue
+		}
+		scores[row] = matcher.Score(row.title, row.description)
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		if a.title != b.title {
+			return a.title < b.title
+		}
+		return a.clipID < b.clipID
+	})
+
+	start, end := pageBounds(len(rows), query.Page)
+	clips := make([]*contracts.ClipInfo, 0, end-start)
+	for _, row := range rows[start:end] {
+		clips = append(clips, row.toClipInfo())
+	}
+	return clips, nil
+}
@@ -0,0 +1,43 @@
+package postgresadapter
+
+import (
+	"net/url"
+	"time"
+)
+
+// Config is a configuration for a postgres instance.
+type Config struct {
+	Addr     string
+	DBName   string
+	User     string
+	Password string
+
+	// SSLMode is passed to the driver as the sslmode parameter. If empty,
+	// "disable" is used.
+	SSLMode string
+
+	MaxConnectionLifetime time.Duration
+	MaxOpenConnections    int
+	MaxIdleConnections    int
+}
+
+func (c *Config) formatDSN() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		Host:     c.Addr,
+		Path:     "/" + c.DBName,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	if c.Password != "" {
+		dsn.User = url.UserPassword(c.User, c.Password)
+	} else {
+		dsn.User = url.User(c.User)
+	}
+
+	return dsn.String()
+}
@@ -0,0 +1,77 @@
+package postgresadapter
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/internal/sqlstore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/migrations"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+var dialect = &migrations.Dialect{
+	CreateHistoryTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description VARCHAR(200) NOT NULL,
+			applied_on TIMESTAMPTZ NOT NULL
+		)
+	`,
+	HistoryTableExists: `SELECT to_regclass('schema_migrations') IS NOT NULL`,
+	SelectVersion:      `SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1`,
+	InsertVersion:      `INSERT INTO schema_migrations (version, description, applied_on) VALUES ($1, $2, $3)`,
+
+	// The advisory lock key is arbitrary, but must be the same for every
+	// migrator. pg_advisory_lock returns void once the lock is held, so it's
+	// selected from to produce the 1 that the Migrator expects.
+	AcquireLock: `SELECT 1 FROM pg_advisory_lock(7253418)`,
+	ReleaseLock: `SELECT pg_advisory_unlock(7253418)`,
+
+	// Databases that were bootstrapped before migrations were embedded had
+	// the bootstrap schema applied by hand, so we adopt it if it's present.
+	BaselineVersion: func(ctx context.Context, conn *sql.Conn) (int, error) {
+		var bootstrapped bool
+		err := conn.QueryRowContext(ctx, `SELECT to_regclass('curated_clips') IS NOT NULL`).Scan(&bootstrapped)
+		if err != nil || !bootstrapped {
+			return -1, err
+		}
+		return 0, nil
+	},
+}
+
+func newMigrator(db *sql.DB) (*migrations.Migrator, error) {
+	migrationsFS, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrations.New(db, dialect, migrationsFS)
+}
+
+// Migrate applies any embedded schema migrations that have not yet been
+// applied to the database. It is safe to call Migrate concurrently from
+// multiple hosts. Once the schema is up to date, any curated episodes and clips
+// that predate the storage of search tokens are indexed.
+func (p *Postgres) Migrate(ctx context.Context) error {
+	db, err := sql.Open("postgres", p.config.formatDSN())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+	err = migrator.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Episodes and clips curated before search tokens were stored are indexed
+	// once the columns exist.
+	return sqlstore.IndexSearchTokens(ctx, db, sqlstore.Postgres)
+}
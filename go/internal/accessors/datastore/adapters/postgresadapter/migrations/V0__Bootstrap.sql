@@ -0,0 +1,72 @@
+-- Schema for the postgresadapter. The layout mirrors the mariadb schema (see
+-- the mariadbadapter's migrations), except that clip offsets are stored as an
+-- array on research_complete rather than in a separate table.
+
+CREATE TABLE curated_clips (
+  clip_id SERIAL PRIMARY KEY,
+  initial_date_curated TIMESTAMPTZ NOT NULL,
+  last_date_curated TIMESTAMPTZ NOT NULL,
+  curator_info VARCHAR(50) NOT NULL,
+  title VARCHAR(250) NOT NULL,
+  description TEXT NOT NULL,
+  media_uri VARCHAR(2048) NOT NULL,
+  media_type VARCHAR(3) NOT NULL,
+  priority INTEGER NOT NULL,
+  CONSTRAINT curated_clips_title_unique UNIQUE (title),
+  CONSTRAINT curated_clips_media_uri_unique UNIQUE (media_uri)
+);
+
+CREATE TABLE curated_episodes (
+  episode_id SERIAL PRIMARY KEY,
+  initial_date_curated TIMESTAMPTZ NOT NULL,
+  last_date_curated TIMESTAMPTZ NOT NULL,
+  curator_info VARCHAR(50) NOT NULL,
+  date_aired TIMESTAMPTZ NOT NULL,
+  title VARCHAR(250) NOT NULL,
+  description TEXT NOT NULL,
+  media_uri VARCHAR(2048) NOT NULL,
+  media_type VARCHAR(3) NOT NULL,
+  priority INTEGER NOT NULL,
+  CONSTRAINT curated_episodes_date_aired_title_unique UNIQUE (date_aired, title),
+  CONSTRAINT curated_episodes_media_uri_unique UNIQUE (media_uri)
+);
+
+CREATE TABLE research_backlog (
+  research_id SERIAL PRIMARY KEY,
+  episode_id INTEGER NOT NULL,
+  clip_id INTEGER NOT NULL,
+  CONSTRAINT research_backlog_episode_id_clip_id_unique UNIQUE (episode_id, clip_id)
+);
+
+CREATE TABLE research_leases (
+  lease_id UUID NOT NULL,
+  research_id INTEGER NOT NULL,
+  expiration TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (lease_id, research_id),
+  CONSTRAINT research_leases_research_id_unique UNIQUE (research_id)
+);
+
+CREATE TABLE research_complete (
+  research_id INTEGER PRIMARY KEY,
+  episode_id INTEGER NOT NULL,
+  clip_id INTEGER NOT NULL,
+  episode_duration_ns BIGINT NOT NULL,
+  clip_duration_ns BIGINT NOT NULL,
+  research_date TIMESTAMPTZ NOT NULL,
+  clip_offsets_ns BIGINT[] NOT NULL,
+  CONSTRAINT research_complete_episode_id_clip_id_unique UNIQUE (episode_id, clip_id)
+);
+
+CREATE TABLE episode_hashes (
+  episode_id INTEGER PRIMARY KEY,
+  hash VARCHAR(32) NOT NULL
+);
+
+CREATE INDEX episode_hash_idx ON episode_hashes(hash);
+
+CREATE TABLE clip_hashes (
+  clip_id INTEGER PRIMARY KEY,
+  hash VARCHAR(32) NOT NULL
+);
+
+CREATE INDEX clip_hash_idx ON clip_hashes(hash);
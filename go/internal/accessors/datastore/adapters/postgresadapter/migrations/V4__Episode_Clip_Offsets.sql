@@ -0,0 +1,14 @@
+-- Clip offsets are moved from the array on research_complete into their own
+-- table, as they are stored by the other sql adapters, so that every adapter
+-- can share the same queries.
+CREATE TABLE episode_clip_offsets (
+  research_id INTEGER NOT NULL,
+  offset_ns BIGINT NOT NULL,
+  PRIMARY KEY (research_id, offset_ns)
+);
+
+INSERT INTO episode_clip_offsets (research_id, offset_ns)
+  SELECT DISTINCT research_id, UNNEST(clip_offsets_ns)
+  FROM research_complete;
+
+ALTER TABLE research_complete DROP COLUMN clip_offsets_ns;
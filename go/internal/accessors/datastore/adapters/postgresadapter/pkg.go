@@ -0,0 +1,62 @@
+package postgresadapter
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/internal/sqlstore"
+
+	// Registers the postgres driver.
+	_ "github.com/lib/pq"
+)
+
+// Postgres is an adapter that plugs into a postgres instance.
+type Postgres struct {
+	config *Config
+}
+
+// PostgresConnection represents a successful connection to a postgres
+// instance. Its queries are shared with the other sql adapters (see the
+// sqlstore package).
+type PostgresConnection struct {
+	*sqlstore.Store
+	db *sql.DB
+}
+
+// New returns a reference to a new Postgres instance.
+func New(config *Config) *Postgres {
+	return &Postgres{
+		config: config,
+	}
+}
+
+// Connect attempts to open a connection to the underlaying postgres instance.
+// If the database's schema is not at the latest embedded migration, a
+// *migrations.OutOfDateError is returned, and the schema must be brought up
+// to date via Migrate.
+func (p *Postgres) Connect() (*PostgresConnection, error) {
+	db, err := sql.Open("postgres", p.config.formatDSN())
+	if err != nil {
+		return nil, err
+	}
+	db.SetConnMaxLifetime(p.config.MaxConnectionLifetime)
+	db.SetMaxOpenConns(p.config.MaxOpenConnections)
+	db.SetMaxIdleConns(p.config.MaxIdleConnections)
+
+	migrator, err := newMigrator(db)
+	if err == nil {
+		err = migrator.CheckVersion(context.Background())
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &PostgresConnection{
+		Store: sqlstore.NewStore(db, sqlstore.Postgres),
+		db:    db,
+	}, nil
+}
+
+var _ datastore.DataStorer = (*PostgresConnection)(nil)
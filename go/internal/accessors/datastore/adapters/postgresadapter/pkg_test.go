@@ -0,0 +1,95 @@
+package postgresadapter_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/postgresadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/datastoretest"
+)
+
+// The conformance suite requires a running postgres instance, so it only runs
+// when TBTLARCHIVIST_TEST_POSTGRES_ADDR is set (e.g. "127.0.0.1:5432"). The
+// embedded migrations are applied before the suite runs, and every table in
+// the test database is truncated before each test, so the database should be
+// dedicated to testing. The database
+// name defaults to "tbtlarchivist_test" and may be overridden with
+// TBTLARCHIVIST_TEST_POSTGRES_DBNAME. The user defaults to "postgres" and may
+// be overridden with TBTLARCHIVIST_TEST_POSTGRES_USER.
+func Test_DataStorerConformance(t *testing.T) {
+	addr := os.Getenv("TBTLARCHIVIST_TEST_POSTGRES_ADDR")
+	if addr == "" {
+		t.Skip("TBTLARCHIVIST_TEST_POSTGRES_ADDR is not set")
+	}
+
+	config := &postgresadapter.Config{
+		Addr:                  addr,
+		DBName:                getenvOrDefault("TBTLARCHIVIST_TEST_POSTGRES_DBNAME", "tbtlarchivist_test"),
+		User:                  getenvOrDefault("TBTLARCHIVIST_TEST_POSTGRES_USER", "postgres"),
+		Password:              os.Getenv("TBTLARCHIVIST_TEST_POSTGRES_PASSWORD"),
+		MaxConnectionLifetime: 60 * time.Second,
+		MaxOpenConnections:    5,
+		MaxIdleConnections:    5,
+	}
+
+	if err := postgresadapter.New(config).Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	datastoretest.RunDataStorerSuite(t, func(t *testing.T) datastore.DataStorer {
+		truncateAllTables(t, config)
+		db, err := postgresadapter.New(config).Connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	})
+}
+
+func getenvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func truncateAllTables(t *testing.T, config *postgresadapter.Config) {
+	t.Helper()
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.User, config.Password),
+		Host:     config.Addr,
+		Path:     "/" + config.DBName,
+		RawQuery: "sslmode=disable",
+	}
+	db, err := sql.Open("postgres", dsn.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	const truncateStmt = `
+		TRUNCATE TABLE
+			curated_clips,
+			curated_episodes,
+			research_backlog,
+			research_leases,
+			research_failures,
+			research_complete,
+			episode_clip_offsets,
+			episode_hashes,
+			clip_hashes,
+			episode_failures,
+			clip_failures
+		RESTART IDENTITY;
+	`
+	if _, err := db.Exec(truncateStmt); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,43 @@
+package sqliteadapter
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Config is a configuration for a sqlite database.
+type Config struct {
+	// Path is the location of the database file. The file is created if it
+	// does not already exist. If Path is ":memory:", the database only exists
+	// for as long as the connection remains open.
+	Path string
+
+	// BusyTimeout is how long a statement waits for a lock held by another
+	// process before failing. If zero, a default of five seconds is used.
+	BusyTimeout time.Duration
+}
+
+const defaultBusyTimeout = 5 * time.Second
+
+func (c *Config) formatDSN() string {
+	busyTimeout := c.BusyTimeout
+	if busyTimeout == 0 {
+		busyTimeout = defaultBusyTimeout
+	}
+
+	params := url.Values{}
+	params.Set("_busy_timeout", fmt.Sprint(busyTimeout.Milliseconds()))
+	params.Set("_foreign_keys", "on")
+
+	// Transactions acquire a write lock immediately. Otherwise two
+	// connections that both read before writing can deadlock.
+	params.Set("_txlock", "immediate")
+
+	if c.Path == ":memory:" {
+		return fmt.Sprintf("file::memory:?%v", params.Encode())
+	}
+
+	params.Set("_journal_mode", "WAL")
+	return fmt.Sprintf("file:%v?%v", c.Path, params.Encode())
+}
@@ -0,0 +1,51 @@
+package sqliteadapter
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/internal/sqlstore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/migrations"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// No lock is required. Each migration is applied within a transaction that is
+// opened with an immediate (exclusive write) lock, and the Migrator re-reads
+// the schema version within that transaction, so a migration that another
+// process applied concurrently isn't applied again.
+var dialect = &migrations.Dialect{
+	CreateHistoryTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description VARCHAR(200) NOT NULL,
+			applied_on DATETIME NOT NULL
+		)
+	`,
+	HistoryTableExists: `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+	SelectVersion:      `SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1`,
+	InsertVersion:      `INSERT INTO schema_migrations (version, description, applied_on) VALUES (?, ?, ?)`,
+}
+
+func migrate(ctx context.Context, db *sql.DB) error {
+	migrationsFS, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return err
+	}
+
+	migrator, err := migrations.New(db, dialect, migrationsFS)
+	if err != nil {
+		return err
+	}
+	err = migrator.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Episodes and clips curated before search tokens were stored are indexed
+	// once the columns exist.
+	return sqlstore.IndexSearchTokens(ctx, db, sqlstore.SQLite)
+}
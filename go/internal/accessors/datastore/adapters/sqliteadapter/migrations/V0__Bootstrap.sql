@@ -0,0 +1,77 @@
+-- This schema mirrors the mariadb schema (see the mariadbadapter's
+-- migrations). Statements are idempotent so that databases created before
+-- migrations were tracked can be adopted.
+
+CREATE TABLE IF NOT EXISTS curated_clips (
+  clip_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  initial_date_curated DATETIME NOT NULL,
+  last_date_curated DATETIME NOT NULL,
+  curator_info VARCHAR(50) NOT NULL,
+  title VARCHAR(250) NOT NULL,
+  description TEXT NOT NULL,
+  media_uri VARCHAR(2048) NOT NULL,
+  media_type VARCHAR(3) NOT NULL,
+  priority INTEGER NOT NULL,
+  CONSTRAINT title_UNIQUE UNIQUE (title),
+  CONSTRAINT media_uri_UNIQUE UNIQUE (media_uri)
+);
+
+CREATE TABLE IF NOT EXISTS curated_episodes (
+  episode_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  initial_date_curated DATETIME NOT NULL,
+  last_date_curated DATETIME NOT NULL,
+  curator_info VARCHAR(50) NOT NULL,
+  date_aired DATETIME NOT NULL,
+  title VARCHAR(250),
+  description TEXT NOT NULL,
+  media_uri VARCHAR(2048) NOT NULL,
+  media_type VARCHAR(3) NOT NULL,
+  priority INTEGER NOT NULL,
+  CONSTRAINT date_aired_title_UNIQUE UNIQUE (date_aired, title),
+  CONSTRAINT media_uri_UNIQUE UNIQUE (media_uri)
+);
+
+CREATE TABLE IF NOT EXISTS research_backlog (
+  research_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  episode_id INTEGER NOT NULL,
+  clip_id INTEGER NOT NULL,
+  CONSTRAINT episode_id_clip_id_UNIQUE UNIQUE (episode_id, clip_id)
+);
+
+CREATE TABLE IF NOT EXISTS research_leases (
+  lease_id CHAR(36) NOT NULL,
+  research_id INTEGER NOT NULL,
+  expiration DATETIME NOT NULL,
+  PRIMARY KEY (lease_id, research_id),
+  CONSTRAINT research_id_UNIQUE UNIQUE (research_id)
+);
+
+CREATE TABLE IF NOT EXISTS research_complete (
+  research_id INTEGER NOT NULL PRIMARY KEY,
+  episode_id INTEGER NOT NULL,
+  clip_id INTEGER NOT NULL,
+  episode_duration_ns BIGINT NOT NULL,
+  clip_duration_ns BIGINT NOT NULL,
+  research_date DATETIME NOT NULL,
+  CONSTRAINT episode_id_clip_id_UNIQUE UNIQUE (episode_id, clip_id)
+);
+
+CREATE TABLE IF NOT EXISTS episode_clip_offsets (
+  research_id INTEGER NOT NULL,
+  offset_ns BIGINT NOT NULL,
+  PRIMARY KEY (research_id, offset_ns)
+);
+
+CREATE TABLE IF NOT EXISTS episode_hashes (
+  episode_id INTEGER NOT NULL PRIMARY KEY,
+  hash VARCHAR(32) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS episode_hash_idx ON episode_hashes(hash);
+
+CREATE TABLE IF NOT EXISTS clip_hashes (
+  clip_id INTEGER NOT NULL PRIMARY KEY,
+  hash VARCHAR(32) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS clip_hash_idx ON clip_hashes(hash);
@@ -0,0 +1,10 @@
+-- Failed attempts to research each episode/clip pair. A pair that has failed
+-- permanently too many times is no longer offered for research.
+CREATE TABLE research_failures (
+  research_id INTEGER NOT NULL PRIMARY KEY,
+  attempts INTEGER NOT NULL,
+  permanent_attempts INTEGER NOT NULL,
+  failure_class VARCHAR(16) NOT NULL,
+  message TEXT NOT NULL,
+  last_failure_date DATETIME NOT NULL
+);
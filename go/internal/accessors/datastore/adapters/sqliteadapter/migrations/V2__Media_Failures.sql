@@ -0,0 +1,14 @@
+-- Permanent failures attributed to an episode or clip itself, rather than to
+-- an episode/clip pair. An episode or clip that has failed permanently too many
+-- times is no longer offered for research with any clip or episode.
+CREATE TABLE episode_failures (
+  episode_id INTEGER NOT NULL PRIMARY KEY,
+  permanent_attempts INTEGER NOT NULL,
+  last_failure_date DATETIME NOT NULL
+);
+
+CREATE TABLE clip_failures (
+  clip_id INTEGER NOT NULL PRIMARY KEY,
+  permanent_attempts INTEGER NOT NULL,
+  last_failure_date DATETIME NOT NULL
+);
@@ -0,0 +1,66 @@
+package sqliteadapter
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/internal/sqlstore"
+
+	// Registers the sqlite3 driver.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLite is an adapter that plugs into an embedded sqlite database.
+type SQLite struct {
+	config *Config
+}
+
+// SQLiteConnection represents a successful connection to a sqlite database.
+// Its queries are shared with the other sql adapters (see the sqlstore
+// package).
+type SQLiteConnection struct {
+	*sqlstore.Store
+	db *sql.DB
+}
+
+// New returns a reference to a new SQLite instance.
+func New(config *Config) *SQLite {
+	return &SQLite{
+		config: config,
+	}
+}
+
+// Connect opens the underlaying sqlite database, creating it if necessary,
+// and applies any embedded schema migrations that have not yet been applied.
+// Since the database is private to the host, there is no need to migrate it
+// separately.
+func (s *SQLite) Connect() (*SQLiteConnection, error) {
+	db, err := sql.Open("sqlite3", s.config.formatDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite only permits a single writer at a time. Limiting the pool to a
+	// single connection serializes access within this process, and is
+	// required for in-memory databases, which are private to a connection.
+	db.SetMaxOpenConns(1)
+
+	err = migrate(context.Background(), db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteConnection{
+		Store: sqlstore.NewStore(db, sqlstore.SQLite),
+		db:    db,
+	}, nil
+}
+
+// Close closes the underlaying database.
+func (s *SQLiteConnection) Close() error {
+	return s.db.Close()
+}
+
+var _ datastore.DataStorer = (*SQLiteConnection)(nil)
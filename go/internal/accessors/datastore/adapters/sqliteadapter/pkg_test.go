@@ -0,0 +1,88 @@
+package sqliteadapter_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/sqliteadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/datastoretest"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_DataStorerConformance(t *testing.T) {
+	datastoretest.RunDataStorerSuite(t, func(t *testing.T) datastore.DataStorer {
+		config := &sqliteadapter.Config{
+			Path: filepath.Join(t.TempDir(), "tbtlarchivist.db"),
+		}
+		db, err := sqliteadapter.New(config).Connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	})
+}
+
+func Test_ConnectIsIdempotent(t *testing.T) {
+	config := &sqliteadapter.Config{
+		Path: filepath.Join(t.TempDir(), "tbtlarchivist.db"),
+	}
+	for i := 0; i < 2; i++ {
+		db, err := sqliteadapter.New(config).Connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.Close()
+	}
+}
+
+func Test_ConnectIndexesSearchTokens(t *testing.T) {
+	config := &sqliteadapter.Config{
+		Path: filepath.Join(t.TempDir(), "tbtlarchivist.db"),
+	}
+	db, err := sqliteadapter.New(config).Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := timestamppb.Now()
+	err = db.UpsertClipInfo(&contracts.ClipInfo{
+		InitialDateCurated: now,
+		LastDateCurated:    now,
+		Title:              "CatchOneInTheMiddle.mp3",
+		MediaUri:           "https://example.com/clip.mp3",
+	})
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a clip that was curated before search tokens were stored.
+	rawDB, err := sql.Open("sqlite3", config.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = rawDB.Exec("UPDATE curated_clips SET title_tokens = NULL, description_tokens = NULL")
+	rawDB.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = sqliteadapter.New(config).Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	clips, err := db.SearchClips(&datastore.SearchQuery{
+		Text: "the middle",
+		Page: datastore.Page{Limit: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clips) != 1 {
+		t.Fatalf("expected the clip to be indexed when connecting, got %v clips", len(clips))
+	}
+}
@@ -1,6 +1,8 @@
 package datastore
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,8 +24,102 @@ type DataStorer interface {
 	CreateResearchLease(*uuid.UUID, *contracts.EpisodeInfo, []*contracts.ClipInfo, time.Time) error
 	RenewResearchLease(uuid.UUID, time.Time) error
 	RevokeResearchLease(uuid.UUID) error
+	ReapExpiredLeases(now time.Time) (int, error)
 
+	// RecordResearchFailure counts a failed attempt to research each of the
+	// failure's episode/clip pairs, and releases the pairs from the failure's
+	// lease so that they may be researched again. Pairs that have failed
+	// permanently PermanentFailureLimit times are no longer offered for
+	// research. Permanent failures that the failure attributes to its episode
+	// or clips are also counted against that media, which is no longer offered
+	// for research with any clip or episode once it reaches the limit.
+	RecordResearchFailure(*contracts.ResearchFailure) error
+
+	// GetHighestPriorityEpisode and GetHighestPriorityClipsForEpisode supply
+	// the MediaHash of any episode or clip that has been researched before.
 	GetHighestPriorityEpisode() (*contracts.EpisodeInfo, error)
 	GetHighestPriorityClipsForEpisode(episode *contracts.EpisodeInfo, limit int) ([]*contracts.ClipInfo, error)
 	RecordCompletedResearch(*contracts.CompletedResearchItem) error
+
+	FindClipAppearances(clip *contracts.ClipInfo, page Page) ([]*contracts.CompletedResearchItem, error)
+	FindEpisodeClips(episode *contracts.EpisodeInfo, page Page) ([]*contracts.CompletedResearchItem, error)
+
+	ListEpisodes(page Page) ([]*contracts.EpisodeInfo, error)
+	ListClips(page Page) ([]*contracts.ClipInfo, error)
+	GetResearchStatistics() (*ResearchStatistics, error)
+
+	SearchEpisodes(*SearchQuery) ([]*contracts.EpisodeInfo, error)
+	SearchClips(*SearchQuery) ([]*contracts.ClipInfo, error)
+}
+
+// PermanentFailureLimit is the number of times that research of an
+// episode/clip pair, or of an episode or clip itself, may fail permanently
+// before GetHighestPriorityEpisode and GetHighestPriorityClipsForEpisode stop
+// offering it for research.
+const PermanentFailureLimit = 3
+
+// A SearchQuery describes a search over curated episodes or clips. Text is
+// matched against titles and descriptions (see the search package for
+// details), and results are ranked by how well they match. If Text is empty,
+// every episode or clip that satisfies the filters is returned, in the same
+// order as ListEpisodes or ListClips.
+type SearchQuery struct {
+	Text string
+
+	// AiredFrom and AiredTo, if not zero, restrict episodes to those that
+	// aired within the range (inclusive). Clips have no air date, so these
+	// must be zero when searching clips.
+	AiredFrom time.Time
+	AiredTo   time.Time
+
+	// Curator, if not empty, restricts results to those with exactly
+	// matching CuratorInformation.
+	Curator string
+
+	Page Page
+}
+
+// HasAirDateRange returns true if either AiredFrom or AiredTo is set.
+func (q *SearchQuery) HasAirDateRange() bool {
+	return !q.AiredFrom.IsZero() || !q.AiredTo.IsZero()
+}
+
+// ErrNotFound is returned (possibly wrapped) by read methods when the
+// requested episode or clip does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ResearchStatistics summarizes the state of the archive.
+type ResearchStatistics struct {
+	// Episodes and Clips are the number of curated episodes and clips.
+	Episodes int
+	Clips    int
+
+	// BacklogItems is the number of episode/clip pairs that have not been
+	// researched, of which LeasedBacklogItems are currently leased to a
+	// researcher.
+	BacklogItems       int
+	LeasedBacklogItems int
+
+	// CompletedItems is the number of episode/clip pairs for which the clip
+	// was found within the episode.
+	CompletedItems int
+}
+
+// A Page selects a subset of the results of a query. Offset is the number of
+// results to skip, and Limit is the maximum number of results to return.
+type Page struct {
+	Offset int
+	Limit  int
+}
+
+// Validate returns an error if the page's offset is negative or its limit is
+// not positive.
+func (p Page) Validate() error {
+	if p.Offset < 0 {
+		return fmt.Errorf("page offset must not be negative, got %v", p.Offset)
+	}
+	if p.Limit <= 0 {
+		return fmt.Errorf("page limit must be positive, got %v", p.Limit)
+	}
+	return nil
 }
@@ -0,0 +1,1044 @@
+// Package datastoretest provides a conformance suite that can be run against
+// any implementation of datastore.DataStorer. Each adapter should invoke
+// RunDataStorerSuite from its own tests so that all adapters are held to the
+// same behavior.
+package datastoretest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// A Factory returns a new, empty DataStorer. The factory is called once for
+// each test in the suite. Any cleanup the DataStorer requires should be
+// registered via t.Cleanup.
+type Factory func(t *testing.T) datastore.DataStorer
+
+// baseTime is truncated to the second, since that is the precision with which
+// the sql adapters persist timestamps.
+var baseTime = time.Date(2021, time.April, 9, 12, 0, 0, 0, time.UTC)
+
+// RunDataStorerSuite runs every conformance test against DataStorers produced
+// by newDataStorer.
+func RunDataStorerSuite(t *testing.T, newDataStorer Factory) {
+	tests := []struct {
+		name string
+		test func(*testing.T, datastore.DataStorer)
+	}{
+		{"UpsertClipInfoPreservesInitialDateCurated", testUpsertClipInfoPreservesInitialDateCurated},
+		{"UpsertEpisodeInfoPreservesInitialDateCurated", testUpsertEpisodeInfoPreservesInitialDateCurated},
+		{"UpsertRejectsLastDateCuratedBeforeInitialDateCurated", testUpsertRejectsLastDateCuratedBeforeInitialDateCurated},
+		{"BacklogContainsEveryEpisodeClipPair", testBacklogContainsEveryEpisodeClipPair},
+		{"EmptyDataStoreHasNoWork", testEmptyDataStoreHasNoWork},
+		{"CreateResearchLeaseExcludesLeasedItems", testCreateResearchLeaseExcludesLeasedItems},
+		{"CreateResearchLeaseIsAtomic", testCreateResearchLeaseIsAtomic},
+		{"CreateResearchLeasePanicsWithoutClips", testCreateResearchLeasePanicsWithoutClips},
+		{"RenewResearchLease", testRenewResearchLease},
+		{"RevokeResearchLease", testRevokeResearchLease},
+		{"ReapExpiredLeases", testReapExpiredLeases},
+		{"EpisodePriorityOrdering", testEpisodePriorityOrdering},
+		{"ClipPriorityOrdering", testClipPriorityOrdering},
+		{"RecordCompletedResearchRemovesBacklogItem", testRecordCompletedResearchRemovesBacklogItem},
+		{"RecordCompletedResearchRequiresBacklogItem", testRecordCompletedResearchRequiresBacklogItem},
+		{"RecordCompletedResearchInsertsHashesOnce", testRecordCompletedResearchInsertsHashesOnce},
+		{"RecordCompletedResearchAcceptsNonMatches", testRecordCompletedResearchAcceptsNonMatches},
+		{"HighestPriorityItemsIncludeMediaHashes", testHighestPriorityItemsIncludeMediaHashes},
+		{"RecordResearchFailureReleasesLease", testRecordResearchFailureReleasesLease},
+		{"RecordResearchFailureExcludesPermanentFailures", testRecordResearchFailureExcludesPermanentFailures},
+		{"RecordResearchFailureExcludesFailedEpisodes", testRecordResearchFailureExcludesFailedEpisodes},
+		{"RecordResearchFailureExcludesFailedClips", testRecordResearchFailureExcludesFailedClips},
+		{"RecordResearchFailureRequiresKnownItems", testRecordResearchFailureRequiresKnownItems},
+		{"FindClipAppearances", testFindClipAppearances},
+		{"FindEpisodeClips", testFindEpisodeClips},
+		{"FindRejectsUnknownItems", testFindRejectsUnknownItems},
+		{"FindRejectsInvalidPages", testFindRejectsInvalidPages},
+		{"ListEpisodes", testListEpisodes},
+		{"ListClips", testListClips},
+		{"GetResearchStatistics", testGetResearchStatistics},
+		{"SearchClipsRanksMatches", testSearchClipsRanksMatches},
+		{"SearchClipsFiltersByCurator", testSearchClipsFiltersByCurator},
+		{"SearchClipsRejectsAirDateRange", testSearchClipsRejectsAirDateRange},
+		{"SearchEpisodesFiltersByAirDate", testSearchEpisodesFiltersByAirDate},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, newDataStorer(t))
+		})
+	}
+}
+
+func newEpisode(n int) *contracts.EpisodeInfo {
+	curated := timestamppb.New(baseTime)
+	return &contracts.EpisodeInfo{
+		InitialDateCurated: curated,
+		LastDateCurated:    curated,
+		CuratorInformation: "datastoretest",
+		DateAired:          timestamppb.New(baseTime.AddDate(0, 0, -n)),
+		Title:              fmt.Sprintf("episode %v", n),
+		Description:        fmt.Sprintf("description of episode %v", n),
+		MediaUri:           fmt.Sprintf("https://example.com/episodes/%v.mp3", n),
+		MediaType:          "mp3",
+	}
+}
+
+func newClip(n int) *contracts.ClipInfo {
+	curated := timestamppb.New(baseTime)
+	return &contracts.ClipInfo{
+		InitialDateCurated: curated,
+		LastDateCurated:    curated,
+		CuratorInformation: "datastoretest",
+		Title:              fmt.Sprintf("clip %v", n),
+		Description:        fmt.Sprintf("description of clip %v", n),
+		MediaUri:           fmt.Sprintf("https://example.com/clips/%v.mp3", n),
+		MediaType:          "mp3",
+	}
+}
+
+func newCompletedResearchItem(episode *contracts.EpisodeInfo, clip *contracts.ClipInfo, offsets ...int64) *contracts.CompletedResearchItem {
+	return &contracts.CompletedResearchItem{
+		ResearchDate:    timestamppb.New(baseTime),
+		EpisodeInfo:     episode,
+		ClipInfo:        clip,
+		EpisodeDuration: int64(time.Hour),
+		EpisodeHash:     "episode hash",
+		ClipDuration:    int64(10 * time.Second),
+		ClipHash:        "clip hash",
+		ClipOffsets:     offsets,
+	}
+}
+
+func mustUpsertEpisodes(t *testing.T, db datastore.DataStorer, episodes ...*contracts.EpisodeInfo) {
+	t.Helper()
+	for _, episode := range episodes {
+		if err := db.UpsertEpisodeInfo(episode); err != nil {
+			t.Fatalf("UpsertEpisodeInfo(%v): %v", episode.Title, err)
+		}
+	}
+}
+
+func mustUpsertClips(t *testing.T, db datastore.DataStorer, clips ...*contracts.ClipInfo) {
+	t.Helper()
+	for _, clip := range clips {
+		if err := db.UpsertClipInfo(clip); err != nil {
+			t.Fatalf("UpsertClipInfo(%v): %v", clip.Title, err)
+		}
+	}
+}
+
+func mustCreateLease(t *testing.T, db datastore.DataStorer, episode *contracts.EpisodeInfo, clips ...*contracts.ClipInfo) uuid.UUID {
+	t.Helper()
+	leaseID := uuid.New()
+	err := db.CreateResearchLease(&leaseID, episode, clips, baseTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("CreateResearchLease: %v", err)
+	}
+	return leaseID
+}
+
+func mustGetHighestPriorityEpisode(t *testing.T, db datastore.DataStorer) *contracts.EpisodeInfo {
+	t.Helper()
+	episode, err := db.GetHighestPriorityEpisode()
+	if err != nil {
+		t.Fatalf("GetHighestPriorityEpisode: %v", err)
+	}
+	return episode
+}
+
+func mustGetClips(t *testing.T, db datastore.DataStorer, episode *contracts.EpisodeInfo, limit int) []*contracts.ClipInfo {
+	t.Helper()
+	clips, err := db.GetHighestPriorityClipsForEpisode(episode, limit)
+	if err != nil {
+		t.Fatalf("GetHighestPriorityClipsForEpisode: %v", err)
+	}
+	return clips
+}
+
+func assertEpisodeTitle(t *testing.T, got *contracts.EpisodeInfo, want string) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("expected episode %q, got nil", want)
+	}
+	if got.Title != want {
+		t.Fatalf("expected episode %q, got %q", want, got.Title)
+	}
+}
+
+func assertClipTitles(t *testing.T, got []*contracts.ClipInfo, want ...string) {
+	t.Helper()
+	gotTitles := make([]string, 0, len(got))
+	for _, clip := range got {
+		gotTitles = append(gotTitles, clip.Title)
+	}
+	if len(gotTitles) != len(want) {
+		t.Fatalf("expected clips %q, got %q", want, gotTitles)
+	}
+	for i := range want {
+		if gotTitles[i] != want[i] {
+			t.Fatalf("expected clips %q, got %q", want, gotTitles)
+		}
+	}
+}
+
+func mustRecordCompletedResearch(t *testing.T, db datastore.DataStorer, items ...*contracts.CompletedResearchItem) {
+	t.Helper()
+	for _, item := range items {
+		if err := db.RecordCompletedResearch(item); err != nil {
+			t.Fatalf("RecordCompletedResearch(%v, %v): %v", item.EpisodeInfo.Title, item.ClipInfo.Title, err)
+		}
+	}
+}
+
+func mustFindClipAppearances(t *testing.T, db datastore.DataStorer, clip *contracts.ClipInfo, page datastore.Page) []*contracts.CompletedResearchItem {
+	t.Helper()
+	items, err := db.FindClipAppearances(clip, page)
+	if err != nil {
+		t.Fatalf("FindClipAppearances: %v", err)
+	}
+	return items
+}
+
+func mustFindEpisodeClips(t *testing.T, db datastore.DataStorer, episode *contracts.EpisodeInfo, page datastore.Page) []*contracts.CompletedResearchItem {
+	t.Helper()
+	items, err := db.FindEpisodeClips(episode, page)
+	if err != nil {
+		t.Fatalf("FindEpisodeClips: %v", err)
+	}
+	return items
+}
+
+// assertCompletedResearch compares the titles and offsets of each item.
+func assertCompletedResearch(t *testing.T, got []*contracts.CompletedResearchItem, want ...*contracts.CompletedResearchItem) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v items, got %v", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].EpisodeInfo.Title != want[i].EpisodeInfo.Title || got[i].ClipInfo.Title != want[i].ClipInfo.Title {
+			t.Fatalf("expected item %v to be (%v, %v), got (%v, %v)", i,
+				want[i].EpisodeInfo.Title, want[i].ClipInfo.Title,
+				got[i].EpisodeInfo.Title, got[i].ClipInfo.Title)
+		}
+		if fmt.Sprint(got[i].ClipOffsets) != fmt.Sprint(want[i].ClipOffsets) {
+			t.Fatalf("expected item %v to have offsets %v, got %v", i, want[i].ClipOffsets, got[i].ClipOffsets)
+		}
+	}
+}
+
+func assertTimestamp(t *testing.T, field string, got *timestamppb.Timestamp, want time.Time) {
+	t.Helper()
+	if !got.AsTime().Equal(want) {
+		t.Fatalf("expected %v to be %v, got %v", field, want, got.AsTime())
+	}
+}
+
+func testUpsertClipInfoPreservesInitialDateCurated(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clip := newClip(1)
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clip)
+
+	updated := newClip(1)
+	updated.InitialDateCurated = timestamppb.New(baseTime.Add(-48 * time.Hour))
+	updated.LastDateCurated = timestamppb.New(baseTime.Add(24 * time.Hour))
+	updated.Description = "updated description"
+	mustUpsertClips(t, db, updated)
+
+	clips := mustGetClips(t, db, episode, 10)
+	assertClipTitles(t, clips, clip.Title)
+	assertTimestamp(t, "InitialDateCurated", clips[0].InitialDateCurated, baseTime)
+	assertTimestamp(t, "LastDateCurated", clips[0].LastDateCurated, baseTime.Add(24*time.Hour))
+	if clips[0].Description != updated.Description {
+		t.Fatalf("expected description to be updated to %q, got %q", updated.Description, clips[0].Description)
+	}
+}
+
+func testUpsertEpisodeInfoPreservesInitialDateCurated(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, newClip(1))
+
+	updated := newEpisode(1)
+	updated.InitialDateCurated = timestamppb.New(baseTime.Add(-48 * time.Hour))
+	updated.LastDateCurated = timestamppb.New(baseTime.Add(24 * time.Hour))
+	updated.Description = "updated description"
+	mustUpsertEpisodes(t, db, updated)
+
+	got := mustGetHighestPriorityEpisode(t, db)
+	assertEpisodeTitle(t, got, episode.Title)
+	assertTimestamp(t, "InitialDateCurated", got.InitialDateCurated, baseTime)
+	assertTimestamp(t, "LastDateCurated", got.LastDateCurated, baseTime.Add(24*time.Hour))
+	assertTimestamp(t, "DateAired", got.DateAired, episode.DateAired.AsTime())
+	if got.Description != updated.Description {
+		t.Fatalf("expected description to be updated to %q, got %q", updated.Description, got.Description)
+	}
+}
+
+func testUpsertRejectsLastDateCuratedBeforeInitialDateCurated(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	episode.LastDateCurated = timestamppb.New(baseTime.Add(-time.Hour))
+	if err := db.UpsertEpisodeInfo(episode); err == nil {
+		t.Fatal("expected an error inserting an episode whose LastDateCurated is before its InitialDateCurated")
+	}
+
+	clip := newClip(1)
+	clip.LastDateCurated = timestamppb.New(baseTime.Add(-time.Hour))
+	if err := db.UpsertClipInfo(clip); err == nil {
+		t.Fatal("expected an error inserting a clip whose LastDateCurated is before its InitialDateCurated")
+	}
+
+	// Neither item should have been inserted, so pairing them with valid
+	// items must not produce any backlog.
+	validEpisode := newEpisode(2)
+	mustUpsertEpisodes(t, db, validEpisode)
+	mustUpsertClips(t, db, newClip(2))
+
+	if got := mustGetHighestPriorityEpisode(t, db); got == nil || got.Title != validEpisode.Title {
+		t.Fatalf("expected only %q to be available for research, got %v", validEpisode.Title, got)
+	}
+	assertClipTitles(t, mustGetClips(t, db, validEpisode, 10), "clip 2")
+}
+
+func testBacklogContainsEveryEpisodeClipPair(t *testing.T, db datastore.DataStorer) {
+	// Interleave episode and clip inserts so that backlog fan-out is
+	// exercised from both directions.
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2), newEpisode(3)}
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2), newClip(3)}
+	mustUpsertClips(t, db, clips[0])
+	mustUpsertEpisodes(t, db, episodes[0], episodes[1])
+	mustUpsertClips(t, db, clips[1], clips[2])
+	mustUpsertEpisodes(t, db, episodes[2])
+
+	for _, episode := range episodes {
+		got := mustGetClips(t, db, episode, 10)
+		if len(got) != len(clips) {
+			t.Fatalf("expected %v backlog items for %q, got %v", len(clips), episode.Title, len(got))
+		}
+	}
+
+	// Updating an existing item must not create additional backlog.
+	mustUpsertEpisodes(t, db, newEpisode(1))
+	mustUpsertClips(t, db, newClip(1))
+	if got := mustGetClips(t, db, episodes[0], 10); len(got) != len(clips) {
+		t.Fatalf("expected %v backlog items after update, got %v", len(clips), len(got))
+	}
+}
+
+func testEmptyDataStoreHasNoWork(t *testing.T, db datastore.DataStorer) {
+	if got := mustGetHighestPriorityEpisode(t, db); got != nil {
+		t.Fatalf("expected no episode, got %v", got)
+	}
+
+	if _, err := db.GetHighestPriorityClipsForEpisode(newEpisode(1), 10); err == nil {
+		t.Fatal("expected an error requesting clips for an unknown episode")
+	}
+
+	// An episode without any clips has nothing to research.
+	episode := newEpisode(1)
+	mustUpsertEpisodes(t, db, episode)
+	if got := mustGetHighestPriorityEpisode(t, db); got != nil {
+		t.Fatalf("expected no episode, got %v", got)
+	}
+	if got := mustGetClips(t, db, episode, 10); got != nil {
+		t.Fatalf("expected nil clips, got %v", got)
+	}
+}
+
+func testCreateResearchLeaseExcludesLeasedItems(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2), newClip(3)}
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clips...)
+
+	mustCreateLease(t, db, episode, clips[0], clips[1])
+	assertClipTitles(t, mustGetClips(t, db, episode, 10), clips[2].Title)
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), episode.Title)
+
+	mustCreateLease(t, db, episode, clips[2])
+	if got := mustGetClips(t, db, episode, 10); got != nil {
+		t.Fatalf("expected no clips once all are leased, got %v", got)
+	}
+	if got := mustGetHighestPriorityEpisode(t, db); got != nil {
+		t.Fatalf("expected no episode once all clips are leased, got %v", got)
+	}
+}
+
+func testCreateResearchLeaseIsAtomic(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clips...)
+	mustCreateLease(t, db, episode, clips[0])
+
+	leaseID := uuid.New()
+	err := db.CreateResearchLease(&leaseID, episode, []*contracts.ClipInfo{clips[1], clips[0]}, baseTime.Add(time.Hour))
+	if err == nil {
+		t.Fatal("expected an error leasing an item that is already leased")
+	}
+
+	// The failed lease must not have leased clip 2.
+	assertClipTitles(t, mustGetClips(t, db, episode, 10), clips[1].Title)
+
+	err = db.CreateResearchLease(&leaseID, episode, []*contracts.ClipInfo{newClip(3)}, baseTime.Add(time.Hour))
+	if err == nil {
+		t.Fatal("expected an error leasing a clip that is not in the backlog")
+	}
+
+	err = db.CreateResearchLease(&leaseID, newEpisode(2), clips[1:], baseTime.Add(time.Hour))
+	if err == nil {
+		t.Fatal("expected an error leasing clips for an unknown episode")
+	}
+}
+
+func testCreateResearchLeasePanicsWithoutClips(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	mustUpsertEpisodes(t, db, episode)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected CreateResearchLease to panic when no clips are supplied")
+		}
+	}()
+	leaseID := uuid.New()
+	_ = db.CreateResearchLease(&leaseID, episode, nil, baseTime.Add(time.Hour))
+}
+
+func testRenewResearchLease(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clip := newClip(1)
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clip)
+	leaseID := mustCreateLease(t, db, episode, clip)
+
+	if err := db.RenewResearchLease(leaseID, baseTime.Add(2*time.Hour)); err != nil {
+		t.Fatalf("RenewResearchLease: %v", err)
+	}
+	if got := mustGetHighestPriorityEpisode(t, db); got != nil {
+		t.Fatalf("expected the episode to remain leased after renewal, got %v", got)
+	}
+
+	if err := db.RenewResearchLease(uuid.New(), baseTime.Add(2*time.Hour)); err != nil {
+		t.Fatalf("expected renewing an unknown lease to be a no-op, got %v", err)
+	}
+}
+
+func testRevokeResearchLease(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clips...)
+	leaseID := mustCreateLease(t, db, episode, clips...)
+
+	if err := db.RevokeResearchLease(leaseID); err != nil {
+		t.Fatalf("RevokeResearchLease: %v", err)
+	}
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), episode.Title)
+	if got := mustGetClips(t, db, episode, 10); len(got) != len(clips) {
+		t.Fatalf("expected %v clips after revoking the lease, got %v", len(clips), len(got))
+	}
+
+	if err := db.RevokeResearchLease(uuid.New()); err != nil {
+		t.Fatalf("expected revoking an unknown lease to be a no-op, got %v", err)
+	}
+}
+
+func testReapExpiredLeases(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2), newClip(3)}
+	clips[0].Priority = 2
+	clips[1].Priority = 1
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clips...)
+
+	// The first two clips expire an hour after baseTime, the third expires
+	// three hours after baseTime.
+	mustCreateLease(t, db, episode, clips[0], clips[1])
+	leaseID := uuid.New()
+	err := db.CreateResearchLease(&leaseID, episode, clips[2:], baseTime.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("CreateResearchLease: %v", err)
+	}
+
+	reclaimed, err := db.ReapExpiredLeases(baseTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ReapExpiredLeases: %v", err)
+	}
+	if reclaimed != 0 {
+		t.Fatalf("expected no leases to be reclaimed before any have expired, got %v", reclaimed)
+	}
+
+	reclaimed, err = db.ReapExpiredLeases(baseTime.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("ReapExpiredLeases: %v", err)
+	}
+	if reclaimed != 2 {
+		t.Fatalf("expected 2 leases to be reclaimed, got %v", reclaimed)
+	}
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), episode.Title)
+	assertClipTitles(t, mustGetClips(t, db, episode, 10), clips[0].Title, clips[1].Title)
+
+	reclaimed, err = db.ReapExpiredLeases(baseTime.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("ReapExpiredLeases: %v", err)
+	}
+	if reclaimed != 0 {
+		t.Fatalf("expected reaping to be idempotent, but %v leases were reclaimed", reclaimed)
+	}
+}
+
+func testEpisodePriorityOrdering(t *testing.T, db datastore.DataStorer) {
+	// Episodes are ordered by priority, then by date aired (newest first).
+	// newEpisode(n) airs n days before baseTime.
+	low := newEpisode(1)
+	highOld := newEpisode(3)
+	highOld.Priority = 5
+	highNew := newEpisode(2)
+	highNew.Priority = 5
+	mustUpsertEpisodes(t, db, low, highOld, highNew)
+	clip := newClip(1)
+	mustUpsertClips(t, db, clip)
+
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), highNew.Title)
+	mustCreateLease(t, db, highNew, clip)
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), highOld.Title)
+	mustCreateLease(t, db, highOld, clip)
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), low.Title)
+}
+
+func testClipPriorityOrdering(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	mustUpsertEpisodes(t, db, episode)
+
+	// Clips are ordered by priority, then by initial date curated (newest
+	// first).
+	low := newClip(1)
+	highOld := newClip(2)
+	highOld.Priority = 5
+	highNew := newClip(3)
+	highNew.Priority = 5
+	highNew.InitialDateCurated = timestamppb.New(baseTime.Add(time.Hour))
+	highNew.LastDateCurated = highNew.InitialDateCurated
+	mustUpsertClips(t, db, low, highOld, highNew)
+
+	assertClipTitles(t, mustGetClips(t, db, episode, 10), highNew.Title, highOld.Title, low.Title)
+	assertClipTitles(t, mustGetClips(t, db, episode, 2), highNew.Title, highOld.Title)
+}
+
+func testRecordCompletedResearchRemovesBacklogItem(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clips...)
+	leaseID := mustCreateLease(t, db, episode, clips...)
+
+	item := newCompletedResearchItem(episode, clips[0], int64(time.Minute), int64(2*time.Minute))
+	if err := db.RecordCompletedResearch(item); err != nil {
+		t.Fatalf("RecordCompletedResearch: %v", err)
+	}
+
+	if err := db.RevokeResearchLease(leaseID); err != nil {
+		t.Fatalf("RevokeResearchLease: %v", err)
+	}
+	assertClipTitles(t, mustGetClips(t, db, episode, 10), clips[1].Title)
+
+	if err := db.RecordCompletedResearch(item); err == nil {
+		t.Fatal("expected an error recording research for a pair that is no longer in the backlog")
+	}
+}
+
+func testRecordCompletedResearchRequiresBacklogItem(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clip := newClip(1)
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clip)
+
+	if err := db.RecordCompletedResearch(newCompletedResearchItem(newEpisode(2), clip)); err == nil {
+		t.Fatal("expected an error recording research for an unknown episode")
+	}
+	if err := db.RecordCompletedResearch(newCompletedResearchItem(episode, newClip(2))); err == nil {
+		t.Fatal("expected an error recording research for an unknown clip")
+	}
+}
+
+func testRecordCompletedResearchInsertsHashesOnce(t *testing.T, db datastore.DataStorer) {
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2)}
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episodes...)
+	mustUpsertClips(t, db, clips...)
+
+	// Each episode and clip participates in more than one research item.
+	// Hashes are only inserted the first time an episode or clip is
+	// researched, so subsequent items must not collide with the existing
+	// hashes, even if the reported hash differs.
+	for i, episode := range episodes {
+		for j, clip := range clips {
+			item := newCompletedResearchItem(episode, clip, int64(time.Minute))
+			item.EpisodeHash = fmt.Sprintf("episode hash %v", j)
+			item.ClipHash = fmt.Sprintf("clip hash %v", i)
+			if err := db.RecordCompletedResearch(item); err != nil {
+				t.Fatalf("RecordCompletedResearch(%v, %v): %v", episode.Title, clip.Title, err)
+			}
+		}
+	}
+
+	if got := mustGetHighestPriorityEpisode(t, db); got != nil {
+		t.Fatalf("expected the backlog to be empty, got %v", got)
+	}
+
+	for _, item := range mustFindEpisodeClips(t, db, episodes[1], datastore.Page{Limit: 10}) {
+		if item.EpisodeHash != "episode hash 0" {
+			t.Errorf("expected the first episode hash to be retained, got %q", item.EpisodeHash)
+		}
+		if item.ClipHash != "clip hash 0" {
+			t.Errorf("expected the first clip hash to be retained, got %q", item.ClipHash)
+		}
+	}
+}
+
+func testRecordCompletedResearchAcceptsNonMatches(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clip := newClip(1)
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clip)
+
+	// A clip that was not found within the episode has no offsets. The pair
+	// is removed from the backlog but is not recorded as completed research.
+	if err := db.RecordCompletedResearch(newCompletedResearchItem(episode, clip)); err != nil {
+		t.Fatalf("RecordCompletedResearch: %v", err)
+	}
+	if got := mustGetHighestPriorityEpisode(t, db); got != nil {
+		t.Fatalf("expected the backlog to be empty, got %v", got)
+	}
+	if got := mustFindEpisodeClips(t, db, episode, datastore.Page{Limit: 10}); len(got) != 0 {
+		t.Fatalf("expected no completed research, got %v", got)
+	}
+}
+
+func testHighestPriorityItemsIncludeMediaHashes(t *testing.T, db datastore.DataStorer) {
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2)}
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episodes...)
+	mustUpsertClips(t, db, clips...)
+
+	// Hashes are only known once an episode or clip has been researched.
+	if got := mustGetHighestPriorityEpisode(t, db); got.MediaHash != "" {
+		t.Fatalf("expected no hash for an unresearched episode, got %q", got.MediaHash)
+	}
+	mustRecordCompletedResearch(t, db, newCompletedResearchItem(episodes[0], clips[0]))
+
+	episode := mustGetHighestPriorityEpisode(t, db)
+	assertEpisodeTitle(t, episode, episodes[0].Title)
+	if episode.MediaHash != "episode hash" {
+		t.Fatalf("expected the episode's hash, got %q", episode.MediaHash)
+	}
+
+	got := mustGetClips(t, db, episodes[1], 10)
+	assertClipTitles(t, got, clips[0].Title, clips[1].Title)
+	if got[0].MediaHash != "clip hash" || got[1].MediaHash != "" {
+		t.Fatalf("expected only the researched clip's hash, got %q and %q", got[0].MediaHash, got[1].MediaHash)
+	}
+}
+
+func newResearchFailure(leaseID uuid.UUID, class contracts.FailureClass, episode *contracts.EpisodeInfo, clips ...*contracts.ClipInfo) *contracts.ResearchFailure {
+	return &contracts.ResearchFailure{
+		FailureDate:  timestamppb.New(baseTime),
+		LeaseId:      leaseID.String(),
+		EpisodeInfo:  episode,
+		ClipInfos:    clips,
+		FailureClass: class,
+		Message:      "research failed",
+	}
+}
+
+func mustRecordResearchFailure(t *testing.T, db datastore.DataStorer, failure *contracts.ResearchFailure) {
+	t.Helper()
+	if err := db.RecordResearchFailure(failure); err != nil {
+		t.Fatalf("RecordResearchFailure: %v", err)
+	}
+}
+
+func testRecordResearchFailureReleasesLease(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clips...)
+	leaseID := mustCreateLease(t, db, episode, clips...)
+
+	mustRecordResearchFailure(t, db, newResearchFailure(leaseID, contracts.FailureClass_TRANSIENT, episode, clips[0]))
+	assertClipTitles(t, mustGetClips(t, db, episode, 10), clips[0].Title)
+
+	// A failure reported under a stale lease must not release the pair from
+	// its current lease.
+	mustRecordResearchFailure(t, db, newResearchFailure(uuid.New(), contracts.FailureClass_TRANSIENT, episode, clips[1]))
+	assertClipTitles(t, mustGetClips(t, db, episode, 10), clips[0].Title)
+}
+
+func testRecordResearchFailureExcludesPermanentFailures(t *testing.T, db datastore.DataStorer) {
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2)}
+	episodes[1].Priority = 5
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episodes...)
+	mustUpsertClips(t, db, clips...)
+
+	// Transient failures never exclude a pair.
+	for i := 0; i < datastore.PermanentFailureLimit; i++ {
+		leaseID := mustCreateLease(t, db, episodes[1], clips[0])
+		mustRecordResearchFailure(t, db, newResearchFailure(leaseID, contracts.FailureClass_TRANSIENT, episodes[1], clips[0]))
+	}
+	assertClipTitles(t, mustGetClips(t, db, episodes[1], 10), clips[0].Title, clips[1].Title)
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), episodes[1].Title)
+
+	for i := 0; i < datastore.PermanentFailureLimit; i++ {
+		assertClipTitles(t, mustGetClips(t, db, episodes[1], 10), clips[0].Title, clips[1].Title)
+		leaseID := mustCreateLease(t, db, episodes[1], clips...)
+		mustRecordResearchFailure(t, db, newResearchFailure(leaseID, contracts.FailureClass_PERMANENT, episodes[1], clips...))
+	}
+	if got := mustGetClips(t, db, episodes[1], 10); got != nil {
+		t.Fatalf("expected no clips once every pair has failed permanently, got %v", got)
+	}
+
+	// Episode 2 is no longer the highest priority episode once all of its
+	// pairs have failed permanently.
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), episodes[0].Title)
+}
+
+func testRecordResearchFailureExcludesFailedEpisodes(t *testing.T, db datastore.DataStorer) {
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2)}
+	episodes[1].Priority = 5
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episodes...)
+	mustUpsertClips(t, db, clips...)
+
+	// Only the pair with the first clip ever fails, but the failure is
+	// attributed to the episode itself (for instance, because it couldn't be
+	// fetched).
+	for i := 0; i < datastore.PermanentFailureLimit; i++ {
+		assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), episodes[1].Title)
+		leaseID := mustCreateLease(t, db, episodes[1], clips[0])
+		failure := newResearchFailure(leaseID, contracts.FailureClass_PERMANENT, episodes[1], clips[0])
+		failure.FailedMedia = contracts.FailedMedia_EPISODE
+		mustRecordResearchFailure(t, db, failure)
+	}
+
+	if got := mustGetClips(t, db, episodes[1], 10); got != nil {
+		t.Fatalf("expected no clips once the episode has failed permanently, got %v", got)
+	}
+	assertEpisodeTitle(t, mustGetHighestPriorityEpisode(t, db), episodes[0].Title)
+}
+
+func testRecordResearchFailureExcludesFailedClips(t *testing.T, db datastore.DataStorer) {
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2)}
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episodes...)
+	mustUpsertClips(t, db, clips...)
+
+	// The first clip only ever fails with the second episode, but the failure
+	// is attributed to the clip itself.
+	for i := 0; i < datastore.PermanentFailureLimit; i++ {
+		assertClipTitles(t, mustGetClips(t, db, episodes[0], 10), clips[0].Title, clips[1].Title)
+		leaseID := mustCreateLease(t, db, episodes[1], clips[0])
+		failure := newResearchFailure(leaseID, contracts.FailureClass_PERMANENT, episodes[1], clips[0])
+		failure.FailedMedia = contracts.FailedMedia_CLIPS
+		mustRecordResearchFailure(t, db, failure)
+	}
+
+	assertClipTitles(t, mustGetClips(t, db, episodes[0], 10), clips[1].Title)
+	assertClipTitles(t, mustGetClips(t, db, episodes[1], 10), clips[1].Title)
+}
+
+func testRecordResearchFailureRequiresKnownItems(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clip := newClip(1)
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clip)
+
+	if err := db.RecordResearchFailure(newResearchFailure(uuid.New(), contracts.FailureClass_PERMANENT, newEpisode(2), clip)); err == nil {
+		t.Fatal("expected an error recording a failure for an unknown episode")
+	}
+	if err := db.RecordResearchFailure(newResearchFailure(uuid.New(), contracts.FailureClass_PERMANENT, episode, newClip(2))); err == nil {
+		t.Fatal("expected an error recording a failure for an unknown clip")
+	}
+
+	// A pair that has already been researched is no longer in the backlog,
+	// so a late failure for it is ignored.
+	mustRecordCompletedResearch(t, db, newCompletedResearchItem(episode, clip))
+	mustRecordResearchFailure(t, db, newResearchFailure(uuid.New(), contracts.FailureClass_PERMANENT, episode, clip))
+}
+
+func testFindClipAppearances(t *testing.T, db datastore.DataStorer) {
+	// Episode 1 aired most recently, so it is listed first.
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2), newEpisode(3)}
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2)}
+	mustUpsertEpisodes(t, db, episodes...)
+	mustUpsertClips(t, db, clips...)
+
+	inEpisode1 := newCompletedResearchItem(episodes[0], clips[0], int64(3*time.Minute), int64(time.Minute))
+	inEpisode3 := newCompletedResearchItem(episodes[2], clips[0], int64(2*time.Minute))
+	mustRecordCompletedResearch(t, db,
+		inEpisode3,
+		newCompletedResearchItem(episodes[1], clips[0]),
+		inEpisode1,
+		newCompletedResearchItem(episodes[0], clips[1], int64(time.Minute)),
+	)
+
+	// Offsets are always returned in ascending order.
+	inEpisode1.ClipOffsets = []int64{int64(time.Minute), int64(3 * time.Minute)}
+
+	got := mustFindClipAppearances(t, db, clips[0], datastore.Page{Limit: 10})
+	assertCompletedResearch(t, got, inEpisode1, inEpisode3)
+
+	item := got[0]
+	if item.EpisodeDuration != inEpisode1.EpisodeDuration || item.ClipDuration != inEpisode1.ClipDuration {
+		t.Errorf("expected durations (%v, %v), got (%v, %v)",
+			inEpisode1.EpisodeDuration, inEpisode1.ClipDuration, item.EpisodeDuration, item.ClipDuration)
+	}
+	if item.EpisodeHash != inEpisode1.EpisodeHash || item.ClipHash != inEpisode1.ClipHash {
+		t.Errorf("expected hashes (%q, %q), got (%q, %q)",
+			inEpisode1.EpisodeHash, inEpisode1.ClipHash, item.EpisodeHash, item.ClipHash)
+	}
+	assertTimestamp(t, "ResearchDate", item.ResearchDate, baseTime)
+	assertTimestamp(t, "DateAired", item.EpisodeInfo.DateAired, episodes[0].DateAired.AsTime())
+
+	assertCompletedResearch(t, mustFindClipAppearances(t, db, clips[0], datastore.Page{Offset: 1, Limit: 1}), inEpisode3)
+	assertCompletedResearch(t, mustFindClipAppearances(t, db, clips[0], datastore.Page{Offset: 2, Limit: 1}))
+}
+
+func testFindEpisodeClips(t *testing.T, db datastore.DataStorer) {
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2)}
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2), newClip(3)}
+	mustUpsertEpisodes(t, db, episodes...)
+	mustUpsertClips(t, db, clips...)
+
+	clip1 := newCompletedResearchItem(episodes[0], clips[0], int64(time.Minute))
+	clip3 := newCompletedResearchItem(episodes[0], clips[2], int64(time.Second), int64(2*time.Second))
+	mustRecordCompletedResearch(t, db,
+		clip3,
+		newCompletedResearchItem(episodes[0], clips[1]),
+		clip1,
+		newCompletedResearchItem(episodes[1], clips[1], int64(time.Minute)),
+	)
+
+	assertCompletedResearch(t, mustFindEpisodeClips(t, db, episodes[0], datastore.Page{Limit: 10}), clip1, clip3)
+	assertCompletedResearch(t, mustFindEpisodeClips(t, db, episodes[0], datastore.Page{Limit: 1}), clip1)
+	assertCompletedResearch(t, mustFindEpisodeClips(t, db, episodes[0], datastore.Page{Offset: 1, Limit: 1}), clip3)
+}
+
+func testFindRejectsUnknownItems(t *testing.T, db datastore.DataStorer) {
+	if _, err := db.FindClipAppearances(newClip(1), datastore.Page{Limit: 10}); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("expected ErrNotFound finding appearances of an unknown clip, got %v", err)
+	}
+	if _, err := db.FindEpisodeClips(newEpisode(1), datastore.Page{Limit: 10}); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("expected ErrNotFound finding clips for an unknown episode, got %v", err)
+	}
+}
+
+func testFindRejectsInvalidPages(t *testing.T, db datastore.DataStorer) {
+	episode := newEpisode(1)
+	clip := newClip(1)
+	mustUpsertEpisodes(t, db, episode)
+	mustUpsertClips(t, db, clip)
+
+	for _, page := range []datastore.Page{{Limit: 0}, {Offset: -1, Limit: 10}} {
+		if _, err := db.FindClipAppearances(clip, page); err == nil {
+			t.Errorf("expected FindClipAppearances to reject %+v", page)
+		}
+		if _, err := db.FindEpisodeClips(episode, page); err == nil {
+			t.Errorf("expected FindEpisodeClips to reject %+v", page)
+		}
+	}
+}
+
+func testListEpisodes(t *testing.T, db datastore.DataStorer) {
+	// Episodes are listed newest first, and episode 1 aired most recently.
+	mustUpsertEpisodes(t, db, newEpisode(2), newEpisode(3), newEpisode(1))
+
+	titles := func(episodes []*contracts.EpisodeInfo) []string {
+		result := []string{}
+		for _, episode := range episodes {
+			result = append(result, episode.Title)
+		}
+		return result
+	}
+
+	testCases := []struct {
+		page datastore.Page
+		want []string
+	}{
+		{datastore.Page{Limit: 10}, []string{"episode 1", "episode 2", "episode 3"}},
+		{datastore.Page{Offset: 1, Limit: 1}, []string{"episode 2"}},
+		{datastore.Page{Offset: 3, Limit: 1}, []string{}},
+	}
+	for _, testCase := range testCases {
+		episodes, err := db.ListEpisodes(testCase.page)
+		if err != nil {
+			t.Fatalf("ListEpisodes(%+v): %v", testCase.page, err)
+		}
+		if got := titles(episodes); fmt.Sprint(got) != fmt.Sprint(testCase.want) {
+			t.Errorf("ListEpisodes(%+v): expected %q, got %q", testCase.page, testCase.want, got)
+		}
+	}
+
+	if _, err := db.ListEpisodes(datastore.Page{}); err == nil {
+		t.Error("expected ListEpisodes to reject an empty page")
+	}
+}
+
+func testListClips(t *testing.T, db datastore.DataStorer) {
+	// Clips are listed by title.
+	mustUpsertClips(t, db, newClip(3), newClip(1), newClip(2))
+
+	clips, err := db.ListClips(datastore.Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListClips: %v", err)
+	}
+	assertClipTitles(t, clips, "clip 1", "clip 2", "clip 3")
+
+	clips, err = db.ListClips(datastore.Page{Offset: 2, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListClips: %v", err)
+	}
+	assertClipTitles(t, clips, "clip 3")
+
+	if _, err := db.ListClips(datastore.Page{}); err == nil {
+		t.Error("expected ListClips to reject an empty page")
+	}
+}
+
+func testGetResearchStatistics(t *testing.T, db datastore.DataStorer) {
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2)}
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2), newClip(3)}
+	mustUpsertEpisodes(t, db, episodes...)
+	mustUpsertClips(t, db, clips...)
+
+	// Of the six pairs in the backlog, two are leased, and two are researched
+	// (one of which is a match).
+	mustCreateLease(t, db, episodes[0], clips[0], clips[1])
+	mustRecordCompletedResearch(t, db,
+		newCompletedResearchItem(episodes[1], clips[0], int64(time.Minute)),
+		newCompletedResearchItem(episodes[1], clips[1]),
+	)
+
+	got, err := db.GetResearchStatistics()
+	if err != nil {
+		t.Fatalf("GetResearchStatistics: %v", err)
+	}
+	want := datastore.ResearchStatistics{
+		Episodes:           2,
+		Clips:              3,
+		BacklogItems:       4,
+		LeasedBacklogItems: 2,
+		CompletedItems:     1,
+	}
+	if *got != want {
+		t.Errorf("expected %+v, got %+v", want, *got)
+	}
+}
+
+func mustSearchClips(t *testing.T, db datastore.DataStorer, query *datastore.SearchQuery) []*contracts.ClipInfo {
+	t.Helper()
+	clips, err := db.SearchClips(query)
+	if err != nil {
+		t.Fatalf("SearchClips(%+v): %v", query, err)
+	}
+	return clips
+}
+
+func mustSearchEpisodes(t *testing.T, db datastore.DataStorer, query *datastore.SearchQuery) []*contracts.EpisodeInfo {
+	t.Helper()
+	episodes, err := db.SearchEpisodes(query)
+	if err != nil {
+		t.Fatalf("SearchEpisodes(%+v): %v", query, err)
+	}
+	return episodes
+}
+
+func testSearchClipsRanksMatches(t *testing.T, db datastore.DataStorer) {
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2), newClip(3), newClip(4)}
+	clips[0].Title = "https://example.com/mp3s/Sandwiches.mp3"
+	clips[0].Description = "Luke tries to catch one in the middle"
+	clips[1].Title = "https://example.com/mp3s/CatchOneInTheMiddle.mp3"
+	clips[1].Description = "A classic"
+	clips[2].Title = "https://example.com/mp3s/Unrelated.mp3"
+	clips[2].Description = "Nothing to see here"
+	clips[3].Title = "https://example.com/mp3s/MiddleOfTheRoad.mp3"
+	clips[3].Description = "Another classic"
+	mustUpsertClips(t, db, clips...)
+
+	query := &datastore.SearchQuery{Text: "Catch one in the MIDDLE", Page: datastore.Page{Limit: 10}}
+	assertClipTitles(t, mustSearchClips(t, db, query), clips[1].Title, clips[0].Title, clips[3].Title)
+
+	query.Page = datastore.Page{Offset: 1, Limit: 1}
+	assertClipTitles(t, mustSearchClips(t, db, query), clips[0].Title)
+
+	query = &datastore.SearchQuery{Text: "classic", Page: datastore.Page{Limit: 10}}
+	assertClipTitles(t, mustSearchClips(t, db, query), clips[1].Title, clips[3].Title)
+}
+
+func testSearchClipsFiltersByCurator(t *testing.T, db datastore.DataStorer) {
+	clips := []*contracts.ClipInfo{newClip(1), newClip(2), newClip(3)}
+	clips[1].CuratorInformation = "another curator"
+	mustUpsertClips(t, db, clips...)
+
+	query := &datastore.SearchQuery{Curator: "another curator", Page: datastore.Page{Limit: 10}}
+	assertClipTitles(t, mustSearchClips(t, db, query), clips[1].Title)
+
+	// Without any text, every clip that satisfies the filters is returned in
+	// title order.
+	query = &datastore.SearchQuery{Curator: clips[0].CuratorInformation, Page: datastore.Page{Limit: 10}}
+	assertClipTitles(t, mustSearchClips(t, db, query), clips[0].Title, clips[2].Title)
+
+	query = &datastore.SearchQuery{Text: "clip", Curator: "another curator", Page: datastore.Page{Limit: 10}}
+	assertClipTitles(t, mustSearchClips(t, db, query), clips[1].Title)
+}
+
+func testSearchClipsRejectsAirDateRange(t *testing.T, db datastore.DataStorer) {
+	query := &datastore.SearchQuery{AiredFrom: baseTime, Page: datastore.Page{Limit: 10}}
+	if _, err := db.SearchClips(query); err == nil {
+		t.Error("expected SearchClips to reject an air date range")
+	}
+	if _, err := db.SearchClips(&datastore.SearchQuery{}); err == nil {
+		t.Error("expected SearchClips to reject an empty page")
+	}
+}
+
+func testSearchEpisodesFiltersByAirDate(t *testing.T, db datastore.DataStorer) {
+	// Episode n aired n days before baseTime.
+	episodes := []*contracts.EpisodeInfo{newEpisode(1), newEpisode(2), newEpisode(3), newEpisode(4)}
+	episodes[2].Description = "the one with the sandwich"
+	mustUpsertEpisodes(t, db, episodes...)
+
+	titles := func(episodes []*contracts.EpisodeInfo) string {
+		result := []string{}
+		for _, episode := range episodes {
+			result = append(result, episode.Title)
+		}
+		return fmt.Sprint(result)
+	}
+
+	query := &datastore.SearchQuery{
+		AiredFrom: baseTime.AddDate(0, 0, -3),
+		AiredTo:   baseTime.AddDate(0, 0, -2),
+		Page:      datastore.Page{Limit: 10},
+	}
+	if got, want := titles(mustSearchEpisodes(t, db, query)), "[episode 2 episode 3]"; got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	query.Text = "sandwich"
+	if got, want := titles(mustSearchEpisodes(t, db, query)), "[episode 3]"; got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	query = &datastore.SearchQuery{Text: "episode", Page: datastore.Page{Offset: 3, Limit: 10}}
+	if got, want := titles(mustSearchEpisodes(t, db, query)), "[episode 4]"; got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,341 @@
+// Package migrations applies versioned schema migrations to a sql database.
+// Each datastore adapter embeds its own migrations and supplies a Dialect
+// that describes how applied migrations are tracked in its database.
+//
+// Migrations are files named `V<version>__<description>.sql` (the same
+// convention used by flyway). Versions start at zero and must be contiguous.
+// Statements within a migration are separated by semicolons, so semicolons
+// must not appear within comments or string literals.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var migrationNameRe = regexp.MustCompile(`^V(\d+)__(\w+)\.sql$`)
+
+// A Migration is a single versioned change to a schema.
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+// A Dialect describes the sql a Migrator uses to track migrations. Each
+// statement uses the placeholder syntax of the underlaying driver.
+type Dialect struct {
+	// CreateHistoryTable creates the table used to record applied
+	// migrations, if it does not already exist.
+	CreateHistoryTable string
+
+	// HistoryTableExists returns a single row with a single boolean column
+	// that is true if the history table exists.
+	HistoryTableExists string
+
+	// SelectVersion returns a single row with a single column containing the
+	// highest applied version, or no rows if no migrations have been applied.
+	SelectVersion string
+
+	// InsertVersion records an applied migration. The statement's parameters
+	// are the version, the description, and the time it was applied.
+	InsertVersion string
+
+	// AcquireLock and ReleaseLock, if not empty, are executed before and
+	// after migrations are applied so that concurrent migrators do not
+	// interfere with one another. AcquireLock must return a single row with a
+	// single column that is 1 if the lock was acquired (as mariadb's GET_LOCK
+	// does). Any other result, including NULL, is treated as a failure to
+	// acquire the lock.
+	AcquireLock string
+	ReleaseLock string
+
+	// BaselineVersion, if not nil, is consulted when no migrations have been
+	// recorded in the history table. It returns the highest version that was
+	// applied by some other means (such as flyway), or -1 if there is no such
+	// version. Migrations up to and including the baseline are recorded as
+	// applied without being executed.
+	BaselineVersion func(ctx context.Context, conn *sql.Conn) (int, error)
+}
+
+// OutOfDateError is returned when a database's schema does not match the
+// latest embedded migration.
+type OutOfDateError struct {
+	CurrentVersion int
+	LatestVersion  int
+}
+
+func (e *OutOfDateError) Error() string {
+	return fmt.Sprintf("database schema is at version %v but version %v is required; the schema must be migrated", e.CurrentVersion, e.LatestVersion)
+}
+
+// A Migrator applies migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	dialect    *Dialect
+	migrations []*Migration
+}
+
+// New returns a Migrator for the migrations found at the root of fsys.
+func New(db *sql.DB, dialect *Dialect, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+// Load reads all migrations from the root of fsys, ordered by version. An
+// error is returned if any sql file is misnamed, or if versions are not
+// contiguous starting at zero.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		matches := migrationNameRe.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("migration %v must be named V<version>__<description>.sql", entry.Name())
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("migration %v has an invalid version: %v", entry.Name(), err)
+		}
+
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, &Migration{
+			Version:     version,
+			Description: strings.ReplaceAll(matches[2], "_", " "),
+			Statements:  splitStatements(string(script)),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, migration := range migrations {
+		if migration.Version != i {
+			return nil, fmt.Errorf("expected migration version %v but found version %v", i, migration.Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// splitStatements splits a script into individual statements, discarding any
+// that contain nothing but whitespace and comments.
+func splitStatements(script string) []string {
+	statements := []string{}
+	for _, statement := range strings.Split(script, ";") {
+		if isBlank(statement) {
+			continue
+		}
+		statements = append(statements, strings.TrimSpace(statement))
+	}
+	return statements
+}
+
+func isBlank(statement string) bool {
+	for _, line := range strings.Split(statement, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// LatestVersion returns the version of the newest migration, or -1 if there
+// are no migrations.
+func (m *Migrator) LatestVersion() int {
+	return len(m.migrations) - 1
+}
+
+// CurrentVersion returns the version of the newest migration that has been
+// applied to the database, or -1 if no migrations have been applied. The
+// database isn't modified, so if the history table doesn't exist, it isn't
+// created, and no migrations are considered to have been applied.
+func (m *Migrator) CurrentVersion(ctx context.Context) (int, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var exists bool
+	if err := conn.QueryRowContext(ctx, m.dialect.HistoryTableExists).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("error checking for schema history table: %v", err)
+	}
+	if !exists {
+		return -1, nil
+	}
+	return m.currentVersion(ctx, conn)
+}
+
+func (m *Migrator) currentVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	var version int
+	err := conn.QueryRowContext(ctx, m.dialect.SelectVersion).Scan(&version)
+	if err == sql.ErrNoRows {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading schema version: %v", err)
+	}
+	return version, nil
+}
+
+// CheckVersion returns an *OutOfDateError if the database has not had every
+// migration applied.
+func (m *Migrator) CheckVersion(ctx context.Context) error {
+	current, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current != m.LatestVersion() {
+		return &OutOfDateError{
+			CurrentVersion: current,
+			LatestVersion:  m.LatestVersion(),
+		}
+	}
+	return nil
+}
+
+// Migrate applies every migration that has not yet been applied to the
+// database. Each migration is applied within its own transaction, though
+// some databases (such as mariadb) implicitly commit schema changes.
+func (m *Migrator) Migrate(ctx context.Context) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect.AcquireLock != "" {
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(ctx, m.dialect.AcquireLock).Scan(&acquired); err != nil {
+			return fmt.Errorf("error acquiring migration lock: %v", err)
+		}
+		if !acquired.Valid || acquired.Int64 != 1 {
+			return errors.New("unable to acquire migration lock; it may be held by another migrator")
+		}
+		defer func() {
+			_, releaseErr := conn.ExecContext(context.Background(), m.dialect.ReleaseLock)
+			if err == nil && releaseErr != nil {
+				err = fmt.Errorf("error releasing migration lock: %v", releaseErr)
+			}
+		}()
+	}
+
+	if _, err := conn.ExecContext(ctx, m.dialect.CreateHistoryTable); err != nil {
+		return fmt.Errorf("error creating schema history table: %v", err)
+	}
+
+	current, err := m.currentVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if current < 0 && m.dialect.BaselineVersion != nil {
+		current, err = m.baseline(ctx, conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	if current > m.LatestVersion() {
+		return fmt.Errorf("database schema is at version %v, which is newer than the latest known version %v", current, m.LatestVersion())
+	}
+
+	for _, migration := range m.migrations[current+1:] {
+		if err := m.apply(ctx, conn, migration, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// baseline records migrations that were applied by some other means as
+// having been applied, and returns the baseline version.
+func (m *Migrator) baseline(ctx context.Context, conn *sql.Conn) (int, error) {
+	baselineVersion, err := m.dialect.BaselineVersion(ctx, conn)
+	if err != nil {
+		return 0, fmt.Errorf("error reading baseline schema version: %v", err)
+	}
+
+	if baselineVersion > m.LatestVersion() {
+		baselineVersion = m.LatestVersion()
+	}
+
+	for _, migration := range m.migrations[:baselineVersion+1] {
+		if err := m.apply(ctx, conn, migration, false); err != nil {
+			return 0, err
+		}
+	}
+
+	return baselineVersion, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration *Migration, execute bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if execute {
+		// Another migrator may have applied the migration since the current
+		// version was read. Databases that don't support AcquireLock must
+		// serialize these transactions (as sqlite's immediate transactions
+		// do), so that the version read here is reliable.
+		var applied int
+		err := tx.QueryRowContext(ctx, m.dialect.SelectVersion).Scan(&applied)
+		if err != nil && err != sql.ErrNoRows {
+			tx.Rollback()
+			return fmt.Errorf("error reading schema version: %v", err)
+		}
+		if err == nil && applied >= migration.Version {
+			return tx.Rollback()
+		}
+
+		for _, statement := range migration.Statements {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("error applying migration V%v (%v): %v", migration.Version, migration.Description, err)
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, m.dialect.InsertVersion, migration.Version, migration.Description, time.Now().UTC())
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error recording migration V%v (%v): %v", migration.Version, migration.Description, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,272 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/migrations"
+
+	// Registers the sqlite3 driver.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var dialect = &migrations.Dialect{
+	CreateHistoryTable: `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, description TEXT NOT NULL, applied_on DATETIME NOT NULL)`,
+	HistoryTableExists: `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+	SelectVersion:      `SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1`,
+	InsertVersion:      `INSERT INTO schema_migrations (version, description, applied_on) VALUES (?, ?, ?)`,
+}
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func file(script string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(script)}
+}
+
+func Test_Load(t *testing.T) {
+	testCases := []struct {
+		name               string
+		fsys               fstest.MapFS
+		expectedStatements [][]string
+		expectError        bool
+	}{
+		{
+			name: "orders by version and splits statements",
+			fsys: fstest.MapFS{
+				"V1__Second.sql": file("-- a comment\nCREATE TABLE b (id INTEGER);\n"),
+				"V0__First.sql":  file("CREATE TABLE a (id INTEGER);\nCREATE INDEX a_idx ON a(id);\n-- trailing comment\n"),
+				"README.md":      file("ignored"),
+			},
+			expectedStatements: [][]string{
+				{"CREATE TABLE a (id INTEGER)", "CREATE INDEX a_idx ON a(id)"},
+				{"-- a comment\nCREATE TABLE b (id INTEGER)"},
+			},
+		},
+		{
+			name: "misnamed migration",
+			fsys: fstest.MapFS{
+				"V0_First.sql": file("CREATE TABLE a (id INTEGER);"),
+			},
+			expectError: true,
+		},
+		{
+			name: "gap in versions",
+			fsys: fstest.MapFS{
+				"V0__First.sql": file("CREATE TABLE a (id INTEGER);"),
+				"V2__Third.sql": file("CREATE TABLE c (id INTEGER);"),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			loaded, err := migrations.Load(testCase.fsys)
+			if testCase.expectError {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			statements := [][]string{}
+			for _, migration := range loaded {
+				statements = append(statements, migration.Statements)
+			}
+			if !reflect.DeepEqual(testCase.expectedStatements, statements) {
+				t.Errorf("expected %q, got %q", testCase.expectedStatements, statements)
+			}
+		})
+	}
+}
+
+func Test_MigrateAppliesOnlyNewMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	fsys := fstest.MapFS{
+		"V0__First.sql": file("CREATE TABLE a (id INTEGER);"),
+	}
+
+	migrator, err := migrations.New(db, dialect, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var outOfDateErr *migrations.OutOfDateError
+	if err := migrator.CheckVersion(ctx); !errors.As(err, &outOfDateErr) {
+		t.Fatalf("expected an OutOfDateError, got %v", err)
+	}
+
+	if err := migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.CheckVersion(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reapplying V0 would fail, since table a already exists.
+	fsys["V1__Second.sql"] = file("CREATE TABLE b (id INTEGER);")
+	migrator, err = migrations.New(db, dialect, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := migrator.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 {
+		t.Errorf("expected version 1, got %v", version)
+	}
+}
+
+func Test_MigrateAdoptsBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	if _, err := db.Exec("CREATE TABLE a (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+
+	baselineDialect := *dialect
+	baselineDialect.BaselineVersion = func(ctx context.Context, conn *sql.Conn) (int, error) {
+		return 0, nil
+	}
+	fsys := fstest.MapFS{
+		"V0__First.sql":  file("CREATE TABLE a (id INTEGER);"),
+		"V1__Second.sql": file("CREATE TABLE b (id INTEGER);"),
+	}
+
+	migrator, err := migrations.New(db, &baselineDialect, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.CheckVersion(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_CurrentVersionDoesNotCreateHistoryTable(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	fsys := fstest.MapFS{
+		"V0__First.sql": file("CREATE TABLE a (id INTEGER);"),
+	}
+
+	migrator, err := migrations.New(db, dialect, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := migrator.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != -1 {
+		t.Errorf("expected version -1, got %v", version)
+	}
+
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Errorf("expected CurrentVersion not to create any tables, found %v", tables)
+	}
+}
+
+func Test_MigrateRequiresLock(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{
+		"V0__First.sql": file("CREATE TABLE a (id INTEGER);"),
+	}
+
+	testCases := []struct {
+		name        string
+		acquireLock string
+		expectError bool
+	}{
+		{"acquired", "SELECT 1", false},
+		{"timed out", "SELECT 0", true},
+		{"error", "SELECT NULL", true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			lockingDialect := *dialect
+			lockingDialect.AcquireLock = testCase.acquireLock
+			lockingDialect.ReleaseLock = "SELECT 1"
+
+			migrator, err := migrations.New(openDB(t), &lockingDialect, fsys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = migrator.Migrate(ctx)
+			if testCase.expectError && err == nil {
+				t.Fatal("expected an error")
+			}
+			if !testCase.expectError && err != nil {
+				t.Fatal(err)
+			}
+
+			version, err := migrator.CurrentVersion(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if testCase.expectError && version != -1 {
+				t.Errorf("expected no migrations to be applied without the lock, got version %v", version)
+			}
+		})
+	}
+}
+
+func Test_ConcurrentMigratorsApplyEachMigrationOnce(t *testing.T) {
+	ctx := context.Background()
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_txlock=immediate&_busy_timeout=5000"
+	fsys := fstest.MapFS{
+		"V0__First.sql":  file("CREATE TABLE a (id INTEGER);"),
+		"V1__Second.sql": file("CREATE TABLE b (id INTEGER);"),
+	}
+
+	errs := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			db, err := sql.Open("sqlite3", dsn)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer db.Close()
+
+			migrator, err := migrations.New(db, dialect, fsys)
+			if err == nil {
+				err = migrator.Migrate(ctx)
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package search implements the tokenizing and ranking that the datastore
+// adapters use to search curated episode and clip metadata. Keeping this
+// logic in one place ensures that every adapter ranks results identically,
+// regardless of the full-text capabilities of the underlaying database.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	titleTokenWeight        = 3.0
+	descriptionTokenWeight  = 1.0
+	titlePrefixWeight       = 1.5
+	descriptionPrefixWeight = 0.5
+	titlePhraseWeight       = 5.0
+	descriptionPhraseWeight = 2.0
+
+	// minPrefixLength is the shortest query token that will match the prefix
+	// of a longer token.
+	minPrefixLength = 3
+)
+
+// Tokenize splits text into lower case tokens. Text is split on anything
+// other than letters and digits, and at lower-to-upper case transitions, so
+// that titles such as "CatchOneInTheMiddle.mp3" are tokenized the same as
+// "catch one in the middle mp3". Single character tokens are discarded.
+func Tokenize(text string) []string {
+	tokens := []string{}
+	current := []rune{}
+	flush := func() {
+		if len(current) > 1 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+		}
+		current = current[:0]
+	}
+
+	var previous rune
+	for _, r := range text {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(previous):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+		previous = r
+	}
+	flush()
+
+	return tokens
+}
+
+// A Matcher scores text against a search query.
+type Matcher struct {
+	tokens []string
+	phrase []string
+}
+
+// NewMatcher returns a Matcher for the supplied query text.
+func NewMatcher(query string) *Matcher {
+	phrase := Tokenize(query)
+	return &Matcher{
+		tokens: unique(phrase),
+		phrase: phrase,
+	}
+}
+
+// Tokens returns the distinct tokens in the query. If the query has no
+// tokens, every candidate matches with a score of zero.
+func (m *Matcher) Tokens() []string {
+	return m.tokens
+}
+
+// A Field identifies the text that a Clause is matched against.
+type Field int
+
+// The fields of a candidate.
+const (
+	Title Field = iota
+	Description
+)
+
+// A Clause awards Weight to a candidate if the Index of the candidate's Field
+// contains Substring. Substrings only contain letters, digits, and spaces, so
+// adapters may match them with a LIKE pattern without escaping.
+type Clause struct {
+	Field     Field
+	Substring string
+	Weight    float64
+}
+
+// A Term is a list of clauses, of which only the first that a candidate
+// satisfies is awarded.
+type Term []Clause
+
+// Terms returns the terms of the query. A candidate's score is the sum of
+// the terms that it satisfies, so adapters can rank candidates within the
+// database by matching each clause against indexed titles and descriptions.
+//
+// There is a term for each token, which awards a whole token in the title
+// over one in the description, and a whole token over a prefix. If the query
+// has more than one token, there is also a term that awards the query
+// appearing as a phrase.
+func (m *Matcher) Terms() []Term {
+	terms := []Term{}
+	for _, token := range m.tokens {
+		term := Term{
+			{Title, " " + token + " ", titleTokenWeight},
+			{Description, " " + token + " ", descriptionTokenWeight},
+		}
+		if len(token) >= minPrefixLength {
+			term = append(term,
+				Clause{Title, " " + token, titlePrefixWeight},
+				Clause{Description, " " + token, descriptionPrefixWeight},
+			)
+		}
+		terms = append(terms, term)
+	}
+
+	if len(m.phrase) > 1 {
+		phrase := " " + strings.Join(m.phrase, " ") + " "
+		terms = append(terms, Term{
+			{Title, phrase, titlePhraseWeight},
+			{Description, phrase, descriptionPhraseWeight},
+		})
+	}
+
+	return terms
+}
+
+// Index returns the form of text that clauses are matched against: its
+// tokens, each surrounded by a single space.
+func Index(text string) string {
+	return " " + strings.Join(Tokenize(text), " ") + " "
+}
+
+// Score ranks a title and description against the query. Higher scores are
+// better matches. Tokens found in the title are weighted more heavily than
+// tokens found in the description, whole tokens are weighted more heavily
+// than prefixes, and a bonus is awarded if the query appears as a phrase. A
+// score of zero means that the candidate does not match the query at all.
+func (m *Matcher) Score(title, description string) float64 {
+	indexes := map[Field]string{
+		Title:       Index(title),
+		Description: Index(description),
+	}
+
+	score := 0.0
+	for _, term := range m.Terms() {
+		for _, clause := range term {
+			if strings.Contains(indexes[clause.Field], clause.Substring) {
+				score += clause.Weight
+				break
+			}
+		}
+	}
+	return score
+}
+
+// Matches returns true if the query has no tokens, or if the title and
+// description match the query.
+func (m *Matcher) Matches(title, description string) bool {
+	return len(m.tokens) == 0 || m.Score(title, description) > 0
+}
+
+func unique(tokens []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			result = append(result, token)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/search"
+)
+
+func Test_Tokenize(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected []string
+	}{
+		{"Catch one in the middle", []string{"catch", "one", "in", "the", "middle"}},
+		{"CatchOneInTheMiddle.mp3", []string{"catch", "one", "in", "the", "middle", "mp3"}},
+		{"https://example.com/clips/catch_one_in_the_middle.mp3", []string{"https", "example", "com", "clips", "catch", "one", "in", "the", "middle", "mp3"}},
+		{"Don't panic, it's a TBTL episode", []string{"don", "panic", "it", "tbtl", "episode"}},
+		{"", []string{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.text, func(t *testing.T) {
+			got := search.Tokenize(testCase.text)
+			if !reflect.DeepEqual(testCase.expected, got) {
+				t.Errorf("expected %q, got %q", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func Test_MatcherRanksTitlesAndPhrasesHigher(t *testing.T) {
+	matcher := search.NewMatcher("Catch one in the middle")
+
+	inTitlePhrase := matcher.Score("CatchOneInTheMiddle.mp3", "")
+	inTitle := matcher.Score("the middle one to catch", "")
+	inDescription := matcher.Score("clip.mp3", "Luke tries to catch one in the middle")
+	prefixOnly := matcher.Score("catches", "")
+	noMatch := matcher.Score("clip.mp3", "something else entirely")
+
+	if !(inTitlePhrase > inTitle && inTitle > inDescription && inDescription > prefixOnly && prefixOnly > noMatch) {
+		t.Errorf("unexpected ranking: phrase in title %v, title %v, description %v, prefix %v, none %v",
+			inTitlePhrase, inTitle, inDescription, prefixOnly, noMatch)
+	}
+	if noMatch != 0 {
+		t.Errorf("expected a non-match to score zero, got %v", noMatch)
+	}
+}
+
+func Test_EmptyQueryMatchesEverything(t *testing.T) {
+	matcher := search.NewMatcher("  ")
+	if !matcher.Matches("anything", "") {
+		t.Error("expected an empty query to match")
+	}
+}
+
+func Test_Index(t *testing.T) {
+	expected := " catch one in the middle mp3 "
+	if got := search.Index("CatchOneInTheMiddle.mp3"); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,178 @@
+package mediacache
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	indexName      = "index.json"
+	downloadPrefix = "download-"
+)
+
+// An indexEntry records the content of the media at a uri.
+type indexEntry struct {
+	Hash     string    `json:"hash"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// load reads the index left by a previous Cache, dropping any uris whose
+// media is missing, and removes any media or partial downloads that the index
+// doesn't refer to.
+func (c *Cache) load() error {
+	c.index = make(map[string]*indexEntry)
+
+	indexBytes, err := ioutil.ReadFile(filepath.Join(c.dir, indexName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to read media cache index: %w", err)
+	}
+	if err == nil {
+		// A corrupt index only costs us the cached media, which will be
+		// downloaded again as needed.
+		if json.Unmarshal(indexBytes, &c.index) != nil {
+			c.index = make(map[string]*indexEntry)
+		}
+	}
+
+	for uri, e := range c.index {
+		info, err := os.Stat(c.path(e.Hash))
+		if err != nil || !isMedia(e.Hash) || info.Size() != e.Size {
+			delete(c.index, uri)
+			continue
+		}
+		if _, ok := c.sizes[e.Hash]; !ok {
+			c.sizes[e.Hash] = e.Size
+			c.size += e.Size
+		}
+	}
+
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("unable to read media cache directory: %w", err)
+	}
+	for _, file := range files {
+		_, cached := c.sizes[file.Name()]
+		stray := isMedia(file.Name()) && !cached
+		if stray || strings.HasPrefix(file.Name(), downloadPrefix) {
+			if err := os.Remove(filepath.Join(c.dir, file.Name())); err != nil {
+				return fmt.Errorf("unable to clean media cache directory: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// save writes the index. The caller must hold c.mu.
+func (c *Cache) save() error {
+	indexBytes, err := json.Marshal(c.index)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(c.dir, indexName+".")
+	if err != nil {
+		return fmt.Errorf("unable to write media cache index: %w", err)
+	}
+	_, err = tmp.Write(indexBytes)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, indexName))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("unable to write media cache index: %w", err)
+	}
+	return nil
+}
+
+// download fetches the media at uri into the cache directory, and returns
+// its digest, size, and modification time.
+func (c *Cache) download(ctx context.Context, uri string) (string, int64, time.Time, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return "", 0, time.Time{}, err
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return "", 0, time.Time{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", 0, time.Time{}, &StatusError{URI: uri, StatusCode: response.StatusCode}
+	}
+
+	tmp, err := ioutil.TempFile(c.dir, downloadPrefix)
+	if err != nil {
+		return "", 0, time.Time{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	digest := md5.New()
+	size, err := io.Copy(io.MultiWriter(tmp, digest), response.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, time.Time{}, fmt.Errorf("unable to download %v: %w", uri, err)
+	}
+	if response.ContentLength >= 0 && size != response.ContentLength {
+		return "", 0, time.Time{}, fmt.Errorf("unable to download %v: expected %v bytes, got %v", uri, response.ContentLength, size)
+	}
+
+	hash := hex.EncodeToString(digest.Sum(nil))
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return "", 0, time.Time{}, err
+	}
+	if err := os.Rename(tmp.Name(), c.path(hash)); err != nil {
+		return "", 0, time.Time{}, err
+	}
+	return hash, size, info.ModTime(), nil
+}
+
+// verify returns an error if the content of the file at path doesn't match
+// hash and size. If the file hasn't been modified since verifiedModTime, it's
+// assumed to match without being read. The file's modification time is
+// returned so that the result can be reused.
+func verify(path, hash string, size int64, verifiedModTime time.Time) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if info.Size() != size {
+		return time.Time{}, fmt.Errorf("expected %v bytes, got %v", size, info.Size())
+	}
+	if !verifiedModTime.IsZero() && info.ModTime().Equal(verifiedModTime) {
+		return verifiedModTime, nil
+	}
+
+	digest := md5.New()
+	if _, err := io.Copy(digest, f); err != nil {
+		return time.Time{}, err
+	}
+	if actual := hex.EncodeToString(digest.Sum(nil)); actual != hash {
+		return time.Time{}, fmt.Errorf("expected digest %v, got %v", hash, actual)
+	}
+	return info.ModTime(), nil
+}
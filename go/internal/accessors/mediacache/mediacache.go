@@ -0,0 +1,390 @@
+// Package mediacache provides a local cache of episode and clip media, so
+// that media that is researched repeatedly (particularly clips, which are
+// compared against every episode) is only downloaded once per host.
+//
+// Media is stored in a directory under the hex encoded MD5 digest of its
+// content, and an index maps each media URI to the digest of its content.
+// Whenever cached media is acquired, its content is verified against the
+// digest stored in the index (and against the digest that the caller expects,
+// such as the one stored in the datastore, if it's known), and it is
+// downloaded again if they differ. Verification is only repeated if the media
+// has been modified since it was last verified.
+// Once the cache exceeds its size limit, the least recently used media that
+// isn't in use is evicted.
+package mediacache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config is a configuration for a media cache.
+type Config struct {
+	// Dir is the directory in which media is stored. It's created if it
+	// doesn't exist.
+	Dir string
+
+	// MaxBytes is the size to which the cache is reduced by evicting media
+	// that isn't in use. If zero, DefaultMaxBytes is used.
+	MaxBytes int64
+
+	// Client is used to download media. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// DefaultMaxBytes is used if a Config doesn't specify MaxBytes.
+const DefaultMaxBytes = 10 << 30
+
+// DefaultConfig returns a Config that stores media in the user's cache
+// directory.
+func DefaultConfig() (*Config, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Config{
+		Dir:      filepath.Join(dir, "tbtlarchivist", "media"),
+		MaxBytes: DefaultMaxBytes,
+	}, nil
+}
+
+// Validate returns an error if the Config is not usable.
+func (c *Config) Validate() error {
+	if c.Dir == "" {
+		return errors.New("media cache config: Dir is required")
+	}
+	if c.MaxBytes < 0 {
+		return fmt.Errorf("media cache config: MaxBytes must not be negative, got %v", c.MaxBytes)
+	}
+	return nil
+}
+
+// A Cache is a size-limited collection of media stored in a directory. A
+// Cache is safe for concurrent use, but a directory must only be used by one
+// Cache at a time.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	client   *http.Client
+
+	mu       sync.Mutex
+	index    map[string]*indexEntry
+	refs     map[string]int
+	sizes    map[string]int64
+	stale    map[string]bool
+	verified map[string]time.Time
+	size     int64
+	inFlight map[string]chan struct{}
+}
+
+// An Entry is media that has been acquired from a Cache. The media won't be
+// evicted until the Entry is released.
+type Entry struct {
+	// Path is the location of the media on the local filesystem.
+	Path string
+
+	// Hash is the hex encoded MD5 digest of the media.
+	Hash string
+
+	// Size is the length of the media in bytes.
+	Size int64
+
+	cache    *Cache
+	released sync.Once
+}
+
+// A StatusError is returned (possibly wrapped) by Acquire when media can't be
+// downloaded because the server responded with a status other than 200.
+type StatusError struct {
+	URI        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received a non-200 http response %v for %v", e.StatusCode, e.URI)
+}
+
+// Open returns a Cache that stores media in the configured directory. Media
+// that was cached by a previous Cache in the same directory is retained.
+func Open(config *Config) (*Cache, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	maxBytes := config.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	client := config.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create media cache directory: %w", err)
+	}
+
+	c := &Cache{
+		dir:      config.Dir,
+		maxBytes: maxBytes,
+		client:   client,
+		refs:     make(map[string]int),
+		sizes:    make(map[string]int64),
+		stale:    make(map[string]bool),
+		verified: make(map[string]time.Time),
+		inFlight: make(map[string]chan struct{}),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.evict(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Acquire returns an Entry for the media identified by uri, downloading the
+// media if it isn't cached, or if the cached media doesn't match its stored
+// digest. If expectedHash is the hex encoded MD5 digest of the media, such as
+// the one stored in the datastore, cached media that doesn't match it is
+// downloaded again, and an error is returned if the downloaded media doesn't
+// match it either. Otherwise, expectedHash is ignored. If several callers
+// acquire the same uri at once, it's downloaded only once. The Entry must be
+// released once the caller is done with the media.
+func (c *Cache) Acquire(ctx context.Context, uri, expectedHash string) (*Entry, error) {
+	if !isMedia(expectedHash) {
+		expectedHash = ""
+	}
+
+	for {
+		c.mu.Lock()
+		if e, ok := c.index[uri]; ok {
+			if expectedHash != "" && e.Hash != expectedHash {
+				// The media at uri is no longer the media that was
+				// cached, but other uris may still refer to it.
+				delete(c.index, uri)
+				var err error
+				if !c.referenced(e.Hash) {
+					err = c.discard(e.Hash)
+				} else {
+					err = c.save()
+				}
+				c.mu.Unlock()
+				if err != nil {
+					return nil, fmt.Errorf("unable to discard %v from the media cache: %w", uri, err)
+				}
+				continue
+			}
+
+			c.refs[e.Hash]++
+			e.LastUsed = time.Now()
+			verifiedModTime := c.verified[e.Hash]
+			c.mu.Unlock()
+
+			entry := &Entry{
+				Path:  c.path(e.Hash),
+				Hash:  e.Hash,
+				Size:  e.Size,
+				cache: c,
+			}
+			modTime, verifyErr := verify(entry.Path, e.Hash, e.Size, verifiedModTime)
+			if verifyErr == nil {
+				c.mu.Lock()
+				c.verified[e.Hash] = modTime
+				c.mu.Unlock()
+				return entry, nil
+			}
+
+			c.mu.Lock()
+			c.refs[e.Hash]--
+			err := c.discard(e.Hash)
+			c.mu.Unlock()
+			if err != nil {
+				return nil, fmt.Errorf("unable to discard %v from the media cache after it failed verification (%v): %w", uri, verifyErr, err)
+			}
+			continue
+		}
+
+		if wait, ok := c.inFlight[uri]; ok {
+			c.mu.Unlock()
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		done := make(chan struct{})
+		c.inFlight[uri] = done
+		c.mu.Unlock()
+
+		hash, size, modTime, err := c.download(ctx, uri)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.inFlight, uri)
+		close(done)
+		if err != nil {
+			return nil, err
+		}
+
+		// A fresh download replaces any stale media with the same content.
+		delete(c.stale, hash)
+		c.verified[hash] = modTime
+		c.add(uri, hash, size)
+		if expectedHash != "" && hash != expectedHash {
+			// The media is still cached, since it's what uri refers to,
+			// but the caller can't use it.
+			if err := c.evict(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("the media at %v doesn't match the expected digest %v (got %v)", uri, expectedHash, hash)
+		}
+		c.refs[hash]++
+		entry := &Entry{
+			Path:  c.path(hash),
+			Hash:  hash,
+			Size:  size,
+			cache: c,
+		}
+		if err := c.evict(); err != nil {
+			c.refs[hash]--
+			return nil, err
+		}
+		return entry, nil
+	}
+}
+
+// Size returns the total size in bytes of the media in the cache.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Release indicates that the caller is done with the media, which may then
+// be evicted. Releasing an Entry more than once has no effect.
+func (e *Entry) Release() error {
+	var err error
+	e.released.Do(func() {
+		e.cache.mu.Lock()
+		defer e.cache.mu.Unlock()
+		e.cache.refs[e.Hash]--
+		if e.cache.refs[e.Hash] == 0 && e.cache.stale[e.Hash] {
+			delete(e.cache.stale, e.Hash)
+			if err = e.cache.remove(e.Hash); err != nil {
+				return
+			}
+		}
+		err = e.cache.evict()
+	})
+	return err
+}
+
+func (c *Cache) path(hash string) string {
+	return filepath.Join(c.dir, hash)
+}
+
+// add records that uri has the content identified by hash. The caller must
+// hold c.mu.
+func (c *Cache) add(uri, hash string, size int64) {
+	if _, ok := c.sizes[hash]; !ok {
+		c.sizes[hash] = size
+		c.size += size
+	}
+	c.index[uri] = &indexEntry{
+		Hash:     hash,
+		Size:     size,
+		LastUsed: time.Now(),
+	}
+}
+
+// discard removes each uri that refers to media from the cache, and removes
+// the media itself. Media that is still in use is marked as stale instead, and
+// is removed once the last Entry that refers to it is released. The caller
+// must hold c.mu.
+func (c *Cache) discard(hash string) error {
+	for uri, e := range c.index {
+		if e.Hash == hash {
+			delete(c.index, uri)
+		}
+	}
+	delete(c.verified, hash)
+	if c.refs[hash] > 0 {
+		c.stale[hash] = true
+	} else if err := c.remove(hash); err != nil {
+		return err
+	}
+	return c.save()
+}
+
+// remove deletes media from the cache directory. The caller must hold c.mu.
+func (c *Cache) remove(hash string) error {
+	c.size -= c.sizes[hash]
+	delete(c.sizes, hash)
+	delete(c.verified, hash)
+	if err := os.Remove(c.path(hash)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// evict removes the least recently used media that isn't in use until the
+// cache is no larger than its limit. Media that is referred to by several
+// uris is only removed once no uri refers to it. The caller must hold c.mu.
+func (c *Cache) evict() error {
+	for c.size > c.maxBytes {
+		lruURI := ""
+		var lru *indexEntry
+		for uri, e := range c.index {
+			if c.refs[e.Hash] > 0 {
+				continue
+			}
+			if lru == nil || e.LastUsed.Before(lru.LastUsed) {
+				lruURI, lru = uri, e
+			}
+		}
+		if lru == nil {
+			break
+		}
+
+		delete(c.index, lruURI)
+		if c.referenced(lru.Hash) {
+			continue
+		}
+		if err := c.remove(lru.Hash); err != nil {
+			return fmt.Errorf("unable to evict %v from the media cache: %w", lruURI, err)
+		}
+	}
+
+	return c.save()
+}
+
+// referenced returns true if any uri in the index refers to hash. The caller
+// must hold c.mu.
+func (c *Cache) referenced(hash string) bool {
+	for _, e := range c.index {
+		if e.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// isMedia returns true if name could be the name of cached media.
+func isMedia(name string) bool {
+	return len(name) == 32 && strings.Trim(name, "0123456789abcdef") == ""
+}
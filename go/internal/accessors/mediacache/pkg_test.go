@@ -0,0 +1,336 @@
+package mediacache_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/mediacache"
+)
+
+// mediaServer serves ten bytes of media at any path other than /missing (or
+// any path that has been removed), and counts the requests for each path.
+type mediaServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests map[string]int
+	removed  map[string]bool
+}
+
+func newMediaServer(t *testing.T) *mediaServer {
+	s := &mediaServer{requests: make(map[string]int), removed: make(map[string]bool)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		removed := s.removed[r.URL.Path]
+		s.mu.Unlock()
+		if r.URL.Path == "/missing" || removed {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(media(r.URL.Path))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *mediaServer) remove(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed[path] = true
+}
+
+func (s *mediaServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func media(path string) []byte {
+	return []byte((path + "..........")[:10])
+}
+
+func mustOpen(t *testing.T, dir string, maxBytes int64) *mediacache.Cache {
+	cache, err := mediacache.Open(&mediacache.Config{Dir: dir, MaxBytes: maxBytes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache
+}
+
+func mustAcquire(t *testing.T, cache *mediacache.Cache, uri string) *mediacache.Entry {
+	entry, err := cache.Acquire(context.Background(), uri, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func mustRelease(t *testing.T, entry *mediacache.Entry) {
+	if err := entry.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func digest(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// corrupt overwrites the media at path, and sets its modification time to
+// modTime.
+func corrupt(t *testing.T, path string, modTime time.Time) {
+	if err := ioutil.WriteFile(path, []byte("corrupted!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_ConfigValidate(t *testing.T) {
+	if err := (&mediacache.Config{}).Validate(); err == nil {
+		t.Error("expected an error for a missing Dir")
+	}
+	if err := (&mediacache.Config{Dir: "x", MaxBytes: -1}).Validate(); err == nil {
+		t.Error("expected an error for a negative MaxBytes")
+	}
+	if err := (&mediacache.Config{Dir: "x"}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_AcquireDownloadsOnce(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 100)
+
+	for i := 0; i < 3; i++ {
+		entry := mustAcquire(t, cache, server.URL+"/a")
+		content, err := ioutil.ReadFile(entry.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != string(media("/a")) {
+			t.Fatalf("expected %q, got %q", media("/a"), content)
+		}
+		if entry.Hash != digest(content) {
+			t.Fatalf("expected hash %v, got %v", digest(content), entry.Hash)
+		}
+		mustRelease(t, entry)
+	}
+
+	if n := server.count("/a"); n != 1 {
+		t.Fatalf("expected 1 download, got %v", n)
+	}
+}
+
+func Test_AcquireConcurrentDownloadsOnce(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 100)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry, err := cache.Acquire(context.Background(), server.URL+"/a", "")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = entry.Release()
+		}()
+	}
+	wg.Wait()
+
+	if n := server.count("/a"); n != 1 {
+		t.Fatalf("expected 1 download, got %v", n)
+	}
+}
+
+func Test_AcquireVerifiesCachedMedia(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 100)
+
+	entry := mustAcquire(t, cache, server.URL+"/a")
+	mustRelease(t, entry)
+	corrupt(t, entry.Path, time.Now().Add(time.Hour))
+
+	entry = mustAcquire(t, cache, server.URL+"/a")
+	defer mustRelease(t, entry)
+	content, err := ioutil.ReadFile(entry.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(media("/a")) {
+		t.Fatalf("expected %q, got %q", media("/a"), content)
+	}
+	if n := server.count("/a"); n != 2 {
+		t.Fatalf("expected 2 downloads, got %v", n)
+	}
+}
+
+func Test_AcquireOnlyVerifiesModifiedMedia(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 100)
+
+	entry := mustAcquire(t, cache, server.URL+"/a")
+	mustRelease(t, entry)
+	info, err := os.Stat(entry.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Media that hasn't been modified since it was verified isn't read again,
+	// so the corruption goes unnoticed.
+	corrupt(t, entry.Path, info.ModTime())
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/a"))
+	if n := server.count("/a"); n != 1 {
+		t.Fatalf("expected 1 download, got %v", n)
+	}
+
+	corrupt(t, entry.Path, info.ModTime().Add(time.Hour))
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/a"))
+	if n := server.count("/a"); n != 2 {
+		t.Fatalf("expected 2 downloads, got %v", n)
+	}
+}
+
+func Test_AcquireVerifiesExpectedHash(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 100)
+	uri := server.URL + "/a"
+
+	entry, err := cache.Acquire(context.Background(), uri, digest(media("/a")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustRelease(t, entry)
+
+	// Hashes that aren't MD5 digests are ignored.
+	entry, err = cache.Acquire(context.Background(), uri, "not a digest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustRelease(t, entry)
+	if n := server.count("/a"); n != 1 {
+		t.Fatalf("expected 1 download, got %v", n)
+	}
+
+	// Cached media that doesn't match the expected hash is downloaded again.
+	if _, err := cache.Acquire(context.Background(), uri, digest(media("/b"))); err == nil {
+		t.Fatal("expected an error acquiring media that doesn't match the expected hash")
+	}
+	if n := server.count("/a"); n != 2 {
+		t.Fatalf("expected 2 downloads, got %v", n)
+	}
+}
+
+func Test_AcquireDoesNotDiscardMediaInUse(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 100)
+
+	entry := mustAcquire(t, cache, server.URL+"/a")
+	corrupt(t, entry.Path, time.Now().Add(time.Hour))
+
+	// The corrupt media can't be downloaded again, but it's still in use, so
+	// it's only removed once it's released.
+	server.remove("/a")
+	if _, err := cache.Acquire(context.Background(), server.URL+"/a", ""); err == nil {
+		t.Fatal("expected an error acquiring media that can't be downloaded")
+	}
+	if _, err := os.Stat(entry.Path); err != nil {
+		t.Fatalf("expected media in use to be retained, got %v", err)
+	}
+
+	mustRelease(t, entry)
+	if _, err := os.Stat(entry.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected stale media to be removed once released, got %v", err)
+	}
+	if cache.Size() != 0 {
+		t.Fatalf("expected an empty cache, got %v bytes", cache.Size())
+	}
+}
+
+func Test_AcquireNon200(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 100)
+
+	_, err := cache.Acquire(context.Background(), server.URL+"/missing", "")
+	var statusErr *mediacache.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 StatusError, got %v", err)
+	}
+	if cache.Size() != 0 {
+		t.Fatalf("expected an empty cache, got %v bytes", cache.Size())
+	}
+}
+
+func Test_EvictsLeastRecentlyUsed(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 20)
+
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/a"))
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/b"))
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/a"))
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/c"))
+
+	if cache.Size() != 20 {
+		t.Fatalf("expected 20 bytes, got %v", cache.Size())
+	}
+
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/a"))
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/b"))
+	if n := server.count("/a"); n != 1 {
+		t.Errorf("expected /a to remain cached, got %v downloads", n)
+	}
+	if n := server.count("/b"); n != 2 {
+		t.Errorf("expected /b to be evicted, got %v downloads", n)
+	}
+}
+
+func Test_DoesNotEvictMediaInUse(t *testing.T) {
+	server := newMediaServer(t)
+	cache := mustOpen(t, t.TempDir(), 10)
+
+	a := mustAcquire(t, cache, server.URL+"/a")
+	b := mustAcquire(t, cache, server.URL+"/b")
+	if cache.Size() != 20 {
+		t.Fatalf("expected 20 bytes, got %v", cache.Size())
+	}
+	if _, err := ioutil.ReadFile(a.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	mustRelease(t, a)
+	if cache.Size() != 10 {
+		t.Fatalf("expected 10 bytes, got %v", cache.Size())
+	}
+	mustRelease(t, b)
+}
+
+func Test_OpenRetainsMedia(t *testing.T) {
+	server := newMediaServer(t)
+	dir := t.TempDir()
+
+	cache := mustOpen(t, dir, 100)
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/a"))
+
+	cache = mustOpen(t, dir, 100)
+	if cache.Size() != 10 {
+		t.Fatalf("expected 10 bytes, got %v", cache.Size())
+	}
+	mustRelease(t, mustAcquire(t, cache, server.URL+"/a"))
+	if n := server.count("/a"); n != 1 {
+		t.Fatalf("expected 1 download, got %v", n)
+	}
+}
@@ -7,6 +7,11 @@ import "github.com/streadway/amqp"
 type Acknowledger struct {
 	channel *amqp.Channel
 	tag     uint64
+
+	// If set, negatively acknowledged messages are retried or dead-lettered
+	// according to the queue's MaxDeliveryAttempts.
+	queue    *QueueConfig
+	delivery *amqp.Delivery
 }
 
 // NewAcknowledger returns an instance of an acknowledger associated with a
@@ -18,12 +23,32 @@ func NewAcknowledger(channel *amqp.Channel, tag uint64) *Acknowledger {
 	}
 }
 
+// newDeliveryAcknowledger returns an acknowledger for a delivery from the
+// supplied queue.
+func newDeliveryAcknowledger(channel *amqp.Channel, queue *QueueConfig, delivery *amqp.Delivery) *Acknowledger {
+	return &Acknowledger{
+		channel:  channel,
+		tag:      delivery.DeliveryTag,
+		queue:    queue,
+		delivery: delivery,
+	}
+}
+
 // Ack acknowledges that a message has been received.
 func (a *Acknowledger) Ack() error {
 	return a.channel.Ack(a.tag, false)
 }
 
 // Nack negatively acknowledges that a message has been received.
+//
+// If the queue has a MaxDeliveryAttempts, a requeued message is republished
+// to the back of the queue with its delivery attempt count incremented, and
+// once the count reaches the maximum, the message is moved to the queue's
+// dead-letter queue instead. A message that is not requeued is moved to the
+// dead-letter queue immediately.
 func (a *Acknowledger) Nack(requeue bool) error {
-	return a.channel.Nack(a.tag, false, requeue)
+	if a.queue == nil || a.queue.MaxDeliveryAttempts == 0 {
+		return a.channel.Nack(a.tag, false, requeue)
+	}
+	return a.retryOrDeadLetter(requeue)
 }
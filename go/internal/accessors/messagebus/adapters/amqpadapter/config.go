@@ -0,0 +1,294 @@
+package amqpadapter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Config is a configuration for a connection to an AMQP broker.
+type Config struct {
+	// URL is the address of the broker, such as amqp://localhost:5672/ or
+	// amqps://broker.example.com/. The URL must not contain credentials; they
+	// are supplied via User and one of the password fields below. The path of
+	// the URL is used as the vhost unless Vhost is set.
+	URL string
+
+	// Vhost overrides the vhost parsed from URL.
+	Vhost string
+
+	// User is the username presented to the broker. If UserEnv is set, the
+	// username is instead read from the named environment variable.
+	User    string
+	UserEnv string
+
+	// At most one of Password, PasswordEnv, or PasswordFile may be set.
+	// PasswordEnv names an environment variable that holds the password.
+	// PasswordFile is the path of a file that holds the password; leading and
+	// trailing whitespace is ignored.
+	Password     string
+	PasswordEnv  string
+	PasswordFile string
+
+	// TLS configures the TLS transport. This is only permitted (and is
+	// optional) when URL uses the amqps scheme.
+	TLS *TLSConfig
+
+	// Heartbeat is the interval at which heartbeats are exchanged with the
+	// broker. If zero, the interval proposed by the broker is used.
+	Heartbeat time.Duration
+
+	// ConnectionName is advertised to the broker so that the connection can be
+	// identified in the broker's management tools.
+	ConnectionName string
+
+	// Reconnect is the backoff between attempts to re-establish a lost
+	// connection. If nil, DefaultBackoff is used.
+	Reconnect *Backoff
+}
+
+// TLSConfig is a TLS configuration for a connection to an AMQP broker.
+type TLSConfig struct {
+	// CAFile is the path of a PEM encoded bundle of certificate authorities
+	// used to verify the broker. If empty, the host's root CAs are used.
+	CAFile string
+
+	// CertFile and KeyFile are the paths of a PEM encoded client certificate
+	// and key. Both or neither must be set.
+	CertFile string
+	KeyFile  string
+
+	// ServerName overrides the hostname used to verify the broker's
+	// certificate.
+	ServerName string
+
+	// InsecureSkipVerify disables verification of the broker's certificate.
+	// This should only be used for testing.
+	InsecureSkipVerify bool
+}
+
+// Validate reports whether the configuration is complete and internally
+// consistent. Validate does not read credentials from the environment or
+// filesystem.
+func (c *Config) Validate() error {
+	if c.URL == "" {
+		return errors.New("amqp config: URL is required")
+	}
+
+	uri, err := amqp.ParseURI(c.URL)
+	if err != nil {
+		return fmt.Errorf("amqp config: invalid URL %q: %v", redactURL(c.URL), err)
+	}
+	if strings.Contains(c.URL, "@") {
+		return fmt.Errorf("amqp config: URL %q must not contain credentials; use User and Password instead", redactURL(c.URL))
+	}
+
+	if c.User != "" && c.UserEnv != "" {
+		return errors.New("amqp config: only one of User or UserEnv may be set")
+	}
+
+	passwordSources := 0
+	for _, source := range []string{c.Password, c.PasswordEnv, c.PasswordFile} {
+		if source != "" {
+			passwordSources++
+		}
+	}
+	if passwordSources > 1 {
+		return errors.New("amqp config: only one of Password, PasswordEnv, or PasswordFile may be set")
+	}
+
+	if c.Heartbeat < 0 {
+		return fmt.Errorf("amqp config: Heartbeat must not be negative, got %v", c.Heartbeat)
+	}
+
+	if c.Reconnect != nil && (c.Reconnect.Initial <= 0 || c.Reconnect.Max < c.Reconnect.Initial) {
+		return fmt.Errorf("amqp config: Reconnect must have a positive Initial no greater than Max, got %+v", *c.Reconnect)
+	}
+
+	if c.TLS != nil {
+		if uri.Scheme != "amqps" {
+			return fmt.Errorf("amqp config: TLS settings require an amqps URL, got %q", redactURL(c.URL))
+		}
+		if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+			return errors.New("amqp config: TLS CertFile and KeyFile must be set together")
+		}
+	}
+
+	return nil
+}
+
+// dial validates the configuration, resolves credentials, and opens a
+// connection to the broker. Any secret that appears in an error returned by
+// dial is redacted.
+func (c *Config) dial() (*amqp.Connection, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	user, password, err := c.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	amqpConfig, err := c.amqpConfig(user, password)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := amqp.DialConfig(c.URL, *amqpConfig)
+	if err != nil {
+		return nil, redactError(fmt.Errorf("failed to connect to %v: %w", redactURL(c.URL), err), password)
+	}
+
+	return conn, nil
+}
+
+func (c *Config) credentials() (string, string, error) {
+	user := c.User
+	if c.UserEnv != "" {
+		user = os.Getenv(c.UserEnv)
+		if user == "" {
+			return "", "", fmt.Errorf("amqp config: environment variable %v is not set", c.UserEnv)
+		}
+	}
+
+	password := c.Password
+	switch {
+	case c.PasswordEnv != "":
+		password = os.Getenv(c.PasswordEnv)
+		if password == "" {
+			return "", "", fmt.Errorf("amqp config: environment variable %v is not set", c.PasswordEnv)
+		}
+	case c.PasswordFile != "":
+		content, err := ioutil.ReadFile(c.PasswordFile)
+		if err != nil {
+			return "", "", fmt.Errorf("amqp config: unable to read password file: %w", err)
+		}
+		password = strings.TrimSpace(string(content))
+	}
+
+	return user, password, nil
+}
+
+func (c *Config) amqpConfig(user, password string) (*amqp.Config, error) {
+	uri, err := amqp.ParseURI(c.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	vhost := uri.Vhost
+	if c.Vhost != "" {
+		vhost = c.Vhost
+	}
+
+	amqpConfig := &amqp.Config{
+		Vhost:     vhost,
+		Heartbeat: c.Heartbeat,
+		Locale:    "en_US",
+	}
+
+	// An empty user falls back to the default credentials of the URL, which
+	// is guest/guest.
+	if user != "" {
+		amqpConfig.SASL = []amqp.Authentication{&amqp.PlainAuth{
+			Username: user,
+			Password: password,
+		}}
+	}
+
+	if c.ConnectionName != "" {
+		amqpConfig.Properties = amqp.Table{
+			"connection_name": c.ConnectionName,
+		}
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = uri.Host
+		}
+		amqpConfig.TLSClientConfig = tlsConfig
+	}
+
+	return amqpConfig, nil
+}
+
+func (t *TLSConfig) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("amqp config: unable to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("amqp config: no certificates found in CA file %v", t.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("amqp config: unable to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+const redacted = "xxxxx"
+
+// redactURL returns the supplied URL with any password replaced. Unparseable
+// URLs are redacted entirely, since we can't tell which part is secret.
+func redactURL(rawURL string) string {
+	uri, err := amqp.ParseURI(rawURL)
+	if err != nil {
+		return redacted
+	}
+	if strings.Contains(rawURL, "@") && uri.Password != "" {
+		return strings.Replace(rawURL, ":"+uri.Password+"@", ":"+redacted+"@", 1)
+	}
+	return rawURL
+}
+
+// redactedError wraps an error, replacing any occurrence of a secret in its
+// message. The wrapped error remains available via errors.Unwrap.
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func redactError(err error, secret string) error {
+	if err == nil || secret == "" {
+		return err
+	}
+	return &redactedError{
+		err:    err,
+		secret: secret,
+	}
+}
+
+func (r *redactedError) Error() string {
+	return strings.ReplaceAll(r.err.Error(), r.secret, redacted)
+}
+
+func (r *redactedError) Unwrap() error {
+	return r.err
+}
@@ -0,0 +1,94 @@
+package amqpadapter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// DefaultConfirmTimeout is used if a QueueConfig enables ConfirmPublishes
+// without specifying a ConfirmTimeout.
+const DefaultConfirmTimeout = 30 * time.Second
+
+// ErrConfirmTimeout is returned by Send if the broker does not confirm a
+// publish within the queue's ConfirmTimeout. The message may or may not have
+// been enqueued.
+var ErrConfirmTimeout = errors.New("timed out waiting for the broker to confirm a publish")
+
+// NackedPublishError is returned by Send if the broker negatively
+// acknowledges a publish, which indicates that the broker was unable to
+// enqueue the message.
+type NackedPublishError struct {
+	Queue       string
+	DeliveryTag uint64
+}
+
+func (e *NackedPublishError) Error() string {
+	return fmt.Sprintf("broker rejected publish %v to queue %v", e.DeliveryTag, e.Queue)
+}
+
+// confirmTracker pairs publishes on a channel in confirm mode with the
+// broker's confirmations. Publishes are serialized so that each publish can
+// wait for its own confirmation.
+type confirmTracker struct {
+	mu            sync.Mutex
+	confirmations <-chan amqp.Confirmation
+	lastTag       uint64
+}
+
+// enableConfirms puts the channel into confirm mode.
+func enableConfirms(ch *amqp.Channel) (*confirmTracker, error) {
+	err := ch.Confirm(false)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to enable publisher confirms: %w", err)
+	}
+	return &confirmTracker{
+		confirmations: ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+	}, nil
+}
+
+// publish publishes a message via the channel, and waits for the broker to
+// confirm it. The broker numbers publishes on a channel from 1, and a
+// confirmation that arrives after its publish timed out is discarded.
+func (c *confirmTracker) publish(ch *amqp.Channel, queue *QueueConfig, publishing amqp.Publishing) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := ch.Publish("", queue.Name, false, false, publishing)
+	if err != nil {
+		return err
+	}
+	c.lastTag++
+	tag := c.lastTag
+
+	timeout := queue.ConfirmTimeout
+	if timeout == 0 {
+		timeout = DefaultConfirmTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case confirmation, open := <-c.confirmations:
+			if !open {
+				return fmt.Errorf("channel closed before publish %v was confirmed: %w", tag, ErrDisconnected)
+			}
+			if confirmation.DeliveryTag < tag {
+				continue
+			}
+			if !confirmation.Ack {
+				return &NackedPublishError{
+					Queue:       queue.Name,
+					DeliveryTag: tag,
+				}
+			}
+			return nil
+		case <-timer.C:
+			return ErrConfirmTimeout
+		}
+	}
+}
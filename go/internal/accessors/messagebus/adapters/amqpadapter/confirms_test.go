@@ -0,0 +1,27 @@
+package amqpadapter_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+)
+
+func Test_NackedPublishErrorIsDistinguishable(t *testing.T) {
+	err := fmt.Errorf("sending completed research: %w", &amqpadapter.NackedPublishError{
+		Queue:       "completed_research",
+		DeliveryTag: 3,
+	})
+
+	var nacked *amqpadapter.NackedPublishError
+	if !errors.As(err, &nacked) {
+		t.Fatalf("expected a NackedPublishError, got %v", err)
+	}
+	if nacked.Queue != "completed_research" || nacked.DeliveryTag != 3 {
+		t.Errorf("unexpected error contents %+v", nacked)
+	}
+	if errors.Is(err, amqpadapter.ErrConfirmTimeout) {
+		t.Error("expected a nacked publish to be distinct from a confirm timeout")
+	}
+}
@@ -0,0 +1,300 @@
+package amqpadapter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/streadway/amqp"
+)
+
+// Message headers used to track delivery attempts and dead-lettering.
+const (
+	// HeaderDeliveryAttempts is the number of times a message has been
+	// delivered and negatively acknowledged.
+	HeaderDeliveryAttempts = "x-tbtl-delivery-attempts"
+
+	// HeaderOriginalQueue is the queue from which a message was
+	// dead-lettered.
+	HeaderOriginalQueue = "x-tbtl-original-queue"
+
+	// HeaderDeadLetterReason describes why a message was dead-lettered.
+	HeaderDeadLetterReason = "x-tbtl-dead-letter-reason"
+
+	// HeaderDeadLetteredAt is the time at which a message was dead-lettered.
+	HeaderDeadLetteredAt = "x-tbtl-dead-lettered-at"
+)
+
+// Reasons recorded in HeaderDeadLetterReason.
+const (
+	ReasonMaxDeliveryAttempts = "maximum delivery attempts reached"
+	ReasonRejected            = "rejected"
+)
+
+// DeadLetterQueueName returns the name of the queue to which messages from
+// the named queue are dead-lettered.
+func DeadLetterQueueName(queueName string) string {
+	return queueName + ".dead"
+}
+
+// declareDeadLetterQueue declares the dead-letter queue for the named queue.
+// Dead-letter queues are always durable so that poison messages survive a
+// broker restart.
+func declareDeadLetterQueue(ch *amqp.Channel, queueName string) error {
+	_, err := ch.QueueDeclare(
+		DeadLetterQueueName(queueName), // name
+		true,                           // durable
+		false,                          // delete when unused
+		false,                          // exclusive
+		false,                          // no-wait
+		nil,                            // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to declare a dead-letter queue: %w", err)
+	}
+	return nil
+}
+
+// deliveryAttempts returns the number of failed delivery attempts recorded
+// in the supplied headers.
+func deliveryAttempts(headers amqp.Table) int {
+	return intHeader(headers, HeaderDeliveryAttempts)
+}
+
+// copyHeaders returns a copy of the supplied headers that is safe to modify.
+func copyHeaders(headers amqp.Table) amqp.Table {
+	headersCopy := amqp.Table{}
+	for key, value := range headers {
+		headersCopy[key] = value
+	}
+	return headersCopy
+}
+
+// retryOrDeadLetter republishes the delivery either to the back of its queue
+// or to the dead-letter queue, and then acknowledges the original delivery.
+// If the process stops between the publish and the acknowledgement, the
+// message may be delivered twice.
+func (a *Acknowledger) retryOrDeadLetter(requeue bool) error {
+	attempts := deliveryAttempts(a.delivery.Headers) + 1
+
+	headers := copyHeaders(a.delivery.Headers)
+	headers[HeaderDeliveryAttempts] = int64(attempts)
+
+	routingKey := a.queue.Name
+	deliveryMode := a.queue.deliveryMode()
+	messageID := a.delivery.MessageId
+	if !requeue || attempts >= a.queue.MaxDeliveryAttempts {
+		routingKey = DeadLetterQueueName(a.queue.Name)
+		deliveryMode = amqp.Persistent
+		headers[HeaderOriginalQueue] = a.queue.Name
+		headers[HeaderDeadLetteredAt] = time.Now().UTC()
+		headers[HeaderDeadLetterReason] = ReasonMaxDeliveryAttempts
+		if !requeue {
+			headers[HeaderDeadLetterReason] = ReasonRejected
+		}
+		if messageID == "" {
+			messageID = uuid.New().String()
+		}
+	}
+
+	err := a.channel.Publish(
+		"",         // exchange
+		routingKey, // routing key
+		false,      // mandatory
+		false,      // immediate
+		amqp.Publishing{
+			Headers:      headers,
+			ContentType:  a.delivery.ContentType,
+			DeliveryMode: deliveryMode,
+			MessageId:    messageID,
+			Timestamp:    a.delivery.Timestamp,
+			Type:         a.delivery.Type,
+			Body:         a.delivery.Body,
+		})
+	if err != nil {
+		// The original delivery is left unacknowledged, so the broker will
+		// redeliver it once the channel closes.
+		return fmt.Errorf("Failed to republish a message: %w", err)
+	}
+
+	return a.channel.Ack(a.tag, false)
+}
+
+// A DeadLetter is a message that was moved to a dead-letter queue.
+type DeadLetter struct {
+	ID             string
+	OriginalQueue  string
+	Reason         string
+	Attempts       int
+	DeadLetteredAt time.Time
+	Envelope       messagebustypes.Envelope
+	Body           []byte
+}
+
+func newDeadLetter(delivery *amqp.Delivery) *DeadLetter {
+	originalQueue, _ := delivery.Headers[HeaderOriginalQueue].(string)
+	reason, _ := delivery.Headers[HeaderDeadLetterReason].(string)
+	deadLetteredAt, _ := delivery.Headers[HeaderDeadLetteredAt].(time.Time)
+	return &DeadLetter{
+		ID:             delivery.MessageId,
+		OriginalQueue:  originalQueue,
+		Reason:         reason,
+		Attempts:       deliveryAttempts(delivery.Headers),
+		DeadLetteredAt: deadLetteredAt,
+		Envelope:       newEnvelope(delivery),
+		Body:           delivery.Body,
+	}
+}
+
+// DeadLetterQueue provides access to the messages that were dead-lettered
+// from a queue. This should be instantiated via OpenDeadLetterQueue.
+type DeadLetterQueue struct {
+	conn      *amqp.Connection
+	channel   *amqp.Channel
+	queueName string
+}
+
+// OpenDeadLetterQueue connects to the broker described by config and opens
+// the dead-letter queue for the named queue. The caller must call Close once
+// the queue is no longer needed.
+func OpenDeadLetterQueue(config *Config, queueName string) (*DeadLetterQueue, error) {
+	conn, err := config.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Failed to open a channel: %w", err)
+	}
+
+	err = declareDeadLetterQueue(ch, queueName)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &DeadLetterQueue{
+		conn:      conn,
+		channel:   ch,
+		queueName: queueName,
+	}, nil
+}
+
+// Close closes the connection to the broker.
+func (d *DeadLetterQueue) Close() error {
+	return d.conn.Close()
+}
+
+// List returns every message in the dead-letter queue, oldest first. The
+// messages remain in the queue.
+func (d *DeadLetterQueue) List() ([]*DeadLetter, error) {
+	deadLetters := []*DeadLetter{}
+	err := d.drain(func(delivery *amqp.Delivery) (bool, error) {
+		deadLetters = append(deadLetters, newDeadLetter(delivery))
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
+
+// Replay moves the messages with the supplied IDs from the dead-letter queue
+// back to the queue from which they were dead-lettered, with their delivery
+// attempt count reset. If no IDs are supplied, every message is replayed. The
+// number of replayed messages is returned.
+func (d *DeadLetterQueue) Replay(ids ...string) (int, error) {
+	selected := map[string]bool{}
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	replayed := 0
+	err := d.drain(func(delivery *amqp.Delivery) (bool, error) {
+		if len(selected) > 0 && !selected[delivery.MessageId] {
+			return false, nil
+		}
+
+		originalQueue, _ := delivery.Headers[HeaderOriginalQueue].(string)
+		if originalQueue == "" {
+			originalQueue = d.queueName
+		}
+		headers := copyHeaders(delivery.Headers)
+		delete(headers, HeaderDeliveryAttempts)
+		delete(headers, HeaderOriginalQueue)
+		delete(headers, HeaderDeadLetterReason)
+		delete(headers, HeaderDeadLetteredAt)
+
+		err := d.channel.Publish(
+			"",            // exchange
+			originalQueue, // routing key
+			false,         // mandatory
+			false,         // immediate
+			amqp.Publishing{
+				Headers:      headers,
+				ContentType:  delivery.ContentType,
+				DeliveryMode: delivery.DeliveryMode,
+				MessageId:    delivery.MessageId,
+				Timestamp:    delivery.Timestamp,
+				Type:         delivery.Type,
+				Body:         delivery.Body,
+			})
+		if err != nil {
+			return false, fmt.Errorf("Failed to replay message %v: %w", delivery.MessageId, err)
+		}
+		replayed++
+		return true, nil
+	})
+	return replayed, err
+}
+
+// drain fetches each message that is currently in the dead-letter queue and
+// passes it to visit. Messages for which visit returns true are
+// acknowledged, which removes them from the queue. All other messages are
+// returned to the queue once every message has been visited, or if an error
+// occurs.
+func (d *DeadLetterQueue) drain(visit func(*amqp.Delivery) (bool, error)) error {
+	deadQueueName := DeadLetterQueueName(d.queueName)
+	info, err := d.channel.QueueInspect(deadQueueName)
+	if err != nil {
+		return err
+	}
+
+	var lastTag uint64
+	unacked := false
+	defer func() {
+		if unacked {
+			d.channel.Nack(lastTag, true, true)
+		}
+	}()
+
+	for i := 0; i < info.Messages; i++ {
+		delivery, ok, err := d.channel.Get(deadQueueName, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		lastTag = delivery.DeliveryTag
+
+		remove, err := visit(&delivery)
+		if err != nil {
+			unacked = true
+			return err
+		}
+		if remove {
+			err = d.channel.Ack(delivery.DeliveryTag, false)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		unacked = true
+	}
+
+	return nil
+}
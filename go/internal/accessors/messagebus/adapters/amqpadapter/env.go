@@ -0,0 +1,94 @@
+package amqpadapter
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	// URLEnv holds the address of the broker. If unset, DefaultURL is used.
+	URLEnv = "AMQP_URL"
+
+	// VhostEnv, if set, overrides the vhost parsed from the URL.
+	VhostEnv = "AMQP_VHOST"
+
+	// UserEnv holds the username presented to the broker. If unset, the
+	// broker's default credentials are used.
+	UserEnv = "AMQP_USER"
+
+	// PasswordEnv holds the password presented to the broker. Alternatively,
+	// PasswordFileEnv holds the path of a file that contains the password.
+	PasswordEnv     = "AMQP_PASSWORD"
+	PasswordFileEnv = "AMQP_PASSWORD_FILE"
+
+	// CAFileEnv, CertFileEnv, KeyFileEnv, and ServerNameEnv configure TLS (see
+	// TLSConfig). Setting any of them requires an amqps URL.
+	CAFileEnv     = "AMQP_CA_FILE"
+	CertFileEnv   = "AMQP_CERT_FILE"
+	KeyFileEnv    = "AMQP_KEY_FILE"
+	ServerNameEnv = "AMQP_SERVER_NAME"
+
+	// HeartbeatEnv holds the heartbeat interval, such as 10s. If unset,
+	// DefaultHeartbeat is used.
+	HeartbeatEnv = "AMQP_HEARTBEAT"
+)
+
+// DefaultURL is the address of a broker running on the local host.
+const DefaultURL = "amqp://localhost:5672/"
+
+// DefaultHeartbeat is the heartbeat interval used by ConfigFromEnv if
+// HeartbeatEnv isn't set.
+const DefaultHeartbeat = 10 * time.Second
+
+// ConfigFromEnv returns a configuration for a connection, identified to the
+// broker as connectionName, that is read from the environment variables
+// above. Credentials aren't read until the connection is opened, so they
+// never appear in the returned configuration.
+func ConfigFromEnv(connectionName string) (*Config, error) {
+	config := &Config{
+		URL:            DefaultURL,
+		Vhost:          os.Getenv(VhostEnv),
+		Heartbeat:      DefaultHeartbeat,
+		ConnectionName: connectionName,
+	}
+
+	if url := os.Getenv(URLEnv); url != "" {
+		config.URL = url
+	}
+
+	if os.Getenv(UserEnv) != "" {
+		config.UserEnv = UserEnv
+	}
+
+	if os.Getenv(PasswordEnv) != "" {
+		config.PasswordEnv = PasswordEnv
+	}
+	config.PasswordFile = os.Getenv(PasswordFileEnv)
+
+	tlsConfig := &TLSConfig{
+		CAFile:     os.Getenv(CAFileEnv),
+		CertFile:   os.Getenv(CertFileEnv),
+		KeyFile:    os.Getenv(KeyFileEnv),
+		ServerName: os.Getenv(ServerNameEnv),
+	}
+	if *tlsConfig != (TLSConfig{}) {
+		config.TLS = tlsConfig
+	}
+
+	if heartbeat := os.Getenv(HeartbeatEnv); heartbeat != "" {
+		interval, err := time.ParseDuration(heartbeat)
+		if err != nil {
+			return nil, fmt.Errorf("amqp config: invalid %v: %v", HeartbeatEnv, err)
+		}
+		config.Heartbeat = interval
+	}
+
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,140 @@
+package amqpadapter_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+)
+
+// setEnv sets each of the supplied environment variables, and unsets the rest
+// of those read by ConfigFromEnv, until the test finishes.
+func setEnv(t *testing.T, env map[string]string) {
+	names := []string{
+		amqpadapter.URLEnv,
+		amqpadapter.VhostEnv,
+		amqpadapter.UserEnv,
+		amqpadapter.PasswordEnv,
+		amqpadapter.PasswordFileEnv,
+		amqpadapter.CAFileEnv,
+		amqpadapter.CertFileEnv,
+		amqpadapter.KeyFileEnv,
+		amqpadapter.ServerNameEnv,
+		amqpadapter.HeartbeatEnv,
+	}
+	for _, name := range names {
+		name := name
+		original, found := os.LookupEnv(name)
+		t.Cleanup(func() {
+			if found {
+				os.Setenv(name, original)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+		if value, set := env[name]; set {
+			os.Setenv(name, value)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func Test_ConfigFromEnv(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected *amqpadapter.Config
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{},
+			expected: &amqpadapter.Config{
+				URL:            amqpadapter.DefaultURL,
+				Heartbeat:      amqpadapter.DefaultHeartbeat,
+				ConnectionName: "test",
+			},
+		},
+		{
+			name: "complete",
+			env: map[string]string{
+				amqpadapter.URLEnv:        "amqps://broker.example.com/",
+				amqpadapter.VhostEnv:      "archivist",
+				amqpadapter.UserEnv:       "archivist",
+				amqpadapter.PasswordEnv:   "s3cret",
+				amqpadapter.CAFileEnv:     "ca.pem",
+				amqpadapter.ServerNameEnv: "broker",
+				amqpadapter.HeartbeatEnv:  "30s",
+				amqpadapter.CertFileEnv:   "cert.pem",
+				amqpadapter.KeyFileEnv:    "key.pem",
+			},
+			expected: &amqpadapter.Config{
+				URL:         "amqps://broker.example.com/",
+				Vhost:       "archivist",
+				UserEnv:     amqpadapter.UserEnv,
+				PasswordEnv: amqpadapter.PasswordEnv,
+				TLS: &amqpadapter.TLSConfig{
+					CAFile:     "ca.pem",
+					CertFile:   "cert.pem",
+					KeyFile:    "key.pem",
+					ServerName: "broker",
+				},
+				Heartbeat:      30 * time.Second,
+				ConnectionName: "test",
+			},
+		},
+		{
+			name: "password file",
+			env: map[string]string{
+				amqpadapter.UserEnv:         "archivist",
+				amqpadapter.PasswordFileEnv: "/run/secrets/amqp",
+			},
+			expected: &amqpadapter.Config{
+				URL:            amqpadapter.DefaultURL,
+				UserEnv:        amqpadapter.UserEnv,
+				PasswordFile:   "/run/secrets/amqp",
+				Heartbeat:      amqpadapter.DefaultHeartbeat,
+				ConnectionName: "test",
+			},
+		},
+		{
+			name: "multiple passwords",
+			env: map[string]string{
+				amqpadapter.PasswordEnv:     "s3cret",
+				amqpadapter.PasswordFileEnv: "/run/secrets/amqp",
+			},
+		},
+		{
+			name: "invalid heartbeat",
+			env:  map[string]string{amqpadapter.HeartbeatEnv: "often"},
+		},
+		{
+			name: "tls without amqps",
+			env:  map[string]string{amqpadapter.CAFileEnv: "ca.pem"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setEnv(t, testCase.env)
+			config, err := amqpadapter.ConfigFromEnv("test")
+			if testCase.expected == nil {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(config, testCase.expected) {
+				t.Fatalf("expected %+v, got %+v", testCase.expected, config)
+			}
+			if config.Password != "" {
+				t.Fatal("expected the password not to be read into the config")
+			}
+		})
+	}
+}
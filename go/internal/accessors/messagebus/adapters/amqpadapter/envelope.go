@@ -0,0 +1,81 @@
+package amqpadapter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/streadway/amqp"
+)
+
+// ReservedHeaderPrefix is the prefix of the headers that the adapter uses for
+// its own bookkeeping. Senders may not supply headers with this prefix, and
+// these headers are not included in a received message's envelope.
+const ReservedHeaderPrefix = "x-tbtl-"
+
+// HeaderSchemaVersion carries an envelope's SchemaVersion.
+const HeaderSchemaVersion = ReservedHeaderPrefix + "schema-version"
+
+// newPublishing maps an envelope and body onto an AMQP publishing. The
+// envelope's missing fields are supplied via WithDefaults.
+func newPublishing(envelope *messagebustypes.Envelope, body []byte, deliveryMode uint8) (amqp.Publishing, error) {
+	envelope = envelope.WithDefaults()
+
+	headers := amqp.Table{}
+	for key, value := range envelope.Headers {
+		if strings.HasPrefix(key, ReservedHeaderPrefix) {
+			return amqp.Publishing{}, fmt.Errorf("header %v uses the reserved prefix %v", key, ReservedHeaderPrefix)
+		}
+		headers[key] = value
+	}
+	if envelope.SchemaVersion != 0 {
+		headers[HeaderSchemaVersion] = int64(envelope.SchemaVersion)
+	}
+
+	return amqp.Publishing{
+		Headers:      headers,
+		ContentType:  envelope.ContentType,
+		DeliveryMode: deliveryMode,
+		MessageId:    envelope.MessageID,
+		Timestamp:    envelope.Timestamp.UTC(),
+		Type:         envelope.MessageType,
+		Body:         body,
+	}, nil
+}
+
+// newEnvelope maps an AMQP delivery onto an envelope. Only string valued
+// headers without the reserved prefix are included in the envelope's
+// headers.
+func newEnvelope(delivery *amqp.Delivery) messagebustypes.Envelope {
+	headers := map[string]string{}
+	for key, value := range delivery.Headers {
+		stringValue, isString := value.(string)
+		if isString && !strings.HasPrefix(key, ReservedHeaderPrefix) {
+			headers[key] = stringValue
+		}
+	}
+
+	return messagebustypes.Envelope{
+		Headers:       headers,
+		ContentType:   delivery.ContentType,
+		MessageType:   delivery.Type,
+		SchemaVersion: intHeader(delivery.Headers, HeaderSchemaVersion),
+		MessageID:     delivery.MessageId,
+		Timestamp:     delivery.Timestamp,
+	}
+}
+
+// intHeader returns the value of an integer header, or zero if the header
+// isn't present.
+func intHeader(headers amqp.Table, key string) int {
+	switch value := headers[key].(type) {
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,128 @@
+package amqpadapter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// QueueConfig is a configuration for a queue. Note that the broker refuses to
+// redeclare an existing queue with different Durable or argument settings, so
+// an existing queue must be deleted before its configuration can be changed.
+type QueueConfig struct {
+	Name string
+
+	// Durable queues survive a broker restart.
+	Durable bool
+
+	// Persistent messages are written to disk by the broker, and survive a
+	// broker restart if they're in a durable queue.
+	Persistent bool
+
+	// MessageTTL is the length of time a message may wait in the queue before
+	// it's discarded. If zero, messages don't expire. The broker's resolution
+	// is one millisecond.
+	MessageTTL time.Duration
+
+	// MaxLength is the maximum number of ready messages in the queue. If zero,
+	// the queue's length is unbounded.
+	MaxLength int
+
+	// Overflow determines how the broker behaves once MaxLength is reached.
+	// It must be empty (which the broker treats as "drop-head"),
+	// "drop-head", "reject-publish", or "reject-publish-dlx".
+	Overflow string
+
+	// ConfirmPublishes puts the sending channel into confirm mode, so that
+	// Send waits for the broker to confirm each message.
+	ConfirmPublishes bool
+
+	// ConfirmTimeout is the length of time Send waits for a confirmation. If
+	// zero, DefaultConfirmTimeout is used.
+	ConfirmTimeout time.Duration
+
+	// MaxDeliveryAttempts is the number of times a received message may be
+	// negatively acknowledged before it's moved to the queue's dead-letter
+	// queue (see DeadLetterQueueName). If zero, messages are requeued
+	// indefinitely and rejected messages are discarded.
+	MaxDeliveryAttempts int
+}
+
+const completedResearchQueueName = "completed_research"
+
+// DefaultMaxDeliveryAttempts is the MaxDeliveryAttempts used by
+// DefaultQueueConfig.
+const DefaultMaxDeliveryAttempts = 5
+
+// DefaultQueueConfig returns the configuration used by the hosts for the
+// named queue. The completed-research queue is durable, its messages are
+// persistent, and publishes to it are confirmed, because completed research
+// can take minutes per episode to reproduce. Other queues are transient,
+// since their contents are regenerated from the datastore. Every queue
+// dead-letters messages after DefaultMaxDeliveryAttempts.
+func DefaultQueueConfig(name string) *QueueConfig {
+	durable := name == completedResearchQueueName
+	return &QueueConfig{
+		Name:                name,
+		Durable:             durable,
+		Persistent:          durable,
+		ConfirmPublishes:    durable,
+		MaxDeliveryAttempts: DefaultMaxDeliveryAttempts,
+	}
+}
+
+// Validate reports whether the queue configuration is complete and
+// internally consistent.
+func (q *QueueConfig) Validate() error {
+	if q.Name == "" {
+		return errors.New("queue config: Name is required")
+	}
+	if q.MessageTTL < 0 || q.MessageTTL%time.Millisecond != 0 {
+		return fmt.Errorf("queue config: MessageTTL must be a non-negative number of milliseconds, got %v", q.MessageTTL)
+	}
+	if q.MaxLength < 0 {
+		return fmt.Errorf("queue config: MaxLength must not be negative, got %v", q.MaxLength)
+	}
+	switch q.Overflow {
+	case "", "drop-head", "reject-publish", "reject-publish-dlx":
+	default:
+		return fmt.Errorf("queue config: unsupported Overflow %q", q.Overflow)
+	}
+	if q.Overflow != "" && q.MaxLength == 0 {
+		return errors.New("queue config: Overflow requires MaxLength")
+	}
+	if q.MaxDeliveryAttempts < 0 {
+		return fmt.Errorf("queue config: MaxDeliveryAttempts must not be negative, got %v", q.MaxDeliveryAttempts)
+	}
+	if q.ConfirmTimeout < 0 {
+		return fmt.Errorf("queue config: ConfirmTimeout must not be negative, got %v", q.ConfirmTimeout)
+	}
+	return nil
+}
+
+// arguments returns the optional arguments with which the queue is declared.
+func (q *QueueConfig) arguments() amqp.Table {
+	args := amqp.Table{}
+	if q.MessageTTL > 0 {
+		args["x-message-ttl"] = int64(q.MessageTTL / time.Millisecond)
+	}
+	if q.MaxLength > 0 {
+		args["x-max-length"] = int64(q.MaxLength)
+	}
+	if q.Overflow != "" {
+		args["x-overflow"] = q.Overflow
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return args
+}
+
+func (q *QueueConfig) deliveryMode() uint8 {
+	if q.Persistent {
+		return amqp.Persistent
+	}
+	return amqp.Transient
+}
@@ -0,0 +1,110 @@
+package amqpadapter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+func Test_DefaultQueueConfig(t *testing.T) {
+	completed := amqpadapter.DefaultQueueConfig("completed_research")
+	if !completed.Durable || !completed.Persistent || !completed.ConfirmPublishes {
+		t.Errorf("expected the completed-research queue to be durable, persistent, and confirmed, got %+v", completed)
+	}
+
+	pending := amqpadapter.DefaultQueueConfig("pending_research")
+	if pending.Durable || pending.Persistent || pending.ConfirmPublishes {
+		t.Errorf("expected the pending-research queue to be transient, got %+v", pending)
+	}
+
+	for _, queue := range []*amqpadapter.QueueConfig{completed, pending} {
+		if queue.MaxDeliveryAttempts != amqpadapter.DefaultMaxDeliveryAttempts {
+			t.Errorf("expected %v to dead-letter messages after %v attempts, got %v", queue.Name, amqpadapter.DefaultMaxDeliveryAttempts, queue.MaxDeliveryAttempts)
+		}
+		if err := queue.Validate(); err != nil {
+			t.Errorf("expected %v to be valid, got %v", queue.Name, err)
+		}
+	}
+}
+
+func Test_QueueConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		queue amqpadapter.QueueConfig
+		valid bool
+	}{
+		{
+			name: "complete",
+			queue: amqpadapter.QueueConfig{
+				Name:       "queue",
+				Durable:    true,
+				Persistent: true,
+				MessageTTL: time.Hour,
+				MaxLength:  1000,
+				Overflow:   "reject-publish",
+			},
+			valid: true,
+		},
+		{
+			name:  "negative confirm timeout",
+			queue: amqpadapter.QueueConfig{Name: "queue", ConfirmPublishes: true, ConfirmTimeout: -time.Second},
+		},
+		{
+			name:  "negative max delivery attempts",
+			queue: amqpadapter.QueueConfig{Name: "queue", MaxDeliveryAttempts: -1},
+		},
+		{
+			name:  "missing name",
+			queue: amqpadapter.QueueConfig{},
+		},
+		{
+			name:  "negative ttl",
+			queue: amqpadapter.QueueConfig{Name: "queue", MessageTTL: -time.Second},
+		},
+		{
+			name:  "sub-millisecond ttl",
+			queue: amqpadapter.QueueConfig{Name: "queue", MessageTTL: time.Microsecond},
+		},
+		{
+			name:  "negative max length",
+			queue: amqpadapter.QueueConfig{Name: "queue", MaxLength: -1},
+		},
+		{
+			name:  "unsupported overflow",
+			queue: amqpadapter.QueueConfig{Name: "queue", MaxLength: 1, Overflow: "drop-tail"},
+		},
+		{
+			name:  "overflow without max length",
+			queue: amqpadapter.QueueConfig{Name: "queue", Overflow: "drop-head"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.queue.Validate()
+			if testCase.valid && err != nil {
+				t.Fatalf("expected queue config to be valid, got %v", err)
+			}
+			if !testCase.valid && err == nil {
+				t.Fatal("expected queue config to be invalid")
+			}
+		})
+	}
+}
+
+func Test_InitializeRejectsInvalidQueueConfig(t *testing.T) {
+	config := &amqpadapter.Config{URL: "amqp://localhost/"}
+	_, err := amqpadapter.Initialize(context.Background(), config, &amqpadapter.QueueConfig{}, messagebustypes.DirectionSendOnly)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func Test_DeadLetterQueueName(t *testing.T) {
+	if name := amqpadapter.DeadLetterQueueName("curated_clips"); name != "curated_clips.dead" {
+		t.Errorf("unexpected dead-letter queue name %v", name)
+	}
+}
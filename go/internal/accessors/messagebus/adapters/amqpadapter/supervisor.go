@@ -0,0 +1,191 @@
+package amqpadapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// EventKind identifies the type of a connection Event.
+type EventKind int
+
+const (
+	// EventDisconnected indicates that the connection or channel was closed,
+	// or that the broker cancelled the consumer.
+	EventDisconnected EventKind = iota + 1
+
+	// EventReconnectFailed indicates that an attempt to reconnect failed.
+	// Another attempt will be made after a backoff.
+	EventReconnectFailed
+
+	// EventReconnected indicates that the connection, queue, and consumer
+	// (if any) were re-established.
+	EventReconnected
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventDisconnected:
+		return "disconnected"
+	case EventReconnectFailed:
+		return "reconnect failed"
+	case EventReconnected:
+		return "reconnected"
+	default:
+		return "unknown"
+	}
+}
+
+// An Event describes a change in the state of the API's connection to the
+// broker.
+type Event struct {
+	Kind EventKind
+
+	// Attempt is the number of reconnection attempts made since the
+	// connection was lost. It is zero for EventDisconnected.
+	Attempt int
+
+	// Err is the reason the connection was lost, or the reason a
+	// reconnection attempt failed. It is nil for EventReconnected, and may be
+	// nil for EventDisconnected if the connection was closed gracefully.
+	Err error
+}
+
+func (e Event) String() string {
+	if e.Err != nil {
+		return e.Kind.String() + ": " + e.Err.Error()
+	}
+	return e.Kind.String()
+}
+
+// eventBufferSize is the number of events buffered for callers of Events.
+// Events are dropped, rather than blocking reconnection, if the buffer is
+// full.
+const eventBufferSize = 16
+
+// Events returns a channel that reports reconnect activity. The channel is
+// closed once the API is closed. Callers aren't required to read from the
+// channel; events are discarded if they aren't read.
+func (a *API) Events() <-chan Event {
+	return a.events
+}
+
+func (a *API) emit(event Event) {
+	select {
+	case a.events <- event:
+	default:
+	}
+}
+
+// Backoff describes the delays between reconnection attempts. The delay
+// starts at Initial, and doubles after each failed attempt up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// DefaultBackoff is used if a Config does not specify a Reconnect backoff.
+var DefaultBackoff = Backoff{
+	Initial: 500 * time.Millisecond,
+	Max:     30 * time.Second,
+}
+
+// Delay returns the delay before the specified reconnection attempt, where
+// the first attempt is 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// supervise waits for the current connection to fail and replaces it, until
+// ctx is done.
+func (a *API) supervise(ctx context.Context) {
+	defer a.shutdown()
+
+	for {
+		a.mu.RLock()
+		conn, ch := a.conn, a.defaultChannel
+		a.mu.RUnlock()
+
+		connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		channelClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+		cancelled := ch.NotifyCancel(make(chan string, 1))
+
+		var reason error
+		select {
+		case <-ctx.Done():
+			return
+		case amqpErr := <-connClosed:
+			if amqpErr != nil {
+				reason = amqpErr
+			}
+		case amqpErr := <-channelClosed:
+			if amqpErr != nil {
+				reason = amqpErr
+			}
+		case consumer, open := <-cancelled:
+			if open {
+				reason = &amqp.Error{Reason: "consumer " + consumer + " cancelled by the broker"}
+			}
+		}
+
+		a.mu.Lock()
+		a.connected = false
+		a.notifyStateChanged()
+		a.mu.Unlock()
+		// Closing the connection is a no-op if the broker already closed it,
+		// and releases it if only the channel or consumer was lost.
+		conn.Close()
+		a.emit(Event{Kind: EventDisconnected, Err: reason})
+
+		if !a.reconnect(ctx) {
+			return
+		}
+	}
+}
+
+// reconnect attempts to connect until it succeeds or ctx is done. It returns
+// false if ctx is done.
+func (a *API) reconnect(ctx context.Context) bool {
+	backoff := a.config.Reconnect
+	if backoff == nil {
+		backoff = &DefaultBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(backoff.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+
+		err := a.connect()
+		if err != nil {
+			a.emit(Event{Kind: EventReconnectFailed, Attempt: attempt, Err: err})
+			continue
+		}
+		a.emit(Event{Kind: EventReconnected, Attempt: attempt})
+		return true
+	}
+}
+
+func (a *API) shutdown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.closed = true
+	a.connected = false
+	a.notifyStateChanged()
+	if a.conn != nil {
+		a.conn.Close()
+	}
+	close(a.events)
+}
@@ -0,0 +1,33 @@
+package amqpadapter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/amqpadapter"
+)
+
+func Test_BackoffDelay(t *testing.T) {
+	backoff := amqpadapter.Backoff{
+		Initial: time.Second,
+		Max:     5 * time.Second,
+	}
+
+	expected := []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}
+	for i, delay := range expected {
+		attempt := i + 1
+		if actual := backoff.Delay(attempt); actual != delay {
+			t.Errorf("attempt %v: expected %v, got %v", attempt, delay, actual)
+		}
+	}
+
+	if actual := backoff.Delay(1000); actual != backoff.Max {
+		t.Errorf("expected delay to be capped at %v, got %v", backoff.Max, actual)
+	}
+}
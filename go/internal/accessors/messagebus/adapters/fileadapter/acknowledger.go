@@ -0,0 +1,48 @@
+package fileadapter
+
+import (
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/acknowledger"
+)
+
+// Acknowledger acknowledges a single message that was received from a queue.
+type Acknowledger struct {
+	api    *API
+	offset int64
+}
+
+func newAcknowledger(api *API, offset int64) *Acknowledger {
+	return &Acknowledger{
+		api:    api,
+		offset: offset,
+	}
+}
+
+// Ack acknowledges that a message has been received, which removes it from
+// the queue. The acknowledgement has been written to disk by the time this
+// method returns.
+func (a *Acknowledger) Ack() error {
+	return a.settle(false)
+}
+
+// Nack negatively acknowledges that a message has been received. If requeue
+// is true, the message is returned to the queue ahead of any message that was
+// sent after it. Otherwise it is discarded.
+func (a *Acknowledger) Nack(requeue bool) error {
+	return a.settle(requeue)
+}
+
+// settle removes the message from the messages in flight. An error is
+// returned if the message has already been acknowledged, or if it was
+// requeued because its API was closed.
+func (a *Acknowledger) settle(requeue bool) error {
+	a.api.bus.mu.Lock()
+	defer a.api.bus.mu.Unlock()
+
+	err := a.api.checkOpen()
+	if err != nil {
+		return err
+	}
+	return a.api.queue.settle(a.api, a.offset, requeue)
+}
+
+var _ acknowledger.AckNack = (*Acknowledger)(nil)
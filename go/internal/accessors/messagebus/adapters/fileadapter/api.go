@@ -0,0 +1,170 @@
+package fileadapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+// API is a connection to a single queue on a Bus. This should be
+// instantiated via Bus.Initialize.
+type API struct {
+	bus       *Bus
+	queue     *queue
+	direction messagebustypes.Direction
+	closed    bool
+}
+
+// Close releases the API. Any messages that were received by the API but
+// not yet acknowledged are returned to the queue. Once every receiving API in
+// the process is closed, the process stops consuming the queue. Calling Close
+// more than once has no effect.
+func (a *API) Close() error {
+	a.bus.mu.Lock()
+	defer a.bus.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	if a.direction.CanReceive() && !a.bus.closed {
+		a.queue.removeReceiver(a)
+	}
+	return nil
+}
+
+// checkOpen returns an error if the API or its Bus has been closed. The
+// caller must hold the bus's mutex.
+func (a *API) checkOpen() error {
+	if a.closed || a.bus.closed {
+		return messagebustypes.ErrClosed
+	}
+	return nil
+}
+
+// Send transmits a message to the queue with a default envelope. This method
+// will panic if the API was initialized as receive-only.
+func (a *API) Send(msg []byte) error {
+	return a.SendEnvelope(nil, msg)
+}
+
+// SendEnvelope transmits a message to the queue with the supplied envelope.
+// The message has been written to disk by the time this method returns. This
+// method will panic if the API was initialized as receive-only.
+func (a *API) SendEnvelope(envelope *messagebustypes.Envelope, msg []byte) error {
+	if !a.direction.CanSend() {
+		panic("Cannot send on a receive-only connection.")
+	}
+
+	a.bus.mu.Lock()
+	defer a.bus.mu.Unlock()
+
+	err := a.checkOpen()
+	if err != nil {
+		return err
+	}
+
+	err = a.queue.append(envelope.WithDefaults(), msg)
+	if err != nil {
+		return err
+	}
+	a.queue.signal()
+	return nil
+}
+
+// Inspect returns the number of messages that are waiting to be delivered
+// (not including messages that this process has received but not
+// acknowledged), and the number of consumers associated with the queue. If
+// another process is consuming the queue, it counts as a single consumer.
+func (a *API) Inspect() (*messagebustypes.QueueInfo, error) {
+	a.bus.mu.Lock()
+	defer a.bus.mu.Unlock()
+
+	err := a.checkOpen()
+	if err != nil {
+		return nil, err
+	}
+
+	messages, consumers, err := a.queue.inspect()
+	if err != nil {
+		return nil, err
+	}
+	return &messagebustypes.QueueInfo{
+		Messages:  messages,
+		Consumers: consumers,
+	}, nil
+}
+
+// Receive retrieves a message from the queue. This method does not block. If
+// no message is available the method will return nil. The message remains
+// outstanding until it is acknowledged via its Acknowledger, or until the API
+// is closed. This method will panic if the API was initialized as send-only.
+func (a *API) Receive() (*messagebustypes.Message, error) {
+	if !a.direction.CanReceive() {
+		panic("Cannot receive from a send-only connection.")
+	}
+
+	a.bus.mu.Lock()
+	defer a.bus.mu.Unlock()
+
+	msg, _, err := a.tryReceive()
+	return msg, err
+}
+
+// ReceiveContext retrieves a message from the queue, blocking until a
+// message is available or ctx is done. Messages sent by other processes are
+// noticed within the Bus's poll interval. This method will panic if the API
+// was initialized as send-only.
+func (a *API) ReceiveContext(ctx context.Context) (*messagebustypes.Message, error) {
+	if !a.direction.CanReceive() {
+		panic("Cannot receive from a send-only connection.")
+	}
+
+	ticker := time.NewTicker(a.bus.pollInterval())
+	defer ticker.Stop()
+
+	for {
+		a.bus.mu.Lock()
+		msg, changed, err := a.tryReceive()
+		a.bus.mu.Unlock()
+		if msg != nil || err != nil {
+			return msg, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// tryReceive delivers the next pending message, if any. If no message is
+// pending, a channel that is closed when the queue next changes within this
+// process is returned. The caller must hold the bus's mutex.
+func (a *API) tryReceive() (*messagebustypes.Message, <-chan struct{}, error) {
+	err := a.checkOpen()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := a.queue.next(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r == nil {
+		return nil, a.queue.changed, nil
+	}
+
+	return &messagebustypes.Message{
+		Envelope:     *r.envelope.WithDefaults(),
+		Body:         r.body,
+		Acknowledger: newAcknowledger(a, r.offset),
+	}, nil, nil
+}
+
+var _ messagebus.SenderReceiver = (*API)(nil)
@@ -0,0 +1,157 @@
+// Package fileadapter provides a message bus that persists its queues to
+// disk, so that curators, archivists, and researchers can run on a single
+// host without a message broker, and without losing messages if a process
+// crashes.
+//
+// Each queue is a directory containing an append-only log of messages, and
+// an append-only log of the offsets of the messages that the queue's
+// consumer has acknowledged. A queue may be sent to by any number of
+// processes, but may only be consumed by one process at a time. Processes
+// coordinate via advisory file locks. Once every message in a queue has been
+// acknowledged, both logs are truncated.
+package fileadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+// Config is a configuration for a file-backed message bus.
+type Config struct {
+	// Dir is the directory in which queues are stored. It's created if it
+	// doesn't exist.
+	Dir string
+
+	// PollInterval is how often a blocked ReceiveContext checks for messages
+	// sent by other processes. If zero, DefaultPollInterval is used.
+	PollInterval time.Duration
+
+	// DisableSync skips flushing each write to stable storage. This is faster,
+	// but messages may be lost or redelivered if the host (rather than the
+	// process) crashes.
+	DisableSync bool
+}
+
+// DefaultPollInterval is used if a Config doesn't specify a PollInterval.
+const DefaultPollInterval = 100 * time.Millisecond
+
+var queueNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// A Bus is a collection of queues stored in a directory. A Bus is safe for
+// concurrent use.
+type Bus struct {
+	config *Config
+
+	mu     sync.Mutex
+	queues map[string]*queue
+	closed bool
+}
+
+// Open returns a reference to a Bus that stores its queues in the configured
+// directory.
+func Open(config *Config) (*Bus, error) {
+	if config.Dir == "" {
+		return nil, errors.New("file bus config: Dir is required")
+	}
+	if config.PollInterval < 0 {
+		return nil, fmt.Errorf("file bus config: PollInterval must not be negative, got %v", config.PollInterval)
+	}
+
+	err := os.MkdirAll(config.Dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bus{
+		config: config,
+		queues: map[string]*queue{},
+	}, nil
+}
+
+// Close closes every queue that the Bus has opened. Any messages that were
+// received but not acknowledged will be redelivered once the queue is next
+// consumed.
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	var firstErr error
+	for _, q := range b.queues {
+		q.signal()
+		err := q.close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Initialize returns an API for the named queue, creating the queue if it
+// doesn't already exist. If the supplied direction permits receiving, the
+// API becomes a consumer of the queue until Close is called or ctx is
+// cancelled. An error is returned if another process is consuming the queue.
+func (b *Bus) Initialize(ctx context.Context, queueName string, direction messagebustypes.Direction) (*API, error) {
+	if err := direction.Validate(); err != nil {
+		return nil, err
+	}
+	if !queueNamePattern.MatchString(queueName) || queueName == "." || queueName == ".." {
+		return nil, fmt.Errorf("invalid queue name %q", queueName)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, messagebustypes.ErrClosed
+	}
+
+	q, found := b.queues[queueName]
+	if !found {
+		var err error
+		q, err = openQueue(filepath.Join(b.config.Dir, queueName), !b.config.DisableSync)
+		if err != nil {
+			return nil, err
+		}
+		b.queues[queueName] = q
+	}
+
+	api := &API{
+		bus:       b,
+		queue:     q,
+		direction: direction,
+	}
+
+	if api.direction.CanReceive() {
+		err := q.addReceiver()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		api.Close()
+	}()
+
+	return api, nil
+}
+
+func (b *Bus) pollInterval() time.Duration {
+	if b.config.PollInterval == 0 {
+		return DefaultPollInterval
+	}
+	return b.config.PollInterval
+}
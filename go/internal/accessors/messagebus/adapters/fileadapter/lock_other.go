@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+package fileadapter
+
+import (
+	"errors"
+	"os"
+)
+
+var errLockingUnsupported = errors.New("file locking is not supported on this platform")
+
+func lockFile(f *os.File) error {
+	return errLockingUnsupported
+}
+
+func lockFileShared(f *os.File) error {
+	return errLockingUnsupported
+}
+
+func tryLockFile(f *os.File) (bool, error) {
+	return false, errLockingUnsupported
+}
+
+func unlockFile(f *os.File) error {
+	return errLockingUnsupported
+}
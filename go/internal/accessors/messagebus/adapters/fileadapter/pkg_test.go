@@ -0,0 +1,256 @@
+package fileadapter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/fileadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustest"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+func open(t *testing.T, dir string) *fileadapter.Bus {
+	t.Helper()
+	bus, err := fileadapter.Open(&fileadapter.Config{
+		Dir:          dir,
+		PollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { bus.Close() })
+	return bus
+}
+
+func initialize(t *testing.T, bus *fileadapter.Bus, direction messagebustypes.Direction) *fileadapter.API {
+	t.Helper()
+	api, err := bus.Initialize(context.Background(), "queue", direction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func send(t *testing.T, api *fileadapter.API, bodies ...string) {
+	t.Helper()
+	for _, body := range bodies {
+		if err := api.Send([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func receive(t *testing.T, api *fileadapter.API, body string) *messagebustypes.Message {
+	t.Helper()
+	msg, err := api.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg == nil {
+		t.Fatalf("expected message %q", body)
+	}
+	if string(msg.Body) != body {
+		t.Fatalf("expected message %q, got %q", body, msg.Body)
+	}
+	return msg
+}
+
+func expectEmpty(t *testing.T, api *fileadapter.API) {
+	t.Helper()
+	if msg, err := api.Receive(); msg != nil || err != nil {
+		t.Fatalf("expected nil, nil from an empty queue, got %v, %v", msg, err)
+	}
+}
+
+func expectQueueInfo(t *testing.T, api *fileadapter.API, messages, consumers int) {
+	t.Helper()
+	info, err := api.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Messages != messages || info.Consumers != consumers {
+		t.Fatalf("expected %v messages and %v consumers, got %v", messages, consumers, info)
+	}
+}
+
+func Test_SenderReceiverConformance(t *testing.T) {
+	messagebustest.RunSenderReceiverSuite(t, func(t *testing.T) messagebustest.Connector {
+		bus := open(t, t.TempDir())
+		return func(direction messagebustypes.Direction) messagebustest.Conn {
+			return initialize(t, bus, direction)
+		}
+	})
+}
+
+func Test_Nack(t *testing.T) {
+	bus := open(t, t.TempDir())
+	api := initialize(t, bus, messagebustypes.DirectionSendReceive)
+	send(t, api, "a", "b")
+
+	a := receive(t, api, "a")
+	b := receive(t, api, "b")
+	if err := b.Acknowledger.Nack(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Acknowledger.Nack(true); err != nil {
+		t.Fatal(err)
+	}
+
+	a = receive(t, api, "a")
+	if err := a.Acknowledger.Nack(false); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, api, "b")
+	expectEmpty(t, api)
+}
+
+func Test_RedeliveryAfterReopen(t *testing.T) {
+	dir := t.TempDir()
+	bus := open(t, dir)
+	api := initialize(t, bus, messagebustypes.DirectionSendReceive)
+	send(t, api, "a", "b", "c")
+
+	a := receive(t, api, "a")
+	if err := a.Acknowledger.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, api, "b")
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := initialize(t, open(t, dir), messagebustypes.DirectionReceiveOnly)
+	expectQueueInfo(t, reopened, 2, 1)
+	receive(t, reopened, "b")
+	receive(t, reopened, "c")
+	expectEmpty(t, reopened)
+}
+
+func Test_Close(t *testing.T) {
+	bus := open(t, t.TempDir())
+	sender := initialize(t, bus, messagebustypes.DirectionSendOnly)
+	receiver := initialize(t, bus, messagebustypes.DirectionReceiveOnly)
+	send(t, sender, "a")
+
+	msg := receive(t, receiver, "a")
+	if err := receiver.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.Acknowledger.Ack(); err == nil {
+		t.Fatal("expected an error when acknowledging a message after closing")
+	}
+	expectQueueInfo(t, sender, 1, 0)
+
+	receiver = initialize(t, bus, messagebustypes.DirectionReceiveOnly)
+	receive(t, receiver, "a")
+}
+
+func Test_ExclusiveConsumer(t *testing.T) {
+	dir := t.TempDir()
+	first := initialize(t, open(t, dir), messagebustypes.DirectionReceiveOnly)
+
+	other := open(t, dir)
+	if _, err := other.Initialize(context.Background(), "queue", messagebustypes.DirectionReceiveOnly); err == nil {
+		t.Fatal("expected an error when consuming a queue that is consumed by another bus")
+	}
+
+	// Another bus may still send to the queue, and sees the consumer.
+	sender := initialize(t, other, messagebustypes.DirectionSendOnly)
+	send(t, sender, "a")
+	expectQueueInfo(t, sender, 1, 1)
+	receive(t, first, "a")
+
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expectQueueInfo(t, sender, 1, 0)
+	receive(t, initialize(t, other, messagebustypes.DirectionReceiveOnly), "a")
+}
+
+func Test_InspectCountsAnotherBusesAcks(t *testing.T) {
+	dir := t.TempDir()
+	receiver := initialize(t, open(t, dir), messagebustypes.DirectionReceiveOnly)
+	sender := initialize(t, open(t, dir), messagebustypes.DirectionSendOnly)
+
+	send(t, sender, "a", "b")
+	expectQueueInfo(t, sender, 2, 1)
+
+	if err := receive(t, receiver, "a").Acknowledger.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	expectQueueInfo(t, sender, 1, 1)
+
+	// Acknowledging the last message compacts the queue, which the sender
+	// sees the next time it inspects the queue.
+	if err := receive(t, receiver, "b").Acknowledger.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	expectQueueInfo(t, sender, 0, 1)
+
+	send(t, sender, "c")
+	expectQueueInfo(t, sender, 1, 1)
+	expectQueueInfo(t, receiver, 1, 1)
+}
+
+func Test_TornWrite(t *testing.T) {
+	dir := t.TempDir()
+	bus := open(t, dir)
+	api := initialize(t, bus, messagebustypes.DirectionSendOnly)
+	send(t, api, "a")
+	if err := bus.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash part way through writing a record.
+	log, err := os.OpenFile(filepath.Join(dir, "queue", "log"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := log.Write([]byte{0, 0, 0, 100, 1, 2, 3, 4, 5}); err != nil {
+		t.Fatal(err)
+	}
+	log.Close()
+
+	api = initialize(t, open(t, dir), messagebustypes.DirectionSendReceive)
+	send(t, api, "b")
+	receive(t, api, "a")
+	receive(t, api, "b")
+	expectEmpty(t, api)
+}
+
+func Test_ReceiveContextFromAnotherBus(t *testing.T) {
+	dir := t.TempDir()
+	receiver := initialize(t, open(t, dir), messagebustypes.DirectionReceiveOnly)
+	sender := initialize(t, open(t, dir), messagebustypes.DirectionSendOnly)
+
+	received := make(chan *messagebustypes.Message)
+	go func() {
+		msg, err := receiver.ReceiveContext(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		received <- msg
+	}()
+
+	send(t, sender, "a")
+	select {
+	case msg := <-received:
+		if msg == nil || string(msg.Body) != "a" {
+			t.Fatalf("unexpected message %v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message sent by another bus")
+	}
+}
+
+func Test_InvalidQueueName(t *testing.T) {
+	bus := open(t, t.TempDir())
+	for _, name := range []string{"", "..", "a/b"} {
+		if _, err := bus.Initialize(context.Background(), name, messagebustypes.DirectionSendOnly); err == nil {
+			t.Errorf("expected an error for queue name %q", name)
+		}
+	}
+}
@@ -0,0 +1,627 @@
+package fileadapter
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+const (
+	logFileName          = "log"
+	acksFileName         = "consumer.acks"
+	consumerLockFileName = "consumer.lock"
+
+	// While a process consumes a queue it holds a shared lock on the
+	// presence file. Other processes count the queue's consumers by testing
+	// that lock, rather than the consumer lock, so that counting consumers
+	// never prevents a process from becoming the consumer.
+	presenceFileName = "consumer.presence"
+
+	// The log begins with a header holding the log's generation, which is
+	// incremented each time the log is truncated. This lets each process
+	// detect that offsets it has cached are no longer valid.
+	logHeaderSize = 8
+
+	// Each record in the log is prefixed with its length and a CRC of its
+	// payload, so that a record torn by a crash can be detected.
+	recordHeaderSize = 8
+	maxRecordSize    = 64 << 20
+
+	// Each entry in the acks log is the offset of an acknowledged record.
+	ackSize = 8
+)
+
+// A record is a message stored in the log.
+type record struct {
+	offset   int64
+	envelope *messagebustypes.Envelope
+	body     []byte
+}
+
+type recordPayload struct {
+	Envelope *messagebustypes.Envelope
+	Body     []byte
+}
+
+// A queue is a process's view of a queue directory. All access to a queue is
+// guarded by the bus's mutex.
+type queue struct {
+	dir  string
+	sync bool
+	log  *os.File
+
+	// generation and validEnd describe the portion of the log this process
+	// has validated, and records is the number of records within it.
+	generation uint64
+	validEnd   int64
+	records    int
+
+	// acksEnd and acks describe the portion of the acks log that this
+	// process has counted while another process consumes the queue.
+	acksEnd int64
+	acks    int
+
+	// changed is closed (and replaced) whenever this process adds or
+	// requeues messages, or closes a receiver, so that blocked receivers can
+	// re-check the queue.
+	changed chan struct{}
+
+	// consumer is only set while this process is consuming the queue.
+	consumer  *consumer
+	receivers int
+}
+
+// A consumer tracks the messages that have been delivered and acknowledged.
+type consumer struct {
+	lock     *os.File
+	presence *os.File
+	acks     *os.File
+	acksEnd  int64
+	acked    map[int64]bool
+
+	// readOffset is the end of the portion of the log that has been read
+	// into pending.
+	readOffset int64
+	pending    []*record
+	inFlight   map[int64]*delivery
+}
+
+type delivery struct {
+	record   *record
+	receiver *API
+}
+
+func openQueue(dir string, sync bool) (*queue, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &queue{
+		dir:     dir,
+		sync:    sync,
+		log:     log,
+		changed: make(chan struct{}),
+	}, nil
+}
+
+func (q *queue) close() error {
+	if q.consumer != nil {
+		q.releaseConsumer()
+	}
+	return q.log.Close()
+}
+
+// signal wakes any receivers in this process that are waiting on the queue.
+func (q *queue) signal() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// withLog runs fn while holding the lock on the log, after first bringing the
+// process's view of the log up to date.
+func (q *queue) withLog(fn func() error) error {
+	err := lockFile(q.log)
+	if err != nil {
+		return err
+	}
+	defer unlockFile(q.log)
+
+	err = q.refreshGeneration()
+	if err != nil {
+		return err
+	}
+
+	err = q.validateTail()
+	if err != nil {
+		return err
+	}
+
+	return fn()
+}
+
+// refreshGeneration reads the log's generation, initializing the header if
+// the log is new. If the log was truncated by another process, the process's
+// view of the log is reset. The caller must hold the lock on the log.
+func (q *queue) refreshGeneration() error {
+	header := make([]byte, logHeaderSize)
+	_, err := q.log.ReadAt(header, 0)
+	if err == io.EOF {
+		binary.BigEndian.PutUint64(header, 1)
+		err = q.writeLog(header, 0)
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	generation := binary.BigEndian.Uint64(header)
+	if generation != q.generation {
+		q.generation = generation
+		q.validEnd = logHeaderSize
+		q.records = 0
+		q.acksEnd = 0
+		q.acks = 0
+		if q.consumer != nil {
+			q.consumer.readOffset = logHeaderSize
+		}
+	}
+	return nil
+}
+
+// validateTail checks any records appended since the log was last
+// validated. Because the caller holds the lock on the log, no write can be in
+// progress, so an incomplete or corrupt record can only have been left by a
+// crash. Such a record is truncated, along with anything after it.
+func (q *queue) validateTail() error {
+	info, err := q.log.Stat()
+	if err != nil {
+		return err
+	}
+
+	records, end, err := q.scan(q.validEnd, info.Size(), false)
+	if err != nil {
+		return err
+	}
+	if end < info.Size() {
+		err = q.log.Truncate(end)
+		if err != nil {
+			return err
+		}
+	}
+	q.validEnd = end
+	q.records += len(records)
+	return nil
+}
+
+// scan reads records from the log between from and limit, stopping at the
+// first incomplete or corrupt record. The offset at which scanning stopped is
+// returned. Records' envelopes and bodies are only decoded if decode is true.
+func (q *queue) scan(from, limit int64, decode bool) ([]*record, int64, error) {
+	records := []*record{}
+	offset := from
+	header := make([]byte, recordHeaderSize)
+	for offset+recordHeaderSize <= limit {
+		_, err := q.log.ReadAt(header, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		checksum := binary.BigEndian.Uint32(header[4:])
+		if length > maxRecordSize || offset+recordHeaderSize+length > limit {
+			break
+		}
+
+		payload := make([]byte, length)
+		_, err = q.log.ReadAt(payload, offset+recordHeaderSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		if crc32.ChecksumIEEE(payload) != checksum {
+			break
+		}
+
+		r := &record{offset: offset}
+		if decode {
+			decoded := new(recordPayload)
+			err = json.Unmarshal(payload, decoded)
+			if err != nil {
+				return nil, 0, fmt.Errorf("corrupt record at offset %v: %w", offset, err)
+			}
+			r.envelope = decoded.Envelope
+			r.body = decoded.Body
+		}
+		records = append(records, r)
+
+		offset += recordHeaderSize + length
+	}
+	return records, offset, nil
+}
+
+// writeLog writes to the log at the supplied offset, flushing the write to
+// stable storage if configured to do so.
+func (q *queue) writeLog(b []byte, offset int64) error {
+	_, err := q.log.WriteAt(b, offset)
+	if err == nil && q.sync {
+		err = q.log.Sync()
+	}
+	return err
+}
+
+// append adds a message to the end of the log.
+func (q *queue) append(envelope *messagebustypes.Envelope, body []byte) error {
+	payload, err := json.Marshal(&recordPayload{
+		Envelope: envelope,
+		Body:     body,
+	})
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxRecordSize {
+		return fmt.Errorf("message of %v bytes exceeds the maximum of %v bytes", len(payload), maxRecordSize)
+	}
+
+	entry := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(entry[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(entry[4:recordHeaderSize], crc32.ChecksumIEEE(payload))
+	copy(entry[recordHeaderSize:], payload)
+
+	return q.withLog(func() error {
+		err := q.writeLog(entry, q.validEnd)
+		if err != nil {
+			// Remove any partial write so that the log remains valid.
+			q.log.Truncate(q.validEnd)
+			return err
+		}
+		q.validEnd += int64(len(entry))
+		q.records++
+		return nil
+	})
+}
+
+// addReceiver registers a receiver in this process, becoming the queue's
+// consumer if this is the process's first receiver.
+func (q *queue) addReceiver() error {
+	if q.consumer == nil {
+		err := q.acquireConsumer()
+		if err != nil {
+			return err
+		}
+	}
+	q.receivers++
+	return nil
+}
+
+// removeReceiver deregisters a receiver, requeueing its unacknowledged
+// messages. The queue's consumer is released once the process's last
+// receiver is removed.
+func (q *queue) removeReceiver(receiver *API) {
+	for offset, d := range q.consumer.inFlight {
+		if d.receiver == receiver {
+			delete(q.consumer.inFlight, offset)
+			q.consumer.requeue(d.record)
+		}
+	}
+
+	q.receivers--
+	if q.receivers == 0 {
+		q.releaseConsumer()
+	}
+	q.signal()
+}
+
+func (q *queue) acquireConsumer() error {
+	lock, err := os.OpenFile(filepath.Join(q.dir, consumerLockFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	locked, err := tryLockFile(lock)
+	if err != nil || !locked {
+		lock.Close()
+		if err == nil {
+			err = fmt.Errorf("queue %v is being consumed by another process", filepath.Base(q.dir))
+		}
+		return err
+	}
+
+	presence, err := os.OpenFile(filepath.Join(q.dir, presenceFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err == nil {
+		err = lockFileShared(presence)
+		if err != nil {
+			presence.Close()
+		}
+	}
+	if err != nil {
+		unlockFile(lock)
+		lock.Close()
+		return err
+	}
+
+	acks, err := os.OpenFile(filepath.Join(q.dir, acksFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		unlockFile(presence)
+		presence.Close()
+		unlockFile(lock)
+		lock.Close()
+		return err
+	}
+
+	c := &consumer{
+		lock:       lock,
+		presence:   presence,
+		acks:       acks,
+		acked:      map[int64]bool{},
+		readOffset: logHeaderSize,
+		pending:    []*record{},
+		inFlight:   map[int64]*delivery{},
+	}
+
+	err = q.withLog(func() error {
+		var err error
+		c.acked, c.acksEnd, err = readAcks(acks)
+		if err != nil {
+			return err
+		}
+		// A partial entry can only have been left by a crash.
+		return acks.Truncate(c.acksEnd)
+	})
+	if err != nil {
+		acks.Close()
+		unlockFile(presence)
+		presence.Close()
+		unlockFile(lock)
+		lock.Close()
+		return err
+	}
+
+	q.consumer = c
+	return nil
+}
+
+func (q *queue) releaseConsumer() {
+	q.consumer.acks.Close()
+	unlockFile(q.consumer.presence)
+	q.consumer.presence.Close()
+	unlockFile(q.consumer.lock)
+	q.consumer.lock.Close()
+	q.consumer = nil
+}
+
+// readAcks returns the set of acknowledged offsets, and the end of the last
+// complete entry in the acks log.
+func readAcks(acks *os.File) (map[int64]bool, int64, error) {
+	info, err := acks.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	end := info.Size() - info.Size()%ackSize
+
+	content := make([]byte, end)
+	_, err = acks.ReadAt(content, 0)
+	if err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+
+	acked := map[int64]bool{}
+	for i := int64(0); i < end; i += ackSize {
+		acked[int64(binary.BigEndian.Uint64(content[i:i+ackSize]))] = true
+	}
+	return acked, end, nil
+}
+
+// refill reads any records that have been appended to the log since it was
+// last read, and adds the unacknowledged records to pending.
+func (q *queue) refill() error {
+	return q.withLog(func() error {
+		c := q.consumer
+		records, _, err := q.scan(c.readOffset, q.validEnd, true)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			if !c.acked[r.offset] {
+				c.pending = append(c.pending, r)
+			}
+		}
+		c.readOffset = q.validEnd
+		return nil
+	})
+}
+
+// ack records that a message has been acknowledged. Once every message has
+// been acknowledged, the log and acks log are truncated.
+func (q *queue) ack(offset int64) error {
+	c := q.consumer
+	entry := make([]byte, ackSize)
+	binary.BigEndian.PutUint64(entry, uint64(offset))
+	_, err := c.acks.WriteAt(entry, c.acksEnd)
+	if err == nil && q.sync {
+		err = c.acks.Sync()
+	}
+	if err != nil {
+		c.acks.Truncate(c.acksEnd)
+		return err
+	}
+	c.acksEnd += ackSize
+	c.acked[offset] = true
+
+	if len(c.pending) > 0 || len(c.inFlight) > 0 {
+		return nil
+	}
+	return q.withLog(q.compact)
+}
+
+// compact truncates the log and the acks log if every message in the log has
+// been acknowledged. The caller must hold the lock on the log.
+func (q *queue) compact() error {
+	c := q.consumer
+	if c.readOffset != q.validEnd || len(c.pending) > 0 || len(c.inFlight) > 0 {
+		return nil
+	}
+
+	header := make([]byte, logHeaderSize)
+	binary.BigEndian.PutUint64(header, q.generation+1)
+	err := q.log.Truncate(logHeaderSize)
+	if err == nil {
+		err = q.writeLog(header, 0)
+	}
+	if err == nil {
+		err = c.acks.Truncate(0)
+	}
+	if err != nil {
+		return err
+	}
+
+	q.generation++
+	q.validEnd = logHeaderSize
+	q.records = 0
+	q.acksEnd = 0
+	q.acks = 0
+	c.readOffset = logHeaderSize
+	c.acksEnd = 0
+	c.acked = map[int64]bool{}
+	return nil
+}
+
+// requeue returns a record to pending, in log order.
+func (c *consumer) requeue(r *record) {
+	i := sort.Search(len(c.pending), func(i int) bool {
+		return c.pending[i].offset > r.offset
+	})
+	c.pending = append(c.pending, nil)
+	copy(c.pending[i+1:], c.pending[i:])
+	c.pending[i] = r
+}
+
+// inspect returns the number of unacknowledged messages in the log that
+// aren't in flight in this process, and the number of consumers. The log
+// isn't read; only the records appended by other processes since it was last
+// validated are scanned.
+func (q *queue) inspect() (int, int, error) {
+	messages := 0
+	err := q.withLog(func() error {
+		if q.consumer != nil {
+			messages = q.records - len(q.consumer.acked) - len(q.consumer.inFlight)
+			return nil
+		}
+
+		err := q.countAcks()
+		if err != nil {
+			return err
+		}
+		messages = q.records - q.acks
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	consumers, err := q.consumers()
+	return messages, consumers, err
+}
+
+// countAcks counts the entries that another process has appended to the
+// acks log since they were last counted. Each entry acknowledges a distinct
+// record, so the entries don't need to be read. The caller must hold the
+// lock on the log.
+func (q *queue) countAcks() error {
+	info, err := os.Stat(filepath.Join(q.dir, acksFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// A partial entry is either being written, or was left by a crash.
+	end := info.Size() - info.Size()%ackSize
+	q.acks += int((end - q.acksEnd) / ackSize)
+	q.acksEnd = end
+	return nil
+}
+
+// consumers returns the number of receivers consuming the queue. If another
+// process is consuming the queue, its receivers are counted as one.
+func (q *queue) consumers() (int, error) {
+	if q.consumer != nil {
+		return q.receivers, nil
+	}
+
+	presence, err := os.OpenFile(filepath.Join(q.dir, presenceFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer presence.Close()
+
+	// A consumer waits for the lock on the presence file rather than failing,
+	// so holding it briefly here can only delay a process from becoming the
+	// consumer.
+	locked, err := tryLockFile(presence)
+	if err != nil {
+		return 0, err
+	}
+	if locked {
+		unlockFile(presence)
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// next delivers the first pending message to the supplied receiver, reading
+// new messages from the log if necessary. nil is returned if no message is
+// pending.
+func (q *queue) next(receiver *API) (*record, error) {
+	c := q.consumer
+	if len(c.pending) == 0 {
+		err := q.refill()
+		if err != nil {
+			return nil, err
+		}
+		if len(c.pending) == 0 {
+			return nil, nil
+		}
+	}
+
+	r := c.pending[0]
+	c.pending = c.pending[1:]
+	c.inFlight[r.offset] = &delivery{
+		record:   r,
+		receiver: receiver,
+	}
+	return r, nil
+}
+
+// settle removes a message that was delivered to the supplied receiver from
+// the messages in flight, and either returns it to pending or acknowledges
+// it.
+func (q *queue) settle(receiver *API, offset int64, requeue bool) error {
+	c := q.consumer
+	if c == nil {
+		return fmt.Errorf("unknown delivery at offset %v", offset)
+	}
+	d, found := c.inFlight[offset]
+	if !found || d.receiver != receiver {
+		return fmt.Errorf("unknown delivery at offset %v", offset)
+	}
+	delete(c.inFlight, offset)
+
+	if requeue {
+		c.requeue(d.record)
+		q.signal()
+		return nil
+	}
+	return q.ack(offset)
+}
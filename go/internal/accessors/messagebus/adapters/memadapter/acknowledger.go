@@ -0,0 +1,55 @@
+package memadapter
+
+import (
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/acknowledger"
+)
+
+// Acknowledger acknowledges a single message that was received from a queue.
+type Acknowledger struct {
+	api *API
+	tag uint64
+}
+
+func newAcknowledger(api *API, tag uint64) *Acknowledger {
+	return &Acknowledger{
+		api: api,
+		tag: tag,
+	}
+}
+
+// Ack acknowledges that a message has been received, which removes it from
+// the queue.
+func (a *Acknowledger) Ack() error {
+	return a.settle(false)
+}
+
+// Nack negatively acknowledges that a message has been received. If requeue
+// is true, the message is returned to the front of the queue. Otherwise it is
+// discarded.
+func (a *Acknowledger) Nack(requeue bool) error {
+	return a.settle(requeue)
+}
+
+// settle removes the message from the queue's unacknowledged messages, and
+// optionally returns it to the front of the queue. An error is returned if the message has already been acknowledged, or if it
+// was requeued because its consumer was closed.
+func (a *Acknowledger) settle(requeue bool) error {
+	a.api.broker.mu.Lock()
+	defer a.api.broker.mu.Unlock()
+
+	q := a.api.broker.getQueue(a.api.queueName)
+	d, found := q.unacked[a.tag]
+	if !found {
+		return fmt.Errorf("unknown delivery tag %v", a.tag)
+	}
+	delete(q.unacked, a.tag)
+	if requeue {
+		q.ready = append([]*message{d.msg}, q.ready...)
+		q.signal()
+	}
+	return nil
+}
+
+var _ acknowledger.AckNack = (*Acknowledger)(nil)
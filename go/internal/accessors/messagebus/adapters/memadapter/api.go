@@ -0,0 +1,214 @@
+package memadapter
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+// API is a connection to a single queue on a Broker. This should be
+// instantiated via Broker.Initialize.
+type API struct {
+	broker    *Broker
+	queueName string
+	direction messagebustypes.Direction
+	closed    bool
+
+	// stop is closed by Close, to stop the goroutine that closes the API once
+	// its context is done.
+	stop chan struct{}
+}
+
+// Initialize returns an API for the named queue, declaring the queue if it
+// doesn't already exist. If the supplied direction permits receiving, the API
+// is registered as a consumer of the queue until Close is called or ctx is
+// cancelled.
+func (b *Broker) Initialize(ctx context.Context, queueName string, direction messagebustypes.Direction) (*API, error) {
+	if err := direction.Validate(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	api := &API{
+		broker:    b,
+		queueName: queueName,
+		direction: direction,
+		stop:      make(chan struct{}),
+	}
+
+	q := b.getQueue(queueName)
+	if api.direction.CanReceive() {
+		q.consumers++
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			api.Close()
+		case <-api.stop:
+		}
+	}()
+
+	return api, nil
+}
+
+// Close releases the API. Any messages that were received by the API but
+// not yet acknowledged are returned to the queue, and the API no longer
+// counts as a consumer of the queue. The API also stops watching the context
+// it was initialized with, so APIs whose contexts are never done can be
+// released. Calling Close more than once has no effect.
+func (a *API) Close() error {
+	a.broker.mu.Lock()
+	defer a.broker.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	close(a.stop)
+
+	q := a.broker.getQueue(a.queueName)
+	if a.direction.CanReceive() {
+		q.consumers--
+	}
+
+	// Unacknowledged messages are requeued in the order they were delivered,
+	// ahead of any messages that are waiting to be delivered.
+	tags := []uint64{}
+	for tag, d := range q.unacked {
+		if d.consumer == a {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+
+	requeued := make([]*message, 0, len(tags))
+	for _, tag := range tags {
+		requeued = append(requeued, q.unacked[tag].msg)
+		delete(q.unacked, tag)
+	}
+	q.ready = append(requeued, q.ready...)
+	q.signal()
+
+	return nil
+}
+
+// Send transmits a message to the queue with a default envelope. This method
+// will panic if the API was initialized as receive-only.
+func (a *API) Send(msg []byte) error {
+	return a.SendEnvelope(nil, msg)
+}
+
+// SendEnvelope transmits a message to the queue with the supplied envelope.
+// This method will panic if the API was initialized as receive-only.
+func (a *API) SendEnvelope(envelope *messagebustypes.Envelope, msg []byte) error {
+	if !a.direction.CanSend() {
+		panic("Cannot send on a receive-only connection.")
+	}
+
+	a.broker.mu.Lock()
+	defer a.broker.mu.Unlock()
+
+	if a.closed {
+		return messagebustypes.ErrClosed
+	}
+
+	body := make([]byte, len(msg))
+	copy(body, msg)
+
+	q := a.broker.getQueue(a.queueName)
+	q.ready = append(q.ready, &message{
+		envelope: envelope.WithDefaults(),
+		body:     body,
+	})
+	q.signal()
+	return nil
+}
+
+// Inspect returns the number of messages that are waiting to be delivered
+// (not including messages that have been delivered but not acknowledged),
+// and the number of consumers associated with the queue.
+func (a *API) Inspect() (*messagebustypes.QueueInfo, error) {
+	a.broker.mu.Lock()
+	defer a.broker.mu.Unlock()
+
+	q := a.broker.getQueue(a.queueName)
+	return &messagebustypes.QueueInfo{
+		Messages:  len(q.ready),
+		Consumers: q.consumers,
+	}, nil
+}
+
+// Receive retrieves a message from the queue. This method does not block. If
+// no message is available the method will return nil. The message remains
+// outstanding until it is acknowledged via its Acknowledger, or until the API
+// is closed. This method will panic if the API was initialized as send-only.
+func (a *API) Receive() (*messagebustypes.Message, error) {
+	if !a.direction.CanReceive() {
+		panic("Cannot receive from a send-only connection.")
+	}
+
+	a.broker.mu.Lock()
+	defer a.broker.mu.Unlock()
+
+	msg, _, err := a.tryReceive()
+	return msg, err
+}
+
+// ReceiveContext retrieves a message from the queue, blocking until a
+// message is available or ctx is done. This method will panic if the API was
+// initialized as send-only.
+func (a *API) ReceiveContext(ctx context.Context) (*messagebustypes.Message, error) {
+	if !a.direction.CanReceive() {
+		panic("Cannot receive from a send-only connection.")
+	}
+
+	for {
+		a.broker.mu.Lock()
+		msg, changed, err := a.tryReceive()
+		a.broker.mu.Unlock()
+		if msg != nil || err != nil {
+			return msg, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// tryReceive delivers the next ready message, if any. If no message is ready,
+// a channel that is closed when the queue next changes is returned. The
+// caller must hold the broker's mutex.
+func (a *API) tryReceive() (*messagebustypes.Message, <-chan struct{}, error) {
+	if a.closed {
+		return nil, nil, messagebustypes.ErrClosed
+	}
+
+	q := a.broker.getQueue(a.queueName)
+	if len(q.ready) == 0 {
+		return nil, q.changed, nil
+	}
+
+	msg := q.ready[0]
+	q.ready = q.ready[1:]
+	q.nextTag++
+	q.unacked[q.nextTag] = &delivery{
+		msg:      msg,
+		consumer: a,
+	}
+
+	return &messagebustypes.Message{
+		Envelope:     *msg.envelope.WithDefaults(),
+		Body:         msg.body,
+		Acknowledger: newAcknowledger(a, q.nextTag),
+	}, nil, nil
+}
+
+var _ messagebus.SenderReceiver = (*API)(nil)
@@ -0,0 +1,72 @@
+// Package memadapter provides an in-process message bus. A Broker holds any
+// number of named queues, and each call to Broker.Initialize returns an API
+// that sends to and/or receives from one of those queues, in the same manner
+// as the amqpadapter. This allows curators, archivists, and researchers to be
+// wired together within a single process, without a message broker.
+package memadapter
+
+import (
+	"sync"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+// A Broker is a collection of named in-process queues. A Broker is safe for
+// concurrent use.
+type Broker struct {
+	mu     sync.Mutex
+	queues map[string]*queue
+}
+
+// NewBroker returns a reference to a new Broker with no queues.
+func NewBroker() *Broker {
+	return &Broker{
+		queues: map[string]*queue{},
+	}
+}
+
+// A queue holds the messages that are ready to be delivered, and the
+// messages that have been delivered but not yet acknowledged. All access to
+// a queue is guarded by the broker's mutex.
+type queue struct {
+	ready     []*message
+	unacked   map[uint64]*delivery
+	nextTag   uint64
+	consumers int
+
+	// changed is closed (and replaced) whenever messages become ready, or a
+	// consumer is closed, so that blocked receivers can re-check the queue.
+	changed chan struct{}
+}
+
+type message struct {
+	envelope *messagebustypes.Envelope
+	body     []byte
+}
+
+type delivery struct {
+	msg      *message
+	consumer *API
+}
+
+// getQueue returns the named queue, declaring it if it doesn't exist. The
+// caller must hold the broker's mutex.
+func (b *Broker) getQueue(name string) *queue {
+	q, found := b.queues[name]
+	if !found {
+		q = &queue{
+			ready:   []*message{},
+			unacked: map[uint64]*delivery{},
+			changed: make(chan struct{}),
+		}
+		b.queues[name] = q
+	}
+	return q
+}
+
+// signal wakes any receivers that are waiting on the queue. The caller must
+// hold the broker's mutex.
+func (q *queue) signal() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
@@ -0,0 +1,127 @@
+package memadapter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/memadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustest"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+func initialize(t *testing.T, broker *memadapter.Broker, direction messagebustypes.Direction) *memadapter.API {
+	t.Helper()
+	api, err := broker.Initialize(context.Background(), "queue", direction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func receive(t *testing.T, api *memadapter.API) *messagebustypes.Message {
+	t.Helper()
+	msg, err := api.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg == nil {
+		t.Fatal("expected a message")
+	}
+	return msg
+}
+
+func expectQueueInfo(t *testing.T, api *memadapter.API, messages, consumers int) {
+	t.Helper()
+	info, err := api.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Messages != messages || info.Consumers != consumers {
+		t.Fatalf("expected %v messages and %v consumers, got %v", messages, consumers, info)
+	}
+}
+
+func Test_SenderReceiverConformance(t *testing.T) {
+	messagebustest.RunSenderReceiverSuite(t, func(t *testing.T) messagebustest.Connector {
+		broker := memadapter.NewBroker()
+		return func(direction messagebustypes.Direction) messagebustest.Conn {
+			return initialize(t, broker, direction)
+		}
+	})
+}
+
+func Test_Nack(t *testing.T) {
+	broker := memadapter.NewBroker()
+	api := initialize(t, broker, messagebustypes.DirectionSendReceive)
+	for _, body := range []string{"a", "b"} {
+		if err := api.Send([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msg := receive(t, api)
+	if err := msg.Acknowledger.Nack(true); err != nil {
+		t.Fatal(err)
+	}
+	expectQueueInfo(t, api, 2, 1)
+
+	msg = receive(t, api)
+	if string(msg.Body) != "a" {
+		t.Fatalf("expected a requeued message to be redelivered first, got %q", msg.Body)
+	}
+	if err := msg.Acknowledger.Nack(false); err != nil {
+		t.Fatal(err)
+	}
+	expectQueueInfo(t, api, 1, 1)
+
+	msg = receive(t, api)
+	if string(msg.Body) != "b" {
+		t.Fatalf("expected a discarded message not to be redelivered, got %q", msg.Body)
+	}
+}
+
+func Test_CloseRequeuesUnacknowledgedMessages(t *testing.T) {
+	broker := memadapter.NewBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	sender := initialize(t, broker, messagebustypes.DirectionSendOnly)
+	receiver, err := broker.Initialize(ctx, "queue", messagebustypes.DirectionReceiveOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sender.Send([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	msg := receive(t, receiver)
+	expectQueueInfo(t, sender, 0, 1)
+
+	cancel()
+	if err := receiver.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expectQueueInfo(t, sender, 1, 0)
+
+	if err := msg.Acknowledger.Ack(); err == nil {
+		t.Fatal("expected an error when acknowledging a requeued message")
+	}
+	if _, err := receiver.Receive(); err == nil {
+		t.Fatal("expected an error when receiving from a closed api")
+	}
+}
+
+func Test_QueuesAreIsolated(t *testing.T) {
+	broker := memadapter.NewBroker()
+	a := initialize(t, broker, messagebustypes.DirectionSendReceive)
+	b, err := broker.Initialize(context.Background(), "other", messagebustypes.DirectionSendReceive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Send([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := b.Receive(); msg != nil || err != nil {
+		t.Fatalf("expected nil, nil from another queue, got %v, %v", msg, err)
+	}
+	expectQueueInfo(t, b, 0, 1)
+}
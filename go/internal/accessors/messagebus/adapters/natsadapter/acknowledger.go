@@ -0,0 +1,36 @@
+package natsadapter
+
+import (
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/acknowledger"
+	"github.com/nats-io/nats.go"
+)
+
+// Acknowledger acknowledges a single message that was received from a
+// stream's consumer.
+type Acknowledger struct {
+	api *API
+	msg *nats.Msg
+}
+
+// Ack acknowledges that a message has been received, which removes it from
+// the stream. Ack waits for the server to confirm the acknowledgement.
+func (a *Acknowledger) Ack() error {
+	a.api.settle(a.msg)
+	return a.msg.AckSync(nats.AckWait(a.api.config.requestTimeout()))
+}
+
+// Nack negatively acknowledges that a message has been received. If requeue
+// is true, the server redelivers the message immediately, unless it has
+// reached the stream's MaxDeliveryAttempts. Otherwise the server stops
+// delivering the message and removes it from the stream. Nack waits for the
+// server to confirm the response, so that a requeued message is available as
+// soon as Nack returns.
+func (a *Acknowledger) Nack(requeue bool) error {
+	a.api.settle(a.msg)
+	if requeue {
+		return a.msg.Nak(nats.AckWait(a.api.config.requestTimeout()))
+	}
+	return a.msg.Term(nats.AckWait(a.api.config.requestTimeout()))
+}
+
+var _ acknowledger.AckNack = (*Acknowledger)(nil)
@@ -0,0 +1,341 @@
+package natsadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/nats-io/nats.go"
+)
+
+// pullExpiry is the length of time for which a fetch issued by
+// ReceiveContext waits on the server. ReceiveContext issues successive
+// fetches until a message arrives, which bounds how long a request can
+// outlive a lost connection.
+const pullExpiry = 5 * time.Second
+
+// receiveExpiry is the length of time for which a fetch issued by Receive may
+// wait on the server. The server answers such a request as soon as a message
+// is available, but it holds the request if a delivered message may yet be
+// redelivered.
+const receiveExpiry = 100 * time.Millisecond
+
+// presenceWait is the length of time for which Inspect collects the replies
+// of the stream's receivers.
+const presenceWait = 100 * time.Millisecond
+
+// API is a connection to a single JetStream stream. This should be
+// instantiated via the Initialize function.
+//
+// Every API for a stream receives from the same durable pull consumer, so
+// each message is delivered to only one receiver. Each API that can receive
+// also answers presence requests for the stream, which is how Inspect counts
+// the stream's receivers. The client reconnects to
+// the server automatically; while it's reconnecting, sends and receives fail
+// or wait until the configured RequestTimeout.
+type API struct {
+	config    *Config
+	stream    *StreamConfig
+	direction messagebustypes.Direction
+	conn      *nats.Conn
+	js        nats.JetStreamContext
+
+	// sub is the pull subscription from which messages are fetched, and
+	// presence answers presence requests. Both are nil if the API can't
+	// receive.
+	sub      *nats.Subscription
+	presence *nats.Subscription
+
+	mu     sync.Mutex
+	closed bool
+
+	// inFlight holds the messages that were received but not yet
+	// acknowledged, so that they can be returned to the stream on Close.
+	inFlight map[*nats.Msg]bool
+}
+
+// Initialize establishes a connection with the server described by config,
+// then declares the stream and durable consumer described by stream if they
+// don't already exist. The connection is closed once ctx is done.
+func Initialize(ctx context.Context, config *Config, stream *StreamConfig, direction messagebustypes.Direction) (*API, error) {
+	if err := direction.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := stream.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := config.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := conn.JetStream(nats.MaxWait(config.requestTimeout()))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	err = stream.declare(js)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	a := &API{
+		config:    config,
+		stream:    stream,
+		direction: direction,
+		conn:      conn,
+		js:        js,
+		inFlight:  map[*nats.Msg]bool{},
+	}
+
+	if a.direction.CanReceive() {
+		err = a.subscribe()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		a.Close()
+	}()
+
+	return a, nil
+}
+
+// subscribe binds a pull subscription to the stream's durable consumer, and
+// subscribes to the stream's presence requests.
+func (a *API) subscribe() error {
+	var err error
+	a.sub, err = a.js.PullSubscribe(a.stream.subject(), a.stream.Name, nats.Bind(a.stream.Name, a.stream.Name))
+	if err != nil {
+		return err
+	}
+
+	a.presence, err = a.conn.Subscribe(a.stream.presenceSubject(), func(msg *nats.Msg) {
+		msg.Respond(nil)
+	})
+	return err
+}
+
+// Close closes the connection to the server. Any messages that were received
+// by the API but not yet acknowledged are returned to the stream for
+// immediate redelivery. Calling Close more than once has no effect.
+func (a *API) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	for msg := range a.inFlight {
+		msg.Nak()
+	}
+	a.inFlight = nil
+
+	err := a.conn.FlushTimeout(a.config.requestTimeout())
+	a.conn.Close()
+	if errors.Is(err, nats.ErrConnectionClosed) {
+		err = nil
+	}
+	return err
+}
+
+func (a *API) isClosed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.closed
+}
+
+// Send transmits a message to the stream with a default envelope. See
+// SendEnvelope.
+func (a *API) Send(msg []byte) error {
+	return a.SendEnvelope(nil, msg)
+}
+
+// SendEnvelope transmits a message to the stream with the supplied envelope,
+// and waits for the server to confirm that the message was stored. If a
+// message with the same MessageID was recently stored, the server discards
+// the duplicate. This method will panic if the API was initialized as
+// receive-only.
+func (a *API) SendEnvelope(envelope *messagebustypes.Envelope, msg []byte) error {
+	if !a.direction.CanSend() {
+		panic("Cannot send on a receive-only connection.")
+	}
+	if a.isClosed() {
+		return messagebustypes.ErrClosed
+	}
+
+	natsMsg, err := newMsg(a.stream.subject(), envelope, msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.js.PublishMsg(natsMsg)
+	if err != nil {
+		err = fmt.Errorf("Failed to publish a message: %w", err)
+	}
+	return err
+}
+
+// Inspect returns the number of messages that have not yet been delivered
+// (not including messages that have been delivered but not acknowledged),
+// and the number of APIs that can receive from the stream. Receivers are
+// counted by the replies to a presence request, so Inspect waits briefly for
+// them to answer.
+func (a *API) Inspect() (*messagebustypes.QueueInfo, error) {
+	if a.isClosed() {
+		return nil, messagebustypes.ErrClosed
+	}
+
+	info, err := a.js.ConsumerInfo(a.stream.Name, a.stream.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	consumers, err := a.countReceivers()
+	if err != nil {
+		return nil, err
+	}
+
+	return &messagebustypes.QueueInfo{
+		Messages:  int(info.NumPending),
+		Consumers: consumers,
+	}, nil
+}
+
+// countReceivers sends a presence request for the stream, and counts the
+// replies that arrive within presenceWait.
+func (a *API) countReceivers() (int, error) {
+	inbox := a.conn.NewRespInbox()
+	replies, err := a.conn.SubscribeSync(inbox)
+	if err != nil {
+		return 0, err
+	}
+	defer replies.Unsubscribe()
+
+	err = a.conn.PublishRequest(a.stream.presenceSubject(), inbox, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	deadline := time.Now().Add(presenceWait)
+	for {
+		_, err := replies.NextMsg(time.Until(deadline))
+		if errors.Is(err, nats.ErrTimeout) {
+			return count, nil
+		}
+		if errors.Is(err, nats.ErrNoResponders) {
+			// The server reports that nothing is subscribed to presence
+			// requests.
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+}
+
+// Receive retrieves a message from the stream. This method only waits briefly
+// for a message to be sent. If no message is available the method will return
+// nil. This method will panic if the API was initialized as send-only.
+func (a *API) Receive() (*messagebustypes.Message, error) {
+	if !a.direction.CanReceive() {
+		panic("Cannot receive from a send-only connection.")
+	}
+
+	return a.fetch(nats.MaxWait(receiveExpiry))
+}
+
+// ReceiveContext retrieves a message from the stream, blocking until a
+// message is available or ctx is done. While the client is reconnecting to
+// the server, ReceiveContext continues to wait. If ctx is done just as the
+// server delivers a message, the message is redelivered once the stream's
+// AckWait elapses. This method will panic if the API was initialized as
+// send-only.
+func (a *API) ReceiveContext(ctx context.Context) (*messagebustypes.Message, error) {
+	if !a.direction.CanReceive() {
+		panic("Cannot receive from a send-only connection.")
+	}
+
+	for {
+		fetchCtx, cancel := context.WithTimeout(ctx, pullExpiry)
+		msg, err := a.fetch(nats.Context(fetchCtx))
+		cancel()
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if msg != nil || (err != nil && !isTransient(err)) {
+			return msg, err
+		}
+	}
+}
+
+// fetch fetches a message from the stream's consumer, returning nil if the
+// fetch expired without yielding a message.
+func (a *API) fetch(opt nats.PullOpt) (*messagebustypes.Message, error) {
+	if a.isClosed() {
+		return nil, messagebustypes.ErrClosed
+	}
+
+	msgs, err := a.sub.Fetch(1, opt)
+	if errors.Is(err, nats.ErrTimeout) {
+		return nil, nil
+	}
+	if err != nil {
+		if a.isClosed() {
+			return nil, messagebustypes.ErrClosed
+		}
+		return nil, err
+	}
+	msg := msgs[0]
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		msg.Nak()
+		return nil, messagebustypes.ErrClosed
+	}
+	a.inFlight[msg] = true
+
+	return &messagebustypes.Message{
+		Envelope: newEnvelope(msg),
+		Body:     msg.Data,
+		Acknowledger: &Acknowledger{
+			api: a,
+			msg: msg,
+		},
+	}, nil
+}
+
+// settle forgets a message that is being acknowledged.
+func (a *API) settle(msg *nats.Msg) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.inFlight, msg)
+}
+
+// isTransient reports whether a failed fetch should be retried by
+// ReceiveContext.
+func isTransient(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, nats.ErrTimeout) ||
+		errors.Is(err, nats.ErrNoResponders)
+}
+
+var _ messagebus.SenderReceiver = (*API)(nil)
@@ -0,0 +1,105 @@
+package natsadapter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Config is a configuration for a connection to a NATS server with
+// JetStream enabled.
+type Config struct {
+	// URL is the address of the server, such as nats://localhost:4222. A
+	// comma separated list of addresses may be supplied for a cluster. The URL
+	// must not contain credentials; they are supplied via CredentialsFile.
+	URL string
+
+	// CredentialsFile is the path of a NATS credentials file holding a user
+	// JWT and NKey seed. If empty, the connection is unauthenticated.
+	CredentialsFile string
+
+	// ConnectionName is advertised to the server so that the connection can
+	// be identified in the server's monitoring tools.
+	ConnectionName string
+
+	// RequestTimeout is the length of time to wait for the server to respond
+	// to a JetStream request, such as publishing a message or acknowledging a
+	// delivery. If zero, DefaultRequestTimeout is used.
+	RequestTimeout time.Duration
+
+	// ReconnectWait is the delay between attempts to re-establish a lost
+	// connection. If zero, the client library's default is used. The client
+	// reconnects indefinitely.
+	ReconnectWait time.Duration
+}
+
+// DefaultRequestTimeout is used if a Config doesn't specify a RequestTimeout.
+const DefaultRequestTimeout = 5 * time.Second
+
+// Validate reports whether the configuration is complete and internally
+// consistent.
+func (c *Config) Validate() error {
+	if c.URL == "" {
+		return errors.New("nats config: URL is required")
+	}
+
+	for _, address := range strings.Split(c.URL, ",") {
+		u, err := url.Parse(strings.TrimSpace(address))
+		if err != nil {
+			return fmt.Errorf("nats config: invalid URL: %v", err)
+		}
+		switch u.Scheme {
+		case "nats", "tls":
+		default:
+			return fmt.Errorf("nats config: URL scheme must be nats or tls, got %q", u.Scheme)
+		}
+		if u.User != nil {
+			return errors.New("nats config: URL must not contain credentials; use CredentialsFile instead")
+		}
+	}
+
+	if c.RequestTimeout < 0 {
+		return fmt.Errorf("nats config: RequestTimeout must not be negative, got %v", c.RequestTimeout)
+	}
+	if c.ReconnectWait < 0 {
+		return fmt.Errorf("nats config: ReconnectWait must not be negative, got %v", c.ReconnectWait)
+	}
+
+	return nil
+}
+
+func (c *Config) requestTimeout() time.Duration {
+	if c.RequestTimeout == 0 {
+		return DefaultRequestTimeout
+	}
+	return c.RequestTimeout
+}
+
+// connect validates the configuration and opens a connection to the server.
+func (c *Config) connect() (*nats.Conn, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	options := []nats.Option{
+		nats.Name(c.ConnectionName),
+		nats.MaxReconnects(-1),
+	}
+	if c.CredentialsFile != "" {
+		options = append(options, nats.UserCredentials(c.CredentialsFile))
+	}
+	if c.ReconnectWait > 0 {
+		options = append(options, nats.ReconnectWait(c.ReconnectWait))
+	}
+
+	conn, err := nats.Connect(c.URL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %w", c.URL, err)
+	}
+	return conn, nil
+}
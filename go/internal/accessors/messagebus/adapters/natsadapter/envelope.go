@@ -0,0 +1,73 @@
+package natsadapter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/nats-io/nats.go"
+)
+
+// ReservedHeaderPrefix is the prefix of the headers that the adapter uses to
+// carry an envelope's fields. Senders may not supply headers with this
+// prefix, or with the server's Nats- prefix, and these headers are not
+// included in a received message's envelope headers.
+const ReservedHeaderPrefix = "Tbtl-"
+
+const serverHeaderPrefix = "Nats-"
+
+// Headers that carry an envelope's fields. An envelope's MessageID is
+// carried by the server's Nats-Msg-Id header, so that the server discards
+// duplicate publishes of the same message.
+const (
+	HeaderContentType   = ReservedHeaderPrefix + "Content-Type"
+	HeaderMessageType   = ReservedHeaderPrefix + "Message-Type"
+	HeaderSchemaVersion = ReservedHeaderPrefix + "Schema-Version"
+	HeaderTimestamp     = ReservedHeaderPrefix + "Timestamp"
+)
+
+// fieldHeaders maps the names of an envelope's fields onto the headers that
+// carry them.
+var fieldHeaders = map[string]string{
+	messagebustypes.FieldMessageID:     nats.MsgIdHdr,
+	messagebustypes.FieldContentType:   HeaderContentType,
+	messagebustypes.FieldMessageType:   HeaderMessageType,
+	messagebustypes.FieldSchemaVersion: HeaderSchemaVersion,
+	messagebustypes.FieldTimestamp:     HeaderTimestamp,
+}
+
+// newMsg maps an envelope and body onto a NATS message. The envelope's
+// missing fields are supplied via WithDefaults.
+func newMsg(subject string, envelope *messagebustypes.Envelope, body []byte) (*nats.Msg, error) {
+	envelope = envelope.WithDefaults()
+
+	msg := nats.NewMsg(subject)
+	for key, value := range envelope.Headers {
+		if strings.HasPrefix(key, ReservedHeaderPrefix) || strings.HasPrefix(key, serverHeaderPrefix) {
+			return nil, fmt.Errorf("header %v uses a reserved prefix", key)
+		}
+		msg.Header.Set(key, value)
+	}
+
+	for field, value := range envelope.EncodeFields() {
+		msg.Header.Set(fieldHeaders[field], value)
+	}
+
+	msg.Data = body
+	return msg, nil
+}
+
+// newEnvelope maps a NATS message onto an envelope. Headers that can't be
+// parsed are ignored.
+func newEnvelope(msg *nats.Msg) messagebustypes.Envelope {
+	headers := map[string]string{}
+	for key, values := range msg.Header {
+		if len(values) > 0 && !strings.HasPrefix(key, ReservedHeaderPrefix) && !strings.HasPrefix(key, serverHeaderPrefix) {
+			headers[key] = values[0]
+		}
+	}
+
+	return messagebustypes.DecodeFields(headers, func(field string) string {
+		return msg.Header.Get(fieldHeaders[field])
+	})
+}
@@ -0,0 +1,155 @@
+package natsadapter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/natsadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustest"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// startServer starts an in-process NATS server with JetStream enabled, and
+// returns a configuration for connecting to it.
+func startServer(t *testing.T) *natsadapter.Config {
+	t.Helper()
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server did not start")
+	}
+	t.Cleanup(s.Shutdown)
+
+	return &natsadapter.Config{
+		URL:            s.ClientURL(),
+		ConnectionName: t.Name(),
+		RequestTimeout: time.Second,
+	}
+}
+
+func initialize(t *testing.T, config *natsadapter.Config, stream *natsadapter.StreamConfig, direction messagebustypes.Direction) *natsadapter.API {
+	t.Helper()
+	api, err := natsadapter.Initialize(context.Background(), config, stream, direction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { api.Close() })
+	return api
+}
+
+func send(t *testing.T, api *natsadapter.API, bodies ...string) {
+	t.Helper()
+	for _, body := range bodies {
+		if err := api.Send([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func receive(t *testing.T, api *natsadapter.API, body string) *messagebustypes.Message {
+	t.Helper()
+	msg, err := api.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg == nil {
+		t.Fatalf("expected message %q", body)
+	}
+	if string(msg.Body) != body {
+		t.Fatalf("expected message %q, got %q", body, msg.Body)
+	}
+	return msg
+}
+
+func expectEmpty(t *testing.T, api *natsadapter.API) {
+	t.Helper()
+	if msg, err := api.Receive(); msg != nil || err != nil {
+		t.Fatalf("expected nil, nil from an empty stream, got %v, %v", msg, err)
+	}
+}
+
+func Test_SenderReceiverConformance(t *testing.T) {
+	messagebustest.RunSenderReceiverSuite(t, func(t *testing.T) messagebustest.Connector {
+		config := startServer(t)
+		stream := natsadapter.DefaultStreamConfig("queue")
+		return func(direction messagebustypes.Direction) messagebustest.Conn {
+			return initialize(t, config, stream, direction)
+		}
+	})
+}
+
+func Test_Envelope(t *testing.T) {
+	config := startServer(t)
+	api := initialize(t, config, natsadapter.DefaultStreamConfig("queue"), messagebustypes.DirectionSendReceive)
+
+	// The server discards a publish with the MessageID of a recently stored
+	// message.
+	sent := &messagebustypes.Envelope{MessageID: "id"}
+	for i := 0; i < 2; i++ {
+		if err := api.SendEnvelope(sent, []byte("a")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receive(t, api, "a")
+	expectEmpty(t, api)
+
+	reserved := &messagebustypes.Envelope{Headers: map[string]string{natsadapter.HeaderMessageType: "x"}}
+	if err := api.SendEnvelope(reserved, []byte("b")); err == nil {
+		t.Fatal("expected an error when sending a reserved header")
+	}
+}
+
+func Test_MaxDeliveryAttempts(t *testing.T) {
+	config := startServer(t)
+	stream := natsadapter.DefaultStreamConfig("queue")
+	stream.MaxDeliveryAttempts = 2
+	api := initialize(t, config, stream, messagebustypes.DirectionSendReceive)
+	send(t, api, "a")
+
+	for i := 0; i < stream.MaxDeliveryAttempts; i++ {
+		msg := receive(t, api, "a")
+		if err := msg.Acknowledger.Nack(true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectEmpty(t, api)
+}
+
+func Test_InspectCountsReceivers(t *testing.T) {
+	config := startServer(t)
+	stream := natsadapter.DefaultStreamConfig("queue")
+	sender := initialize(t, config, stream, messagebustypes.DirectionSendOnly)
+
+	expectConsumers := func(consumers int) {
+		t.Helper()
+		info, err := sender.Inspect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Consumers != consumers {
+			t.Fatalf("expected %v consumers, got %v", consumers, info)
+		}
+	}
+
+	expectConsumers(0)
+	first := initialize(t, config, stream, messagebustypes.DirectionReceiveOnly)
+	initialize(t, config, stream, messagebustypes.DirectionSendReceive)
+	expectConsumers(2)
+
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expectConsumers(1)
+}
@@ -0,0 +1,176 @@
+package natsadapter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// StreamConfig is a configuration for the JetStream stream that holds a
+// queue's messages, and for the durable pull consumer from which the queue's
+// messages are received. An existing stream or consumer is used as is, so it
+// must be deleted before its configuration can be changed.
+type StreamConfig struct {
+	// Name is the name of the stream, and of its durable consumer.
+	Name string
+
+	// Subject is the subject to which messages are published. If empty,
+	// SubjectPrefix followed by Name is used.
+	Subject string
+
+	// Memory stores the stream's messages in memory rather than on disk.
+	// Messages stored in memory are lost if the server restarts.
+	Memory bool
+
+	// Replicas is the number of servers in a cluster that hold a copy of the
+	// stream. If zero, one copy is held.
+	Replicas int
+
+	// MaxAge is the length of time a message may wait in the stream before
+	// it's discarded. If zero, messages don't expire.
+	MaxAge time.Duration
+
+	// MaxMsgs is the maximum number of unacknowledged messages in the stream.
+	// Once reached, new messages are rejected. If zero, the stream's length is
+	// unbounded.
+	MaxMsgs int64
+
+	// AckWait is the length of time the server waits for a received message
+	// to be acknowledged before redelivering it. If zero, the server's default
+	// of 30 seconds is used.
+	AckWait time.Duration
+
+	// MaxDeliveryAttempts is the number of times a message is delivered
+	// before the server stops redelivering it. If zero, messages are
+	// redelivered indefinitely.
+	MaxDeliveryAttempts int
+}
+
+// SubjectPrefix is the prefix of the default subject of each stream.
+const SubjectPrefix = "tbtl."
+
+// DefaultMaxDeliveryAttempts is the MaxDeliveryAttempts used by
+// DefaultStreamConfig.
+const DefaultMaxDeliveryAttempts = 5
+
+const completedResearchQueueName = "completed_research"
+
+// DefaultStreamConfig returns the configuration used for the named queue.
+// The completed-research stream is stored on disk, because completed
+// research can take minutes per episode to reproduce. Other streams are held
+// in memory, since their contents are regenerated from the datastore.
+func DefaultStreamConfig(name string) *StreamConfig {
+	return &StreamConfig{
+		Name:                name,
+		Memory:              name != completedResearchQueueName,
+		MaxDeliveryAttempts: DefaultMaxDeliveryAttempts,
+	}
+}
+
+// Stream names may not contain whitespace, periods, or wildcards.
+var streamNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Validate reports whether the stream configuration is complete and
+// internally consistent.
+func (s *StreamConfig) Validate() error {
+	if !streamNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("stream config: Name must be non-empty and contain only letters, digits, underscores, and hyphens, got %q", s.Name)
+	}
+	if s.Replicas < 0 {
+		return fmt.Errorf("stream config: Replicas must not be negative, got %v", s.Replicas)
+	}
+	if s.MaxAge < 0 {
+		return fmt.Errorf("stream config: MaxAge must not be negative, got %v", s.MaxAge)
+	}
+	if s.MaxMsgs < 0 {
+		return fmt.Errorf("stream config: MaxMsgs must not be negative, got %v", s.MaxMsgs)
+	}
+	if s.AckWait < 0 {
+		return fmt.Errorf("stream config: AckWait must not be negative, got %v", s.AckWait)
+	}
+	if s.MaxDeliveryAttempts < 0 {
+		return errors.New("stream config: MaxDeliveryAttempts must not be negative")
+	}
+	return nil
+}
+
+func (s *StreamConfig) subject() string {
+	if s.Subject == "" {
+		return SubjectPrefix + s.Name
+	}
+	return s.Subject
+}
+
+// presenceSubject is the subject on which the stream's receivers answer
+// presence requests. It's outside of the stream's subjects, so presence
+// requests aren't stored.
+func (s *StreamConfig) presenceSubject() string {
+	return "_TBTL.PRESENCE." + s.Name
+}
+
+// streamConfig returns the configuration with which the stream is declared.
+// The stream has work-queue retention, so a message is removed once it's
+// acknowledged.
+func (s *StreamConfig) streamConfig() *nats.StreamConfig {
+	storage := nats.FileStorage
+	if s.Memory {
+		storage = nats.MemoryStorage
+	}
+
+	maxMsgs := s.MaxMsgs
+	if maxMsgs == 0 {
+		maxMsgs = -1
+	}
+
+	return &nats.StreamConfig{
+		Name:      s.Name,
+		Subjects:  []string{s.subject()},
+		Retention: nats.WorkQueuePolicy,
+		Storage:   storage,
+		Replicas:  s.Replicas,
+		MaxAge:    s.MaxAge,
+		MaxMsgs:   maxMsgs,
+		Discard:   nats.DiscardNew,
+	}
+}
+
+// consumerConfig returns the configuration with which the stream's durable
+// pull consumer is declared.
+func (s *StreamConfig) consumerConfig() *nats.ConsumerConfig {
+	maxDeliver := s.MaxDeliveryAttempts
+	if maxDeliver == 0 {
+		maxDeliver = -1
+	}
+
+	return &nats.ConsumerConfig{
+		Durable:       s.Name,
+		DeliverPolicy: nats.DeliverAllPolicy,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       s.AckWait,
+		MaxDeliver:    maxDeliver,
+		FilterSubject: s.subject(),
+	}
+}
+
+// declare creates the stream and its consumer if they don't already exist.
+func (s *StreamConfig) declare(js nats.JetStreamContext) error {
+	_, err := js.StreamInfo(s.Name)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = js.AddStream(s.streamConfig())
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to declare stream %v: %w", s.Name, err)
+	}
+
+	_, err = js.ConsumerInfo(s.Name, s.Name)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		_, err = js.AddConsumer(s.Name, s.consumerConfig())
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to declare consumer %v: %w", s.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package redisadapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/acknowledger"
+)
+
+// Acknowledger acknowledges a single message that was received from a
+// stream.
+type Acknowledger struct {
+	api      *API
+	delivery *delivery
+}
+
+// Ack acknowledges that a message has been received, which removes it from
+// the stream.
+func (a *Acknowledger) Ack() error {
+	if !a.api.settle(a.delivery) {
+		return a.unknown()
+	}
+	return a.api.remove(context.Background(), a.delivery, "", nil)
+}
+
+// Nack negatively acknowledges that a message has been received.
+//
+// If the stream has a MaxDeliveryAttempts, a requeued message is added to
+// the back of the stream with its delivery attempt count, and once the count
+// reaches the maximum, the message is moved to the dead-letter stream
+// instead. A message that is not requeued is moved to the dead-letter stream
+// immediately. If the stream has no MaxDeliveryAttempts, requeued messages
+// are always added to the back of the stream, and other messages are
+// discarded.
+func (a *Acknowledger) Nack(requeue bool) error {
+	if !a.api.settle(a.delivery) {
+		return a.unknown()
+	}
+
+	ctx := context.Background()
+	maxAttempts := a.api.stream.MaxDeliveryAttempts
+	switch {
+	case requeue && (maxAttempts == 0 || a.delivery.attempts < maxAttempts):
+		return a.api.requeue(ctx, a.delivery, a.delivery.attempts)
+	case maxAttempts == 0:
+		return a.api.remove(ctx, a.delivery, "", nil)
+	case requeue:
+		return a.api.deadLetter(ctx, a.delivery, fmt.Sprintf("exceeded %v delivery attempts", maxAttempts))
+	default:
+		return a.api.deadLetter(ctx, a.delivery, "rejected")
+	}
+}
+
+// unknown returns the error for a message that has already been
+// acknowledged, or that was requeued because its API was closed.
+func (a *Acknowledger) unknown() error {
+	return fmt.Errorf("unknown delivery %v", a.delivery.id)
+}
+
+var _ acknowledger.AckNack = (*Acknowledger)(nil)
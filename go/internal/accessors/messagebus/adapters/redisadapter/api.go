@@ -0,0 +1,443 @@
+package redisadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/redis/go-redis/v9"
+)
+
+// blockInterval is the length of time for which ReceiveContext blocks on the
+// server before checking whether its context is done, and whether any
+// messages can be reclaimed. ReceiveContext blocks for less time if its
+// context's deadline is sooner.
+const blockInterval = time.Second
+
+// API is a connection to a single Redis stream. This should be instantiated
+// via the Initialize function.
+//
+// Each API is a distinct consumer in the stream's consumer group, so each
+// message is delivered to only one receiver. Before reading new messages, a
+// receiver reclaims messages that other consumers have held for longer than
+// the stream's ClaimIdle.
+type API struct {
+	config    *Config
+	stream    *StreamConfig
+	direction messagebustypes.Direction
+	client    *redis.Client
+	consumer  string
+
+	mu     sync.Mutex
+	closed bool
+
+	// inFlight holds the messages that were received but not yet
+	// acknowledged, keyed by entry ID, so that they can be returned to the
+	// stream on Close.
+	inFlight map[string]*delivery
+}
+
+// A delivery is a stream entry that was delivered to the API.
+type delivery struct {
+	id     string
+	values map[string]interface{}
+
+	// attempts is the number of times the message has been delivered,
+	// including this delivery.
+	attempts int
+}
+
+// Initialize establishes a connection with the server described by config,
+// then creates the stream and consumer group described by stream if they
+// don't already exist. The connection is closed once ctx is done.
+func Initialize(ctx context.Context, config *Config, stream *StreamConfig, direction messagebustypes.Direction) (*API, error) {
+	if err := direction.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := stream.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := config.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stream.declare(ctx, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	consumer := uuid.New().String()
+	if config.ConnectionName != "" {
+		consumer = config.ConnectionName + "-" + consumer
+	}
+
+	a := &API{
+		config:    config,
+		stream:    stream,
+		direction: direction,
+		client:    client,
+		consumer:  consumer,
+		inFlight:  map[string]*delivery{},
+	}
+
+	go func() {
+		<-ctx.Done()
+		a.Close()
+	}()
+
+	return a, nil
+}
+
+// Close closes the connection to the server. Any messages that were received
+// by the API but not yet acknowledged are returned to the stream, and the
+// API's consumer is removed from the consumer group. Calling Close more than
+// once has no effect.
+func (a *API) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	ctx := context.Background()
+	var firstErr error
+	for _, d := range a.inFlight {
+		err := a.requeue(ctx, d, d.attempts-1)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	a.inFlight = nil
+
+	if a.direction.CanReceive() && firstErr == nil {
+		// Removing a consumer discards its pending messages, so the consumer
+		// is left for reclaiming if any messages couldn't be requeued.
+		firstErr = a.client.XGroupDelConsumer(ctx, a.stream.key(), a.stream.Name, a.consumer).Err()
+	}
+
+	err := a.client.Close()
+	if firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+func (a *API) isClosed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.closed
+}
+
+// Send transmits a message to the stream with a default envelope. See
+// SendEnvelope.
+func (a *API) Send(msg []byte) error {
+	return a.SendEnvelope(nil, msg)
+}
+
+// SendEnvelope transmits a message to the stream with the supplied envelope.
+// This method will panic if the API was initialized as receive-only.
+func (a *API) SendEnvelope(envelope *messagebustypes.Envelope, msg []byte) error {
+	if !a.direction.CanSend() {
+		panic("Cannot send on a receive-only connection.")
+	}
+	if a.isClosed() {
+		return messagebustypes.ErrClosed
+	}
+
+	ctx := context.Background()
+	pipe := a.client.Pipeline()
+	a.stream.add(ctx, pipe, a.stream.key(), newValues(envelope, msg))
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		err = fmt.Errorf("Failed to publish a message: %w", err)
+	}
+	return err
+}
+
+// Inspect returns the number of messages that have not yet been delivered
+// (not including messages that have been delivered but not acknowledged),
+// and the number of consumers that have read from the stream within the
+// stream's ClaimIdle.
+func (a *API) Inspect() (*messagebustypes.QueueInfo, error) {
+	if a.isClosed() {
+		return nil, messagebustypes.ErrClosed
+	}
+
+	ctx := context.Background()
+	length, err := a.client.XLen(ctx, a.stream.key()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := a.client.XInfoGroups(ctx, a.stream.key()).Result()
+	if err != nil {
+		return nil, err
+	}
+	pending := int64(0)
+	for _, group := range groups {
+		if group.Name == a.stream.Name {
+			pending = group.Pending
+		}
+	}
+
+	consumers, err := a.client.XInfoConsumers(ctx, a.stream.key(), a.stream.Name).Result()
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, consumer := range consumers {
+		if consumer.Idle < a.stream.claimIdle() {
+			active++
+		}
+	}
+
+	messages := int(length - pending)
+	if messages < 0 {
+		messages = 0
+	}
+	return &messagebustypes.QueueInfo{
+		Messages:  messages,
+		Consumers: active,
+	}, nil
+}
+
+// Receive retrieves a message from the stream. This method does not block.
+// If no message is available the method will return nil. The message
+// remains pending until it is acknowledged via its Acknowledger, until the
+// API is closed, or until it's reclaimed by another consumer. This method
+// will panic if the API was initialized as send-only.
+func (a *API) Receive() (*messagebustypes.Message, error) {
+	if !a.direction.CanReceive() {
+		panic("Cannot receive from a send-only connection.")
+	}
+	return a.tryReceive(context.Background(), -1)
+}
+
+// ReceiveContext retrieves a message from the stream, blocking until a
+// message is available or ctx is done. This method will panic if the API was
+// initialized as send-only.
+func (a *API) ReceiveContext(ctx context.Context) (*messagebustypes.Message, error) {
+	if !a.direction.CanReceive() {
+		panic("Cannot receive from a send-only connection.")
+	}
+
+	for {
+		block := blockInterval
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < block {
+			// The server blocks indefinitely for a block of zero, so a
+			// block is at least a millisecond.
+			block = time.Until(deadline)
+			if block < time.Millisecond {
+				block = time.Millisecond
+			}
+		}
+
+		msg, err := a.tryReceive(ctx, block)
+		if msg != nil {
+			return msg, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tryReceive reclaims a message from another consumer, or failing that reads
+// a new message, blocking for up to block. A negative block doesn't block.
+func (a *API) tryReceive(ctx context.Context, block time.Duration) (*messagebustypes.Message, error) {
+	if a.isClosed() {
+		return nil, messagebustypes.ErrClosed
+	}
+
+	d, err := a.reclaim(ctx)
+	if err != nil {
+		return nil, a.closedOr(err)
+	}
+	if d != nil {
+		return a.deliver(d), nil
+	}
+
+	streams, err := a.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    a.stream.Name,
+		Consumer: a.consumer,
+		Streams:  []string{a.stream.key(), ">"},
+		Count:    1,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.closedOr(err)
+	}
+
+	for _, stream := range streams {
+		for _, entry := range stream.Messages {
+			return a.deliver(&delivery{
+				id:       entry.ID,
+				values:   entry.Values,
+				attempts: intValue(entry.Values, fieldAttempts) + 1,
+			}), nil
+		}
+	}
+	return nil, nil
+}
+
+// closedOr returns ErrClosed if the API was closed while a command was in
+// progress, and err otherwise.
+func (a *API) closedOr(err error) error {
+	if a.isClosed() {
+		return messagebustypes.ErrClosed
+	}
+	return err
+}
+
+// reclaim claims a message that another consumer has held for longer than
+// the stream's ClaimIdle. Reclaimed messages that have exhausted the
+// stream's MaxDeliveryAttempts are dead-lettered rather than returned.
+func (a *API) reclaim(ctx context.Context) (*delivery, error) {
+	for {
+		entries, _, err := a.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   a.stream.key(),
+			Group:    a.stream.Name,
+			Consumer: a.consumer,
+			MinIdle:  a.stream.claimIdle(),
+			Start:    "0-0",
+			Count:    1,
+		}).Result()
+		if err != nil || len(entries) == 0 {
+			return nil, err
+		}
+		entry := entries[0]
+
+		if len(entry.Values) == 0 {
+			// The entry was deleted while it was pending.
+			err = a.client.XAck(ctx, a.stream.key(), a.stream.Name, entry.ID).Err()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		pending, err := a.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: a.stream.key(),
+			Group:  a.stream.Name,
+			Start:  entry.ID,
+			End:    entry.ID,
+			Count:  1,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		deliveries := 1
+		if len(pending) > 0 {
+			deliveries = int(pending[0].RetryCount)
+		}
+
+		d := &delivery{
+			id:       entry.ID,
+			values:   entry.Values,
+			attempts: intValue(entry.Values, fieldAttempts) + deliveries,
+		}
+		if a.stream.MaxDeliveryAttempts == 0 || d.attempts <= a.stream.MaxDeliveryAttempts {
+			return d, nil
+		}
+
+		reason := fmt.Sprintf("exceeded %v delivery attempts", a.stream.MaxDeliveryAttempts)
+		err = a.deadLetter(ctx, d, reason)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// deliver records that a message is in flight, and returns it to the caller.
+func (a *API) deliver(d *delivery) *messagebustypes.Message {
+	if d == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.inFlight != nil {
+		a.inFlight[d.id] = d
+	}
+
+	body := []byte(stringValue(d.values, fieldBody))
+	return &messagebustypes.Message{
+		Envelope: newEnvelope(d.values),
+		Body:     body,
+		Acknowledger: &Acknowledger{
+			api:      a,
+			delivery: d,
+		},
+	}
+}
+
+// settle forgets a message that is being acknowledged, reporting whether the
+// message was in flight.
+func (a *API) settle(d *delivery) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, found := a.inFlight[d.id]
+	delete(a.inFlight, d.id)
+	return found
+}
+
+// remove acknowledges and deletes an entry, after adding the supplied
+// values to the stream with the supplied key (if values isn't nil). The
+// operations are applied atomically.
+func (a *API) remove(ctx context.Context, d *delivery, key string, values map[string]interface{}) error {
+	_, err := a.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if values != nil {
+			a.stream.add(ctx, pipe, key, values)
+		}
+		pipe.XAck(ctx, a.stream.key(), a.stream.Name, d.id)
+		pipe.XDel(ctx, a.stream.key(), d.id)
+		return nil
+	})
+	return err
+}
+
+// requeue adds a copy of a delivered message to the back of the stream, with
+// the supplied number of prior delivery attempts, and removes the original.
+func (a *API) requeue(ctx context.Context, d *delivery, attempts int) error {
+	values := copyValues(d.values)
+	values[fieldAttempts] = attempts
+	return a.remove(ctx, d, a.stream.key(), values)
+}
+
+// deadLetter moves a delivered message to the stream's dead-letter stream.
+func (a *API) deadLetter(ctx context.Context, d *delivery, reason string) error {
+	values := copyValues(d.values)
+	values[fieldAttempts] = d.attempts
+	values[fieldOriginalStream] = a.stream.key()
+	values[fieldDeadLetterReason] = reason
+	values[fieldDeadLetteredAt] = time.Now().UTC().Format(time.RFC3339Nano)
+	return a.remove(ctx, d, a.stream.DeadLetterKey(), values)
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+var _ messagebus.SenderReceiver = (*API)(nil)
@@ -0,0 +1,98 @@
+package redisadapter
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Config is a configuration for a connection to a Redis server.
+type Config struct {
+	// Addr is the host and port of the server, such as localhost:6379.
+	Addr string
+
+	// DB is the number of the database that holds the streams.
+	DB int
+
+	// User is the username presented to the server. If empty, the default
+	// user is assumed.
+	User string
+
+	// At most one of Password or PasswordEnv may be set. PasswordEnv names an
+	// environment variable that holds the password.
+	Password    string
+	PasswordEnv string
+
+	// TLS connects to the server over TLS, verifying the server against the
+	// host's root CAs.
+	TLS bool
+
+	// ConnectionName is advertised to the server so that the connection can
+	// be identified via CLIENT LIST. It also prefixes the names of the API's
+	// consumers.
+	ConnectionName string
+}
+
+// Validate reports whether the configuration is complete and internally
+// consistent. Validate does not read credentials from the environment.
+func (c *Config) Validate() error {
+	if c.Addr == "" {
+		return errors.New("redis config: Addr is required")
+	}
+	_, _, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		return fmt.Errorf("redis config: invalid Addr %q: %v", c.Addr, err)
+	}
+	if c.DB < 0 {
+		return fmt.Errorf("redis config: DB must not be negative, got %v", c.DB)
+	}
+	if c.Password != "" && c.PasswordEnv != "" {
+		return errors.New("redis config: only one of Password or PasswordEnv may be set")
+	}
+	return nil
+}
+
+// connect validates the configuration, resolves credentials, and verifies
+// that the server is reachable.
+func (c *Config) connect(ctx context.Context) (*redis.Client, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	password := c.Password
+	if c.PasswordEnv != "" {
+		password = os.Getenv(c.PasswordEnv)
+		if password == "" {
+			return nil, fmt.Errorf("redis config: environment variable %v is not set", c.PasswordEnv)
+		}
+	}
+
+	options := &redis.Options{
+		Addr:       c.Addr,
+		DB:         c.DB,
+		Username:   c.User,
+		Password:   password,
+		ClientName: c.ConnectionName,
+	}
+	if c.TLS {
+		host, _, _ := net.SplitHostPort(c.Addr)
+		options.TLSConfig = &tls.Config{ServerName: host}
+	}
+
+	client := redis.NewClient(options)
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err = client.Ping(pingCtx).Err()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to %v: %w", c.Addr, err)
+	}
+	return client, nil
+}
@@ -0,0 +1,61 @@
+package redisadapter_test
+
+import (
+	"testing"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/redisadapter"
+)
+
+func Test_ConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config redisadapter.Config
+		valid  bool
+	}{
+		{
+			name:   "minimal",
+			config: redisadapter.Config{Addr: "localhost:6379"},
+			valid:  true,
+		},
+		{
+			name: "complete",
+			config: redisadapter.Config{
+				Addr:           "redis.example.com:6380",
+				DB:             2,
+				User:           "archivist",
+				PasswordEnv:    "REDIS_PASSWORD",
+				TLS:            true,
+				ConnectionName: "test",
+			},
+			valid: true,
+		},
+		{
+			name:   "missing addr",
+			config: redisadapter.Config{},
+		},
+		{
+			name:   "missing port",
+			config: redisadapter.Config{Addr: "localhost"},
+		},
+		{
+			name:   "negative db",
+			config: redisadapter.Config{Addr: "localhost:6379", DB: -1},
+		},
+		{
+			name:   "multiple passwords",
+			config: redisadapter.Config{Addr: "localhost:6379", Password: "a", PasswordEnv: "B"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.config.Validate()
+			if testCase.valid && err != nil {
+				t.Fatalf("expected a valid config, got %v", err)
+			}
+			if !testCase.valid && err == nil {
+				t.Fatal("expected an invalid config")
+			}
+		})
+	}
+}
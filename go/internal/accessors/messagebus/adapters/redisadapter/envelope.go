@@ -0,0 +1,72 @@
+package redisadapter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+// The fields of a stream entry, in addition to the envelope's fields (see
+// Envelope.EncodeFields). An envelope's headers are stored as fields prefixed
+// with fieldHeaderPrefix, so they can't collide with the other fields.
+const (
+	fieldBody         = "body"
+	fieldHeaderPrefix = "header:"
+
+	// fieldAttempts is the number of times the message was delivered before
+	// it was requeued as this entry.
+	fieldAttempts = "attempts"
+
+	// Fields that are added to an entry when it's moved to a dead-letter
+	// stream.
+	fieldOriginalStream   = "original-stream"
+	fieldDeadLetterReason = "dead-letter-reason"
+	fieldDeadLetteredAt   = "dead-lettered-at"
+)
+
+// newValues maps an envelope and body onto the fields of a stream entry. The
+// envelope's missing fields are supplied via WithDefaults.
+func newValues(envelope *messagebustypes.Envelope, body []byte) map[string]interface{} {
+	envelope = envelope.WithDefaults()
+
+	values := map[string]interface{}{
+		fieldBody: body,
+	}
+	for field, value := range envelope.EncodeFields() {
+		values[field] = value
+	}
+	for key, value := range envelope.Headers {
+		values[fieldHeaderPrefix+key] = value
+	}
+	return values
+}
+
+// newEnvelope maps the fields of a stream entry onto an envelope. Fields that
+// can't be parsed are ignored.
+func newEnvelope(values map[string]interface{}) messagebustypes.Envelope {
+	headers := map[string]string{}
+	for key := range values {
+		if strings.HasPrefix(key, fieldHeaderPrefix) {
+			headers[strings.TrimPrefix(key, fieldHeaderPrefix)] = stringValue(values, key)
+		}
+	}
+
+	return messagebustypes.DecodeFields(headers, func(field string) string {
+		return stringValue(values, field)
+	})
+}
+
+// stringValue returns the value of a field, or an empty string if the field
+// isn't present.
+func stringValue(values map[string]interface{}, field string) string {
+	value, _ := values[field].(string)
+	return value
+}
+
+// intValue returns the value of an integer field, or zero if the field isn't
+// present.
+func intValue(values map[string]interface{}, field string) int {
+	value, _ := strconv.Atoi(stringValue(values, field))
+	return value
+}
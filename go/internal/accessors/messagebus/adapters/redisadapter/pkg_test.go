@@ -0,0 +1,130 @@
+package redisadapter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/redisadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustest"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+// startServer starts an in-process Redis stand-in, and returns a
+// configuration for connecting to it.
+func startServer(t *testing.T) (*miniredis.Miniredis, *redisadapter.Config) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	return server, &redisadapter.Config{
+		Addr:           server.Addr(),
+		ConnectionName: "test",
+	}
+}
+
+func initialize(t *testing.T, config *redisadapter.Config, stream *redisadapter.StreamConfig, direction messagebustypes.Direction) *redisadapter.API {
+	t.Helper()
+	api, err := redisadapter.Initialize(context.Background(), config, stream, direction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { api.Close() })
+	return api
+}
+
+func send(t *testing.T, api *redisadapter.API, bodies ...string) {
+	t.Helper()
+	for _, body := range bodies {
+		if err := api.Send([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func receive(t *testing.T, api *redisadapter.API, body string) *messagebustypes.Message {
+	t.Helper()
+	msg, err := api.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg == nil {
+		t.Fatalf("expected message %q", body)
+	}
+	if string(msg.Body) != body {
+		t.Fatalf("expected message %q, got %q", body, msg.Body)
+	}
+	return msg
+}
+
+func expectEmpty(t *testing.T, api *redisadapter.API) {
+	t.Helper()
+	if msg, err := api.Receive(); msg != nil || err != nil {
+		t.Fatalf("expected nil, nil from an empty stream, got %v, %v", msg, err)
+	}
+}
+
+func Test_SenderReceiverConformance(t *testing.T) {
+	messagebustest.RunSenderReceiverSuite(t, func(t *testing.T) messagebustest.Connector {
+		_, config := startServer(t)
+		stream := redisadapter.DefaultStreamConfig("queue")
+		return func(direction messagebustypes.Direction) messagebustest.Conn {
+			return initialize(t, config, stream, direction)
+		}
+	})
+}
+
+func Test_Nack(t *testing.T) {
+	server, config := startServer(t)
+	stream := redisadapter.DefaultStreamConfig("queue")
+	stream.MaxDeliveryAttempts = 2
+	api := initialize(t, config, stream, messagebustypes.DirectionSendReceive)
+	send(t, api, "a", "b")
+
+	// A requeued message is added to the back of the stream, and is
+	// dead-lettered once it exhausts its delivery attempts.
+	for _, body := range []string{"a", "b", "a"} {
+		msg := receive(t, api, body)
+		if err := msg.Acknowledger.Nack(true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A rejected message is dead-lettered immediately.
+	msg := receive(t, api, "b")
+	if err := msg.Acknowledger.Nack(false); err != nil {
+		t.Fatal(err)
+	}
+	expectEmpty(t, api)
+
+	dead, err := server.Stream(stream.DeadLetterKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 2 {
+		t.Fatalf("expected 2 dead letters, got %v", dead)
+	}
+}
+
+func Test_Reclaim(t *testing.T) {
+	server, config := startServer(t)
+	stream := redisadapter.DefaultStreamConfig("queue")
+	stream.ClaimIdle = 10 * time.Millisecond
+	stream.MaxDeliveryAttempts = 2
+	dead := initialize(t, config, stream, messagebustypes.DirectionSendReceive)
+	alive := initialize(t, config, stream, messagebustypes.DirectionReceiveOnly)
+	send(t, dead, "a")
+
+	// Messages held by a consumer that stops responding are reclaimed once
+	// they've been idle for ClaimIdle, and are dead-lettered rather than
+	// reclaimed once they exhaust their delivery attempts.
+	receive(t, dead, "a")
+	expectEmpty(t, alive)
+	time.Sleep(2 * stream.ClaimIdle)
+	receive(t, alive, "a")
+	time.Sleep(2 * stream.ClaimIdle)
+	expectEmpty(t, dead)
+
+	if entries, err := server.Stream(stream.DeadLetterKey()); err != nil || len(entries) != 1 {
+		t.Fatalf("expected 1 dead letter, got %v, %v", entries, err)
+	}
+}
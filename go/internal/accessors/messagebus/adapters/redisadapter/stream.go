@@ -0,0 +1,121 @@
+package redisadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamConfig is a configuration for the Redis stream that holds a queue's
+// messages, and for the consumer group from which the queue's messages are
+// received.
+type StreamConfig struct {
+	// Name identifies the queue. It's also the name of the consumer group.
+	Name string
+
+	// Key is the key of the stream. If empty, KeyPrefix followed by Name is
+	// used.
+	Key string
+
+	// ClaimIdle is the length of time a message may be held by a consumer
+	// without being acknowledged before another consumer reclaims it. This
+	// recovers messages held by consumers that died. If zero,
+	// DefaultClaimIdle is used.
+	ClaimIdle time.Duration
+
+	// MaxLen is the approximate maximum number of entries in the stream. Once
+	// reached, the oldest entries are trimmed, even if they haven't been
+	// delivered. If zero, the stream's length is unbounded.
+	MaxLen int64
+
+	// MaxDeliveryAttempts is the number of times a message may be delivered
+	// before it's moved to the queue's dead-letter stream (see
+	// DeadLetterKey). If zero, messages are redelivered indefinitely and
+	// rejected messages are discarded.
+	MaxDeliveryAttempts int
+}
+
+// KeyPrefix is the prefix of the default key of each stream.
+const KeyPrefix = "tbtl:"
+
+// DefaultClaimIdle is used if a StreamConfig doesn't specify a ClaimIdle.
+const DefaultClaimIdle = time.Minute
+
+// DefaultMaxDeliveryAttempts is the MaxDeliveryAttempts used by
+// DefaultStreamConfig.
+const DefaultMaxDeliveryAttempts = 5
+
+// DefaultStreamConfig returns the configuration used for the named queue.
+func DefaultStreamConfig(name string) *StreamConfig {
+	return &StreamConfig{
+		Name:                name,
+		MaxDeliveryAttempts: DefaultMaxDeliveryAttempts,
+	}
+}
+
+// Validate reports whether the stream configuration is complete and
+// internally consistent.
+func (s *StreamConfig) Validate() error {
+	if s.Name == "" {
+		return errors.New("stream config: Name is required")
+	}
+	if s.ClaimIdle < 0 {
+		return fmt.Errorf("stream config: ClaimIdle must not be negative, got %v", s.ClaimIdle)
+	}
+	if s.MaxLen < 0 {
+		return fmt.Errorf("stream config: MaxLen must not be negative, got %v", s.MaxLen)
+	}
+	if s.MaxDeliveryAttempts < 0 {
+		return fmt.Errorf("stream config: MaxDeliveryAttempts must not be negative, got %v", s.MaxDeliveryAttempts)
+	}
+	return nil
+}
+
+func (s *StreamConfig) key() string {
+	if s.Key == "" {
+		return KeyPrefix + s.Name
+	}
+	return s.Key
+}
+
+func (s *StreamConfig) claimIdle() time.Duration {
+	if s.ClaimIdle == 0 {
+		return DefaultClaimIdle
+	}
+	return s.ClaimIdle
+}
+
+// DeadLetterKey returns the key of the stream to which a queue's dead
+// letters are moved.
+func (s *StreamConfig) DeadLetterKey() string {
+	return s.key() + ":dead"
+}
+
+// declare creates the stream and its consumer group if they don't already
+// exist. A new group starts at the beginning of the stream, so that messages
+// sent before any receiver started are delivered.
+func (s *StreamConfig) declare(ctx context.Context, client *redis.Client) error {
+	err := client.XGroupCreateMkStream(ctx, s.key(), s.Name, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("Failed to declare consumer group %v: %w", s.Name, err)
+	}
+	return nil
+}
+
+// add appends a message to the stream, trimming the stream if it has a
+// MaxLen.
+func (s *StreamConfig) add(ctx context.Context, pipe redis.Cmdable, key string, values map[string]interface{}) {
+	args := &redis.XAddArgs{
+		Stream: key,
+		Values: values,
+	}
+	if key == s.key() && s.MaxLen > 0 {
+		args.MaxLen = s.MaxLen
+		args.Approx = true
+	}
+	pipe.XAdd(ctx, args)
+}
@@ -1,18 +1,32 @@
 package messagebus
 
-import "github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+import (
+	"context"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
 
 // A Sender is anything that is capable of transmitting a message to a message
 // bus.
 type Sender interface {
+	// Send transmits a message body with a default envelope.
 	Send([]byte) error
+
+	// SendEnvelope transmits a message body with the supplied envelope.
+	SendEnvelope(*messagebustypes.Envelope, []byte) error
+
 	Inspect() (*messagebustypes.QueueInfo, error)
 }
 
 // A Receiver is anything that is capable of consuming a message from a message
 // bus.
 type Receiver interface {
+	// Receive returns a message if one is waiting, and returns nil otherwise.
 	Receive() (*messagebustypes.Message, error)
+
+	// ReceiveContext blocks until a message is available, or until ctx is
+	// done, in which case ctx.Err() is returned.
+	ReceiveContext(ctx context.Context) (*messagebustypes.Message, error)
 }
 
 // SenderReceiver is anything that is capable of sending and receiving messages
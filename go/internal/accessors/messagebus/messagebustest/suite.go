@@ -0,0 +1,262 @@
+// Package messagebustest provides a conformance suite that can be run against
+// any message bus adapter. Each adapter should invoke RunSenderReceiverSuite
+// from its own tests so that all adapters are held to the same behavior.
+package messagebustest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+)
+
+// A Conn is a connection to a queue, such as an adapter's API.
+type Conn interface {
+	messagebus.SenderReceiver
+	Close() error
+}
+
+// A Connector opens a connection to a queue with the supplied direction.
+// Every connection opened by a Connector is to the same queue.
+type Connector func(direction messagebustypes.Direction) Conn
+
+// A Factory returns a Connector for a new, empty queue. The factory is called
+// once for each test in the suite. Any cleanup the connections require should
+// be registered via t.Cleanup.
+type Factory func(t *testing.T) Connector
+
+// RunSenderReceiverSuite runs every conformance test against queues produced
+// by newQueue.
+func RunSenderReceiverSuite(t *testing.T, newQueue Factory) {
+	tests := []struct {
+		name string
+		test func(*testing.T, Connector)
+	}{
+		{"SendReceiveAck", testSendReceiveAck},
+		{"Envelope", testEnvelope},
+		{"Nack", testNack},
+		{"CloseRequeuesUnacknowledgedMessages", testCloseRequeuesUnacknowledgedMessages},
+		{"ReceiveContext", testReceiveContext},
+		{"ReceiveContextStopsWhenContextIsDone", testReceiveContextStopsWhenContextIsDone},
+		{"ReceiveContextStopsWhenClosed", testReceiveContextStopsWhenClosed},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, newQueue(t))
+		})
+	}
+}
+
+func send(t *testing.T, conn Conn, bodies ...string) {
+	t.Helper()
+	for _, body := range bodies {
+		if err := conn.Send([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func receive(t *testing.T, conn Conn, body string) *messagebustypes.Message {
+	t.Helper()
+	msg, err := conn.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg == nil {
+		t.Fatalf("expected message %q", body)
+	}
+	if string(msg.Body) != body {
+		t.Fatalf("expected message %q, got %q", body, msg.Body)
+	}
+	return msg
+}
+
+func expectEmpty(t *testing.T, conn Conn) {
+	t.Helper()
+	if msg, err := conn.Receive(); msg != nil || err != nil {
+		t.Fatalf("expected nil, nil from an empty queue, got %v, %v", msg, err)
+	}
+}
+
+func expectMessages(t *testing.T, conn Conn, messages int) {
+	t.Helper()
+	info, err := conn.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Messages != messages {
+		t.Fatalf("expected %v messages, got %v", messages, info)
+	}
+}
+
+func testSendReceiveAck(t *testing.T, connect Connector) {
+	sender := connect(messagebustypes.DirectionSendOnly)
+	receiver := connect(messagebustypes.DirectionReceiveOnly)
+
+	expectEmpty(t, receiver)
+	send(t, sender, "a", "b")
+	expectMessages(t, sender, 2)
+
+	// Messages are delivered in the order they were sent, and a delivered
+	// message isn't counted while it's unacknowledged.
+	msg := receive(t, receiver, "a")
+	info, err := sender.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Messages != 1 || info.Consumers != 1 {
+		t.Fatalf("expected 1 message and 1 consumer, got %v", info)
+	}
+
+	if err := msg.Acknowledger.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.Acknowledger.Ack(); err == nil {
+		t.Fatal("expected an error when acknowledging a message twice")
+	}
+
+	msg = receive(t, receiver, "b")
+	if err := msg.Acknowledger.Ack(); err != nil {
+		t.Fatal(err)
+	}
+	expectEmpty(t, receiver)
+	expectMessages(t, sender, 0)
+}
+
+func testEnvelope(t *testing.T, connect Connector) {
+	conn := connect(messagebustypes.DirectionSendReceive)
+
+	sent := &messagebustypes.Envelope{
+		Headers:       map[string]string{"key": "value"},
+		ContentType:   messagebustypes.ProtobufContentType,
+		MessageType:   "contracts.EpisodeInfo",
+		SchemaVersion: 1,
+		MessageID:     "id",
+		Timestamp:     time.Date(2021, 2, 3, 4, 5, 6, 7, time.UTC),
+	}
+	if err := conn.SendEnvelope(sent, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	send(t, conn, "b")
+
+	msg := receive(t, conn, "a")
+	if len(msg.Headers) != 1 || msg.Headers["key"] != "value" ||
+		msg.ContentType != sent.ContentType ||
+		msg.MessageType != sent.MessageType ||
+		msg.SchemaVersion != sent.SchemaVersion ||
+		msg.MessageID != sent.MessageID ||
+		!msg.Timestamp.Equal(sent.Timestamp) {
+		t.Fatalf("unexpected envelope %+v", msg.Envelope)
+	}
+
+	msg = receive(t, conn, "b")
+	if msg.ContentType != messagebustypes.OctetStreamContentType ||
+		msg.MessageID == "" ||
+		msg.Timestamp.IsZero() {
+		t.Fatalf("expected a default envelope, got %+v", msg.Envelope)
+	}
+}
+
+func testNack(t *testing.T, connect Connector) {
+	conn := connect(messagebustypes.DirectionSendReceive)
+	send(t, conn, "a")
+
+	msg := receive(t, conn, "a")
+	if err := msg.Acknowledger.Nack(true); err != nil {
+		t.Fatal(err)
+	}
+
+	msg = receive(t, conn, "a")
+	if err := msg.Acknowledger.Nack(false); err != nil {
+		t.Fatal(err)
+	}
+	expectEmpty(t, conn)
+}
+
+func testCloseRequeuesUnacknowledgedMessages(t *testing.T, connect Connector) {
+	first := connect(messagebustypes.DirectionSendReceive)
+	send(t, first, "a")
+
+	receive(t, first, "a")
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("expected closing twice to have no effect, got %v", err)
+	}
+	if _, err := first.Receive(); !errors.Is(err, messagebustypes.ErrClosed) {
+		t.Fatalf("expected %v from a closed connection, got %v", messagebustypes.ErrClosed, err)
+	}
+
+	second := connect(messagebustypes.DirectionReceiveOnly)
+	receive(t, second, "a")
+}
+
+func testReceiveContext(t *testing.T, connect Connector) {
+	receiver := connect(messagebustypes.DirectionReceiveOnly)
+	sender := connect(messagebustypes.DirectionSendOnly)
+
+	received := make(chan *messagebustypes.Message)
+	go func() {
+		msg, err := receiver.ReceiveContext(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		received <- msg
+	}()
+
+	select {
+	case <-received:
+		t.Fatal("expected ReceiveContext to block on an empty queue")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	send(t, sender, "a")
+	select {
+	case msg := <-received:
+		if msg == nil || string(msg.Body) != "a" {
+			t.Fatalf("unexpected message %v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for ReceiveContext")
+	}
+}
+
+func testReceiveContextStopsWhenContextIsDone(t *testing.T, connect Connector) {
+	conn := connect(messagebustypes.DirectionReceiveOnly)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	msg, err := conn.ReceiveContext(ctx)
+	if msg != nil || err != context.DeadlineExceeded {
+		t.Fatalf("expected nil, %v, got %v, %v", context.DeadlineExceeded, msg, err)
+	}
+}
+
+func testReceiveContextStopsWhenClosed(t *testing.T, connect Connector) {
+	conn := connect(messagebustypes.DirectionReceiveOnly)
+
+	errs := make(chan error)
+	go func() {
+		_, err := conn.ReceiveContext(context.Background())
+		errs <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, messagebustypes.ErrClosed) {
+			t.Fatalf("expected %v, got %v", messagebustypes.ErrClosed, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for ReceiveContext")
+	}
+}
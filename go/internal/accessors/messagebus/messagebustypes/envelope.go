@@ -0,0 +1,177 @@
+package messagebustypes
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// Content types used for message bodies.
+const (
+	// ProtobufContentType denotes a protobuf encoded body.
+	ProtobufContentType = "application/x-protobuf"
+
+	// OctetStreamContentType denotes an opaque body. This is the default when
+	// a sender doesn't supply a content type.
+	OctetStreamContentType = "application/octet-stream"
+)
+
+// ContractsSchemaVersion is the schema version of the messages defined in the
+// contracts package. It must be incremented whenever a contract changes in a
+// way that existing consumers can't read.
+//
+// Additive changes don't require an increment. Consumers ignore fields that
+// they don't know about, and reject messages of types that they don't know
+// about (such as ResearchHeartbeat and ResearchFailure, which were added in
+// version 1) by MessageType. Incrementing the version for an additive change
+// would needlessly cause existing consumers to reject every message, including
+// those whose contracts haven't changed.
+const ContractsSchemaVersion = 1
+
+// An Envelope describes a message body. Senders may supply any of the fields,
+// and message buses supply a MessageID, Timestamp, and ContentType if the
+// sender doesn't.
+type Envelope struct {
+	// Headers are arbitrary sender-supplied values.
+	Headers map[string]string
+
+	ContentType string
+
+	// MessageType is the fully qualified name of the body's type, such as
+	// contracts.EpisodeInfo.
+	MessageType string
+
+	// SchemaVersion is the version of the schema that defines MessageType. A
+	// value of zero indicates that the version is unknown.
+	SchemaVersion int
+
+	MessageID string
+	Timestamp time.Time
+}
+
+// NewProtobufEnvelope returns an envelope describing the supplied protobuf
+// message, which is assumed to be defined in the contracts package.
+func NewProtobufEnvelope(message proto.Message) *Envelope {
+	return &Envelope{
+		ContentType:   ProtobufContentType,
+		MessageType:   string(message.ProtoReflect().Descriptor().FullName()),
+		SchemaVersion: ContractsSchemaVersion,
+	}
+}
+
+// WithDefaults returns a copy of the envelope with a MessageID, Timestamp,
+// and ContentType supplied if they're missing. A nil envelope is treated as
+// an empty envelope.
+func (e *Envelope) WithDefaults() *Envelope {
+	envelope := &Envelope{}
+	if e != nil {
+		*envelope = *e
+		envelope.Headers = make(map[string]string, len(e.Headers))
+		for key, value := range e.Headers {
+			envelope.Headers[key] = value
+		}
+	}
+
+	if envelope.MessageID == "" {
+		envelope.MessageID = uuid.New().String()
+	}
+	if envelope.Timestamp.IsZero() {
+		envelope.Timestamp = time.Now().UTC()
+	}
+	if envelope.ContentType == "" {
+		envelope.ContentType = OctetStreamContentType
+	}
+	return envelope
+}
+
+// The names of an envelope's fields, other than its headers, as they're
+// encoded by EncodeFields.
+const (
+	FieldMessageID     = "message-id"
+	FieldContentType   = "content-type"
+	FieldMessageType   = "message-type"
+	FieldSchemaVersion = "schema-version"
+	FieldTimestamp     = "timestamp"
+)
+
+// EncodeFields returns the envelope's fields, other than its headers, as
+// strings keyed by their Field names. This is for message buses that carry
+// an envelope as string values alongside the message. MessageType and
+// SchemaVersion are omitted if they're unset.
+func (e *Envelope) EncodeFields() map[string]string {
+	fields := map[string]string{
+		FieldMessageID:   e.MessageID,
+		FieldContentType: e.ContentType,
+		FieldTimestamp:   e.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	if e.MessageType != "" {
+		fields[FieldMessageType] = e.MessageType
+	}
+	if e.SchemaVersion != 0 {
+		fields[FieldSchemaVersion] = strconv.Itoa(e.SchemaVersion)
+	}
+	return fields
+}
+
+// DecodeFields returns an envelope with the supplied headers, and with the
+// fields encoded by EncodeFields, each of which is looked up by name via
+// field. Fields that are missing or can't be parsed are left unset.
+func DecodeFields(headers map[string]string, field func(name string) string) Envelope {
+	schemaVersion, _ := strconv.Atoi(field(FieldSchemaVersion))
+	timestamp, _ := time.Parse(time.RFC3339Nano, field(FieldTimestamp))
+
+	return Envelope{
+		Headers:       headers,
+		ContentType:   field(FieldContentType),
+		MessageType:   field(FieldMessageType),
+		SchemaVersion: schemaVersion,
+		MessageID:     field(FieldMessageID),
+		Timestamp:     timestamp,
+	}
+}
+
+// UnsupportedMessageError is returned by CheckProtobuf when an envelope
+// describes a message that the consumer doesn't understand.
+type UnsupportedMessageError struct {
+	MessageID string
+	Reason    string
+}
+
+func (e *UnsupportedMessageError) Error() string {
+	return fmt.Sprintf("unsupported message %v: %v", e.MessageID, e.Reason)
+}
+
+// CheckProtobuf reports whether a body described by the envelope can be
+// unmarshalled into the supplied protobuf message. An envelope that doesn't
+// specify a content type, message type, or schema version (such as one
+// published without an envelope) is assumed to be compatible. Otherwise, an
+// *UnsupportedMessageError is returned if the envelope describes something
+// else.
+func (e *Envelope) CheckProtobuf(message proto.Message) error {
+	unsupported := func(format string, args ...interface{}) error {
+		return &UnsupportedMessageError{
+			MessageID: e.MessageID,
+			Reason:    fmt.Sprintf(format, args...),
+		}
+	}
+
+	switch e.ContentType {
+	case "", ProtobufContentType, OctetStreamContentType:
+	default:
+		return unsupported("content type %v is not %v", e.ContentType, ProtobufContentType)
+	}
+
+	expectedType := string(message.ProtoReflect().Descriptor().FullName())
+	if e.MessageType != "" && e.MessageType != expectedType {
+		return unsupported("message type %v is not %v", e.MessageType, expectedType)
+	}
+
+	if e.SchemaVersion > ContractsSchemaVersion {
+		return unsupported("schema version %v is newer than %v", e.SchemaVersion, ContractsSchemaVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package messagebustypes_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+func Test_NewProtobufEnvelope(t *testing.T) {
+	envelope := messagebustypes.NewProtobufEnvelope(new(contracts.EpisodeInfo))
+	if envelope.MessageType != "contracts.EpisodeInfo" {
+		t.Errorf("unexpected message type %v", envelope.MessageType)
+	}
+	if envelope.ContentType != messagebustypes.ProtobufContentType {
+		t.Errorf("unexpected content type %v", envelope.ContentType)
+	}
+	if envelope.SchemaVersion != messagebustypes.ContractsSchemaVersion {
+		t.Errorf("unexpected schema version %v", envelope.SchemaVersion)
+	}
+}
+
+func Test_WithDefaults(t *testing.T) {
+	var nilEnvelope *messagebustypes.Envelope
+	defaulted := nilEnvelope.WithDefaults()
+	if defaulted.MessageID == "" || defaulted.Timestamp.IsZero() {
+		t.Errorf("expected a message ID and timestamp, got %+v", defaulted)
+	}
+	if defaulted.ContentType != messagebustypes.OctetStreamContentType {
+		t.Errorf("unexpected content type %v", defaulted.ContentType)
+	}
+
+	timestamp := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	supplied := &messagebustypes.Envelope{
+		Headers:     map[string]string{"key": "value"},
+		ContentType: "text/plain",
+		MessageID:   "id",
+		Timestamp:   timestamp,
+	}
+	defaulted = supplied.WithDefaults()
+	if defaulted.MessageID != "id" || !defaulted.Timestamp.Equal(timestamp) || defaulted.ContentType != "text/plain" {
+		t.Errorf("expected supplied values to be preserved, got %+v", defaulted)
+	}
+	defaulted.Headers["key"] = "changed"
+	if supplied.Headers["key"] != "value" {
+		t.Error("expected headers to be copied")
+	}
+}
+
+func Test_CheckProtobuf(t *testing.T) {
+	testCases := []struct {
+		name      string
+		envelope  messagebustypes.Envelope
+		supported bool
+	}{
+		{
+			name:      "no envelope",
+			supported: true,
+		},
+		{
+			name:      "matching envelope",
+			envelope:  *messagebustypes.NewProtobufEnvelope(new(contracts.ClipInfo)),
+			supported: true,
+		},
+		{
+			name:      "older schema",
+			envelope:  messagebustypes.Envelope{MessageType: "contracts.ClipInfo", SchemaVersion: messagebustypes.ContractsSchemaVersion - 1},
+			supported: true,
+		},
+		{
+			name:     "other message type",
+			envelope: *messagebustypes.NewProtobufEnvelope(new(contracts.EpisodeInfo)),
+		},
+		{
+			name:     "other content type",
+			envelope: messagebustypes.Envelope{ContentType: "application/json"},
+		},
+		{
+			name:     "newer schema",
+			envelope: messagebustypes.Envelope{SchemaVersion: messagebustypes.ContractsSchemaVersion + 1},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.envelope.CheckProtobuf(new(contracts.ClipInfo))
+			if testCase.supported && err != nil {
+				t.Fatalf("expected message to be supported, got %v", err)
+			}
+			if !testCase.supported {
+				var unsupported *messagebustypes.UnsupportedMessageError
+				if !errors.As(err, &unsupported) {
+					t.Fatalf("expected an UnsupportedMessageError, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func Test_EncodeDecodeFields(t *testing.T) {
+	sent := &messagebustypes.Envelope{
+		Headers:       map[string]string{"key": "value"},
+		ContentType:   messagebustypes.ProtobufContentType,
+		MessageType:   "contracts.EpisodeInfo",
+		SchemaVersion: 1,
+		MessageID:     "id",
+		Timestamp:     time.Date(2021, 2, 3, 4, 5, 6, 7, time.UTC),
+	}
+	fields := sent.EncodeFields()
+	received := messagebustypes.DecodeFields(sent.Headers, func(name string) string { return fields[name] })
+	if received.Headers["key"] != "value" ||
+		received.ContentType != sent.ContentType ||
+		received.MessageType != sent.MessageType ||
+		received.SchemaVersion != sent.SchemaVersion ||
+		received.MessageID != sent.MessageID ||
+		!received.Timestamp.Equal(sent.Timestamp) {
+		t.Fatalf("unexpected envelope %+v", received)
+	}
+
+	fields = (&messagebustypes.Envelope{}).EncodeFields()
+	if _, ok := fields[messagebustypes.FieldMessageType]; ok {
+		t.Errorf("expected an unset message type to be omitted, got %v", fields)
+	}
+	if _, ok := fields[messagebustypes.FieldSchemaVersion]; ok {
+		t.Errorf("expected an unset schema version to be omitted, got %v", fields)
+	}
+}
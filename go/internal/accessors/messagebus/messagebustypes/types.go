@@ -1,12 +1,22 @@
 package messagebustypes
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/acknowledger"
 )
 
+// ErrClosed is returned by message bus adapters once they've been closed.
+// Receivers can check for it to distinguish a closed bus, which will never
+// deliver another message, from a failure that might be retried.
+var ErrClosed = errors.New("message bus is closed")
+
 // Message is a wrapper around a message body that also provides capability of
-// aknowledging receipt of the message.
+// aknowledging receipt of the message. The message's Envelope describes its
+// body.
 type Message struct {
+	Envelope
 	Acknowledger acknowledger.AckNack
 	Body         []byte
 }
@@ -16,3 +26,36 @@ type QueueInfo struct {
 	Messages  int
 	Consumers int
 }
+
+// Direction denotes if a message queue allows sending and/or receiving.
+type Direction int
+
+const (
+	// DirectionReceiveOnly callers will only receive messages from this queue.
+	DirectionReceiveOnly Direction = 1
+
+	// DirectionSendOnly callers will only send messages to this queue.
+	DirectionSendOnly Direction = 2
+
+	// DirectionSendReceive callers will both send messages to and receive
+	// messages from this queue.
+	DirectionSendReceive Direction = 3
+)
+
+// Validate returns an error if d isn't one of the defined directions.
+func (d Direction) Validate() error {
+	if d < DirectionReceiveOnly || d > DirectionSendReceive {
+		return fmt.Errorf("invalid direction %v", int(d))
+	}
+	return nil
+}
+
+// CanSend reports whether d permits sending.
+func (d Direction) CanSend() bool {
+	return d&DirectionSendOnly != 0
+}
+
+// CanReceive reports whether d permits receiving.
+func (d Direction) CanReceive() bool {
+	return d&DirectionReceiveOnly != 0
+}
@@ -0,0 +1,289 @@
+// Package archiveapi serves a read-only JSON HTTP API over a datastore, so
+// that other tools can browse the archive without direct database access.
+//
+// The API exposes the following resources. Every resource only supports GET.
+//
+//	/episodes                          curated episodes (newest first)
+//	/episodes/clips?title=&date_aired= clips found within an episode
+//	/clips                             curated clips (by title)
+//	/clips/episodes?title=             episodes within which a clip was found
+//	/statistics                        backlog and lease statistics
+//
+// Lists are paged via the `offset` and `limit` query parameters. Episodes are
+// identified by their title and the RFC 3339 timestamp at which they aired,
+// and clips are identified by their title.
+//
+// Episodes and clips can be searched via the `q` (search text) and `curator`
+// query parameters, and episodes can also be filtered via the `aired_from`
+// and `aired_to` parameters (RFC 3339 timestamps). Search results are ranked
+// by how well they match the search text.
+package archiveapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+type handler struct {
+	db datastore.DataStorer
+}
+
+// NewHandler returns an http.Handler that serves the archive API from db.
+func NewHandler(db datastore.DataStorer) http.Handler {
+	h := &handler{db: db}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/episodes", h.get(h.listEpisodes))
+	mux.HandleFunc("/episodes/clips", h.get(h.findEpisodeClips))
+	mux.HandleFunc("/clips", h.get(h.listClips))
+	mux.HandleFunc("/clips/episodes", h.get(h.findClipAppearances))
+	mux.HandleFunc("/statistics", h.get(h.getStatistics))
+	return mux
+}
+
+// A statusError is an error that should be reported to the client with a
+// specific status code.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &statusError{http.StatusBadRequest, fmt.Errorf(format, args...)}
+}
+
+// get adapts a function that returns a response body (or an error) to an
+// http.HandlerFunc that only accepts GET requests.
+func (h *handler) get(serve func(*http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+			return
+		}
+
+		body, err := serve(r)
+		if err != nil {
+			var se *statusError
+			switch {
+			case errors.As(err, &se):
+				writeJSON(w, se.status, errorResponse{Error: se.Error()})
+			case errors.Is(err, datastore.ErrNotFound):
+				writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			default:
+				log.Printf("error serving %v: %v", r.URL, err)
+				writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+			}
+			return
+		}
+
+		writeJSON(w, http.StatusOK, body)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("error writing response: %v", err)
+	}
+}
+
+func (h *handler) listEpisodes(r *http.Request) (interface{}, error) {
+	page, err := parsePage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var episodes []*contracts.EpisodeInfo
+	if isSearch(r, "aired_from", "aired_to") {
+		query, err := parseSearchQuery(r, page)
+		if err != nil {
+			return nil, err
+		}
+		episodes, err = h.db.SearchEpisodes(query)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		episodes, err = h.db.ListEpisodes(page)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]*episodeResponse, 0, len(episodes))
+	for _, episode := range episodes {
+		items = append(items, newEpisodeResponse(episode))
+	}
+	return &listResponse{Offset: page.Offset, Limit: page.Limit, Items: items}, nil
+}
+
+func (h *handler) listClips(r *http.Request) (interface{}, error) {
+	page, err := parsePage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.URL.Query().Get("aired_from") != "" || r.URL.Query().Get("aired_to") != "" {
+		return nil, badRequest("clips cannot be filtered by air date")
+	}
+
+	var clips []*contracts.ClipInfo
+	if isSearch(r) {
+		query, err := parseSearchQuery(r, page)
+		if err != nil {
+			return nil, err
+		}
+		clips, err = h.db.SearchClips(query)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		clips, err = h.db.ListClips(page)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]*clipResponse, 0, len(clips))
+	for _, clip := range clips {
+		items = append(items, newClipResponse(clip))
+	}
+	return &listResponse{Offset: page.Offset, Limit: page.Limit, Items: items}, nil
+}
+
+func (h *handler) findEpisodeClips(r *http.Request) (interface{}, error) {
+	page, err := parsePage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	title := r.URL.Query().Get("title")
+	if title == "" {
+		return nil, badRequest("title is required")
+	}
+
+	dateAired, err := time.Parse(time.RFC3339, r.URL.Query().Get("date_aired"))
+	if err != nil {
+		return nil, badRequest("date_aired must be an RFC 3339 timestamp: %v", err)
+	}
+
+	episode := &contracts.EpisodeInfo{
+		Title:     title,
+		DateAired: timestamppb.New(dateAired),
+	}
+	research, err := h.db.FindEpisodeClips(episode, page)
+	if err != nil {
+		return nil, err
+	}
+	return &listResponse{Offset: page.Offset, Limit: page.Limit, Items: newCompletedResearchResponses(research)}, nil
+}
+
+func (h *handler) findClipAppearances(r *http.Request) (interface{}, error) {
+	page, err := parsePage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	title := r.URL.Query().Get("title")
+	if title == "" {
+		return nil, badRequest("title is required")
+	}
+
+	research, err := h.db.FindClipAppearances(&contracts.ClipInfo{Title: title}, page)
+	if err != nil {
+		return nil, err
+	}
+	return &listResponse{Offset: page.Offset, Limit: page.Limit, Items: newCompletedResearchResponses(research)}, nil
+}
+
+func (h *handler) getStatistics(r *http.Request) (interface{}, error) {
+	statistics, err := h.db.GetResearchStatistics()
+	if err != nil {
+		return nil, err
+	}
+	return &statisticsResponse{
+		Episodes:           statistics.Episodes,
+		Clips:              statistics.Clips,
+		BacklogItems:       statistics.BacklogItems,
+		LeasedBacklogItems: statistics.LeasedBacklogItems,
+		CompletedItems:     statistics.CompletedItems,
+	}, nil
+}
+
+// isSearch returns true if the request includes the q or curator query
+// parameters, or any of the additional parameters.
+func isSearch(r *http.Request, additionalParameters ...string) bool {
+	for _, parameter := range append([]string{"q", "curator"}, additionalParameters...) {
+		if r.URL.Query().Get(parameter) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSearchQuery reads the search query parameters.
+func parseSearchQuery(r *http.Request, page datastore.Page) (*datastore.SearchQuery, error) {
+	query := &datastore.SearchQuery{
+		Text:    r.URL.Query().Get("q"),
+		Curator: r.URL.Query().Get("curator"),
+		Page:    page,
+	}
+
+	for parameter, value := range map[string]*time.Time{
+		"aired_from": &query.AiredFrom,
+		"aired_to":   &query.AiredTo,
+	} {
+		if raw := r.URL.Query().Get(parameter); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return nil, badRequest("%v must be an RFC 3339 timestamp: %v", parameter, err)
+			}
+			*value = parsed
+		}
+	}
+
+	return query, nil
+}
+
+// parsePage reads the offset and limit query parameters. The limit defaults
+// to defaultPageLimit, and may not exceed maxPageLimit.
+func parsePage(r *http.Request) (datastore.Page, error) {
+	page := datastore.Page{Limit: defaultPageLimit}
+
+	if offset := r.URL.Query().Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return page, badRequest("offset must be a non-negative integer")
+		}
+		page.Offset = value
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > maxPageLimit {
+			return page, badRequest("limit must be an integer between 1 and %v", maxPageLimit)
+		}
+		page.Limit = value
+	}
+
+	return page, nil
+}
@@ -0,0 +1,233 @@
+package archiveapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/memadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archiveapi"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var dateAired = time.Date(2021, time.April, 9, 0, 0, 0, 0, time.UTC)
+
+func newServer(t *testing.T) *httptest.Server {
+	db := memadapter.New()
+	curated := timestamppb.New(dateAired)
+	episode := &contracts.EpisodeInfo{
+		InitialDateCurated: curated,
+		LastDateCurated:    curated,
+		DateAired:          timestamppb.New(dateAired),
+		Title:              "episode",
+		MediaUri:           "https://example.com/episode.mp3",
+	}
+	clips := []*contracts.ClipInfo{
+		{InitialDateCurated: curated, LastDateCurated: curated, Title: "clip a", Description: "sandwiches", MediaUri: "https://example.com/a.mp3"},
+		{InitialDateCurated: curated, LastDateCurated: curated, Title: "clip b", Description: "bicycles", MediaUri: "https://example.com/b.mp3"},
+	}
+	if err := db.UpsertEpisodeInfo(episode); err != nil {
+		t.Fatal(err)
+	}
+	for _, clip := range clips {
+		if err := db.UpsertClipInfo(clip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := db.RecordCompletedResearch(&contracts.CompletedResearchItem{
+		ResearchDate: curated,
+		EpisodeInfo:  episode,
+		ClipInfo:     clips[0],
+		ClipOffsets:  []int64{int64(time.Minute), int64(2 * time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(archiveapi.NewHandler(db))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, server *httptest.Server, path string, query url.Values, body interface{}) int {
+	t.Helper()
+	response, err := http.Get(server.URL + path + "?" + query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Fatalf("expected a json response, got %q", contentType)
+	}
+	if err := json.NewDecoder(response.Body).Decode(body); err != nil {
+		t.Fatal(err)
+	}
+	return response.StatusCode
+}
+
+type itemsResponse struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Items  []struct {
+		Title   string `json:"title"`
+		Episode struct {
+			Title string `json:"title"`
+		} `json:"episode"`
+		Clip struct {
+			Title string `json:"title"`
+		} `json:"clip"`
+		ClipOffsetsNs []int64 `json:"clip_offsets_ns"`
+	} `json:"items"`
+}
+
+func Test_ListClips(t *testing.T) {
+	server := newServer(t)
+
+	var body itemsResponse
+	status := get(t, server, "/clips", url.Values{"offset": {"1"}, "limit": {"10"}}, &body)
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", status)
+	}
+	if body.Offset != 1 || body.Limit != 10 {
+		t.Errorf("expected offset 1 and limit 10, got %v and %v", body.Offset, body.Limit)
+	}
+	if len(body.Items) != 1 || body.Items[0].Title != "clip b" {
+		t.Errorf("expected only clip b, got %+v", body.Items)
+	}
+}
+
+func Test_SearchClips(t *testing.T) {
+	server := newServer(t)
+
+	var body itemsResponse
+	status := get(t, server, "/clips", url.Values{"q": {"Bicycle"}}, &body)
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", status)
+	}
+	if len(body.Items) != 1 || body.Items[0].Title != "clip b" {
+		t.Errorf("expected only clip b, got %+v", body.Items)
+	}
+
+}
+
+func Test_SearchEpisodes(t *testing.T) {
+	server := newServer(t)
+
+	testCases := []struct {
+		query         url.Values
+		expectedCount int
+	}{
+		{url.Values{"q": {"episode"}}, 1},
+		{url.Values{"q": {"nothing"}}, 0},
+		{url.Values{"aired_from": {dateAired.Add(time.Hour).Format(time.RFC3339)}}, 0},
+		{url.Values{"aired_to": {dateAired.Format(time.RFC3339)}}, 1},
+	}
+	for _, testCase := range testCases {
+		var body itemsResponse
+		status := get(t, server, "/episodes", testCase.query, &body)
+		if status != http.StatusOK {
+			t.Fatalf("%v: expected status 200, got %v", testCase.query, status)
+		}
+		if len(body.Items) != testCase.expectedCount {
+			t.Errorf("%v: expected %v episodes, got %+v", testCase.query, testCase.expectedCount, body.Items)
+		}
+	}
+}
+
+func Test_FindEpisodeClips(t *testing.T) {
+	server := newServer(t)
+
+	var body itemsResponse
+	query := url.Values{"title": {"episode"}, "date_aired": {dateAired.Format(time.RFC3339)}}
+	status := get(t, server, "/episodes/clips", query, &body)
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", status)
+	}
+	if len(body.Items) != 1 || body.Items[0].Clip.Title != "clip a" {
+		t.Fatalf("expected only clip a, got %+v", body.Items)
+	}
+	if offsets := body.Items[0].ClipOffsetsNs; len(offsets) != 2 || offsets[0] != int64(time.Minute) {
+		t.Errorf("expected two offsets starting at one minute, got %v", offsets)
+	}
+}
+
+func Test_FindClipAppearances(t *testing.T) {
+	server := newServer(t)
+
+	var body itemsResponse
+	status := get(t, server, "/clips/episodes", url.Values{"title": {"clip a"}}, &body)
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", status)
+	}
+	if len(body.Items) != 1 || body.Items[0].Episode.Title != "episode" {
+		t.Errorf("expected only the episode, got %+v", body.Items)
+	}
+}
+
+func Test_GetStatistics(t *testing.T) {
+	server := newServer(t)
+
+	var body map[string]int
+	status := get(t, server, "/statistics", nil, &body)
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", status)
+	}
+	expected := map[string]int{
+		"episodes":             1,
+		"clips":                2,
+		"backlog_items":        1,
+		"leased_backlog_items": 0,
+		"completed_items":      1,
+	}
+	for key, value := range expected {
+		if body[key] != value {
+			t.Errorf("expected %v to be %v, got %v", key, value, body[key])
+		}
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	server := newServer(t)
+
+	testCases := []struct {
+		name           string
+		path           string
+		query          url.Values
+		expectedStatus int
+	}{
+		{"invalid limit", "/episodes", url.Values{"limit": {"0"}}, http.StatusBadRequest},
+		{"invalid offset", "/clips", url.Values{"offset": {"-1"}}, http.StatusBadRequest},
+		{"missing title", "/clips/episodes", nil, http.StatusBadRequest},
+		{"clip air dates", "/clips", url.Values{"aired_from": {dateAired.Format(time.RFC3339)}}, http.StatusBadRequest},
+		{"invalid date aired", "/episodes/clips", url.Values{"title": {"episode"}, "date_aired": {"yesterday"}}, http.StatusBadRequest},
+		{"unknown clip", "/clips/episodes", url.Values{"title": {"clip z"}}, http.StatusNotFound},
+		{"unknown resource", "/nothing", nil, http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			response, err := http.Get(server.URL + testCase.path + "?" + testCase.query.Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+			if response.StatusCode != testCase.expectedStatus {
+				t.Errorf("expected status %v, got %v", testCase.expectedStatus, response.StatusCode)
+			}
+		})
+	}
+
+	response, err := http.Post(server.URL+"/statistics", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %v", response.StatusCode)
+	}
+}
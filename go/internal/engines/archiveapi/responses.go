@@ -0,0 +1,103 @@
+package archiveapi
+
+import (
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type listResponse struct {
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+	Items  interface{} `json:"items"`
+}
+
+type episodeResponse struct {
+	Title              string    `json:"title"`
+	Description        string    `json:"description"`
+	DateAired          time.Time `json:"date_aired"`
+	CuratorInformation string    `json:"curator_information"`
+	MediaURI           string    `json:"media_uri"`
+	MediaType          string    `json:"media_type"`
+	Priority           int32     `json:"priority"`
+	InitialDateCurated time.Time `json:"initial_date_curated"`
+	LastDateCurated    time.Time `json:"last_date_curated"`
+}
+
+func newEpisodeResponse(episode *contracts.EpisodeInfo) *episodeResponse {
+	return &episodeResponse{
+		Title:              episode.Title,
+		Description:        episode.Description,
+		DateAired:          episode.DateAired.AsTime(),
+		CuratorInformation: episode.CuratorInformation,
+		MediaURI:           episode.MediaUri,
+		MediaType:          episode.MediaType,
+		Priority:           episode.Priority,
+		InitialDateCurated: episode.InitialDateCurated.AsTime(),
+		LastDateCurated:    episode.LastDateCurated.AsTime(),
+	}
+}
+
+type clipResponse struct {
+	Title              string    `json:"title"`
+	Description        string    `json:"description"`
+	CuratorInformation string    `json:"curator_information"`
+	MediaURI           string    `json:"media_uri"`
+	MediaType          string    `json:"media_type"`
+	Priority           int32     `json:"priority"`
+	InitialDateCurated time.Time `json:"initial_date_curated"`
+	LastDateCurated    time.Time `json:"last_date_curated"`
+}
+
+func newClipResponse(clip *contracts.ClipInfo) *clipResponse {
+	return &clipResponse{
+		Title:              clip.Title,
+		Description:        clip.Description,
+		CuratorInformation: clip.CuratorInformation,
+		MediaURI:           clip.MediaUri,
+		MediaType:          clip.MediaType,
+		Priority:           clip.Priority,
+		InitialDateCurated: clip.InitialDateCurated.AsTime(),
+		LastDateCurated:    clip.LastDateCurated.AsTime(),
+	}
+}
+
+type completedResearchResponse struct {
+	Episode           *episodeResponse `json:"episode"`
+	Clip              *clipResponse    `json:"clip"`
+	EpisodeDurationNs int64            `json:"episode_duration_ns"`
+	EpisodeHash       string           `json:"episode_hash"`
+	ClipDurationNs    int64            `json:"clip_duration_ns"`
+	ClipHash          string           `json:"clip_hash"`
+	ClipOffsetsNs     []int64          `json:"clip_offsets_ns"`
+	ResearchDate      time.Time        `json:"research_date"`
+}
+
+func newCompletedResearchResponses(research []*contracts.CompletedResearchItem) []*completedResearchResponse {
+	responses := make([]*completedResearchResponse, 0, len(research))
+	for _, item := range research {
+		responses = append(responses, &completedResearchResponse{
+			Episode:           newEpisodeResponse(item.EpisodeInfo),
+			Clip:              newClipResponse(item.ClipInfo),
+			EpisodeDurationNs: item.EpisodeDuration,
+			EpisodeHash:       item.EpisodeHash,
+			ClipDurationNs:    item.ClipDuration,
+			ClipHash:          item.ClipHash,
+			ClipOffsetsNs:     item.ClipOffsets,
+			ResearchDate:      item.ResearchDate.AsTime(),
+		})
+	}
+	return responses
+}
+
+type statisticsResponse struct {
+	Episodes           int `json:"episodes"`
+	Clips              int `json:"clips"`
+	BacklogItems       int `json:"backlog_items"`
+	LeasedBacklogItems int `json:"leased_backlog_items"`
+	CompletedItems     int `json:"completed_items"`
+}
@@ -2,10 +2,12 @@ package archivists
 
 import (
 	"context"
-	"runtime"
+	"errors"
+	"time"
 
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/utils"
 	"google.golang.org/protobuf/proto"
@@ -18,7 +20,7 @@ type ClipsArchivist struct {
 	Done   <-chan struct{}
 }
 
-// StartClipsArchivist initializes a clips archivist. The archvist will begin polling the
+// StartClipsArchivist initializes a clips archivist. The archvist will begin consuming the
 // supplied queue for new clips, and will place those clips in the supplied
 // datastore. The clips archivist operates indefinitely, or until its parent
 // context signals that it is done. Once the archivist is initialized, the
@@ -31,22 +33,20 @@ func StartClipsArchivist(ctx context.Context, queue messagebus.Receiver, db data
 		defer close(errorSource)
 		defer close(done)
 		for {
-			if utils.ContextIsDone(ctx) {
-				return
-			}
-
-			// If we're in a position where we're getting a lot of errors or
-			// nil messages from the queue, we can end up hogging resources
-			// from other goroutines. So, we yield to get out of their way.
-			// Though the runtime technically can yield on any function call,
-			// it will only do so on non-inlined calls. Since we don't know for
-			// sure if the next call is inlined, we explicitly yield to be
-			// safe.
-			runtime.Gosched()
-
-			msg, err := queue.Receive()
+			msg, err := queue.ReceiveContext(ctx)
 			if err != nil {
+				if utils.ContextIsDone(ctx) {
+					return
+				}
 				errorSource <- err
+				if errors.Is(err, messagebustypes.ErrClosed) {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(receiveRetryInterval):
+				}
 				continue
 			}
 
@@ -55,10 +55,16 @@ func StartClipsArchivist(ctx context.Context, queue messagebus.Receiver, db data
 			}
 
 			clipInfo := new(contracts.ClipInfo)
-			err = proto.Unmarshal(msg.Body, clipInfo)
+			err = msg.CheckProtobuf(clipInfo)
+			if err == nil {
+				err = proto.Unmarshal(msg.Body, clipInfo)
+			}
 			if err != nil {
 				errorSource <- err
-				err := msg.Acknowledger.Nack(true)
+				// A message that isn't understood or can't be unmarshalled
+				// will never succeed, so it's rejected (and dead-lettered)
+				// rather than requeued.
+				err := msg.Acknowledger.Nack(false)
 				if err != nil {
 					errorSource <- err
 				}
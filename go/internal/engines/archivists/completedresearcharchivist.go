@@ -15,6 +15,15 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+// researchHeartbeatType is the message type of a ResearchHeartbeat, which
+// researchers send on the completed research queue while research is
+// underway.
+var researchHeartbeatType = string(new(contracts.ResearchHeartbeat).ProtoReflect().Descriptor().FullName())
+
+// researchFailureType is the message type of a ResearchFailure, which
+// researchers send on the completed research queue when research fails.
+var researchFailureType = string(new(contracts.ResearchFailure).ProtoReflect().Descriptor().FullName())
+
 // A CompletedResearchArchivist determines if any upstream researchers have
 // reported any completed work, and, if so, records thwat work in the datastore
 // and renews the lease on the associated episode.
@@ -25,11 +34,16 @@ type CompletedResearchArchivist struct {
 
 // StartCompletedResearchArchivist starts the archivist, which begins polling
 // for completed work. When completed work is found, it is recorded in the
-// datastore and the associated episode's lease is renewed. This archivist will
-// continue to poll for completed work until it determines that no work is
-// available, at which point the archivist will exit and no further work will
-// be done. Thus, it is the responsibility of the host system to periodically
-// start an archivist via a cron job or some other desired scheduler.
+// datastore and the associated episode's lease is renewed. The lease is also
+// renewed whenever a researcher reports, via a ResearchHeartbeat, that
+// research is still underway. When a researcher reports, via a
+// ResearchFailure, that research has failed, the failure is recorded in the
+// datastore, and the failed items are released from their lease. This
+// archivist will continue to poll for completed work until it determines that
+// no work is available, at which point the archivist will exit and no further
+// work will be done. Thus, it is the responsibility of the host system to
+// periodically start an archivist via a cron job or some other desired
+// scheduler.
 func StartCompletedResearchArchivist(ctx context.Context, messageBus messagebus.SenderReceiver, db datastore.DataStorer) *CompletedResearchArchivist {
 	errorSource := make(chan error)
 	done := make(chan struct{})
@@ -69,15 +83,96 @@ func StartCompletedResearchArchivist(ctx context.Context, messageBus messagebus.
 				continue
 			}
 
-			if len(rawMessage.Body) == 0 {
+			if rawMessage == nil || len(rawMessage.Body) == 0 {
+				continue
+			}
+
+			if rawMessage.MessageType == researchHeartbeatType {
+				researchHeartbeat := new(contracts.ResearchHeartbeat)
+				err = rawMessage.CheckProtobuf(researchHeartbeat)
+				if err == nil {
+					err = proto.Unmarshal(rawMessage.Body, researchHeartbeat)
+				}
+				var leaseID uuid.UUID
+				if err == nil {
+					leaseID, err = uuid.Parse(researchHeartbeat.LeaseId)
+				}
+				if err != nil {
+					errorSource <- fmt.Errorf("an error occured while unmarshalling a research heartbeat. %v %v", rawMessage.Body, err)
+					err = rawMessage.Acknowledger.Nack(false)
+					if err != nil {
+						errorSource <- fmt.Errorf("an error occured while trying to send a negative achnowledgement to the message bus %v", err)
+					}
+					continue
+				}
+
+				// A heartbeat that can't be recorded (for instance, because
+				// its lease has since expired or been revoked) is still
+				// acknowledged, and so dropped rather than requeued or
+				// dead-lettered, since the researcher will send another before
+				// the lease expires.
+				err = db.RenewResearchLease(leaseID, time.Now().Add(episodeLeaseDuration).UTC())
+				if err != nil {
+					errorSource <- fmt.Errorf("an error occured trying to renew a lease from a heartbeat. %v %v", rawMessage.Body, err)
+				}
+
+				err = rawMessage.Acknowledger.Ack()
+				if err != nil {
+					errorSource <- fmt.Errorf("an error occured while trying to acknowledge receipt of a message %v", err)
+				}
+				continue
+			}
+
+			if rawMessage.MessageType == researchFailureType {
+				researchFailure := new(contracts.ResearchFailure)
+				err = rawMessage.CheckProtobuf(researchFailure)
+				if err == nil {
+					err = proto.Unmarshal(rawMessage.Body, researchFailure)
+				}
+				if err == nil {
+					_, err = uuid.Parse(researchFailure.LeaseId)
+				}
+				if err != nil {
+					errorSource <- fmt.Errorf("an error occured while unmarshalling a research failure. %v %v", rawMessage.Body, err)
+					err = rawMessage.Acknowledger.Nack(false)
+					if err != nil {
+						errorSource <- fmt.Errorf("an error occured while trying to send a negative achnowledgement to the message bus %v", err)
+					}
+					continue
+				}
+
+				err = db.RecordResearchFailure(researchFailure)
+				if err != nil {
+					errorSource <- fmt.Errorf("an error occured recording a research failure to the datastore. %v %v", rawMessage.Body, err)
+					err = rawMessage.Acknowledger.Nack(true)
+					if err != nil {
+						errorSource <- fmt.Errorf("an error occured while trying to send a negative achnowledgement to the message bus %v", err)
+					}
+					continue
+				}
+
+				err = rawMessage.Acknowledger.Ack()
+				if err != nil {
+					errorSource <- fmt.Errorf("an error occured while trying to acknowledge receipt of a message %v", err)
+				}
 				continue
 			}
 
-			var completedResearchItem *contracts.CompletedResearchItem
-			err = proto.Unmarshal(rawMessage.Body, completedResearchItem)
+			completedResearchItem := new(contracts.CompletedResearchItem)
+			err = rawMessage.CheckProtobuf(completedResearchItem)
+			if err == nil {
+				err = proto.Unmarshal(rawMessage.Body, completedResearchItem)
+			}
+			var leaseID uuid.UUID
+			if err == nil {
+				leaseID, err = uuid.Parse(completedResearchItem.LeaseId)
+			}
 			if err != nil {
 				errorSource <- fmt.Errorf("an error occured while unmarshalling a completed research item. %v %v", rawMessage.Body, err)
-				err = rawMessage.Acknowledger.Nack(true)
+				// A message that isn't understood or can't be unmarshalled
+				// will never succeed, so it's rejected (and dead-lettered)
+				// rather than requeued.
+				err = rawMessage.Acknowledger.Nack(false)
 				if err != nil {
 					errorSource <- fmt.Errorf("an error occured while trying to send a negative achnowledgement to the message bus %v", err)
 				}
@@ -86,10 +181,10 @@ func StartCompletedResearchArchivist(ctx context.Context, messageBus messagebus.
 
 			var operationType string
 			if completedResearchItem.RevokeLease {
-				err = db.RevokeResearchLease(uuid.MustParse(completedResearchItem.LeaseId))
+				err = db.RevokeResearchLease(leaseID)
 				operationType = "revoke"
 			} else {
-				err = db.RenewResearchLease(uuid.MustParse(completedResearchItem.LeaseId), time.Now().Add(episodeLeaseDuration).UTC())
+				err = db.RenewResearchLease(leaseID, time.Now().Add(episodeLeaseDuration).UTC())
 				operationType = "renew"
 			}
 
@@ -0,0 +1,210 @@
+package archivists_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/memadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/fileadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archivists"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/mocks/accessors/mock_messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/mocks/accessors/mock_messagebus/mock_acknowledger"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newLeasedResearch returns a datastore containing an episode and clip that
+// are leased until leaseExpiration.
+func newLeasedResearch(t *testing.T, leaseExpiration time.Time) (datastore.DataStorer, *contracts.EpisodeInfo, *contracts.ClipInfo, uuid.UUID) {
+	db := memadapter.New()
+	now := timestamppb.Now()
+	episode := &contracts.EpisodeInfo{
+		InitialDateCurated: now,
+		LastDateCurated:    now,
+		DateAired:          now,
+		Title:              "episode",
+		MediaUri:           "https://example.com/episode.mp3",
+	}
+	clip := &contracts.ClipInfo{
+		InitialDateCurated: now,
+		LastDateCurated:    now,
+		Title:              "clip",
+		MediaUri:           "https://example.com/clip.mp3",
+	}
+	if err := db.UpsertEpisodeInfo(episode); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpsertClipInfo(clip); err != nil {
+		t.Fatal(err)
+	}
+	leaseID := uuid.New()
+	err := db.CreateResearchLease(&leaseID, episode, []*contracts.ClipInfo{clip}, leaseExpiration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, episode, clip, leaseID
+}
+
+// newCompletedResearchQueue returns a queue containing each of the supplied
+// messages.
+func newCompletedResearchQueue(ctx context.Context, t *testing.T, messages ...proto.Message) messagebus.SenderReceiver {
+	bus, err := fileadapter.Open(&fileadapter.Config{Dir: t.TempDir(), DisableSync: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue, err := bus.Initialize(ctx, "completed_research", messagebustypes.DirectionSendReceive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, message := range messages {
+		body, err := proto.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = queue.SendEnvelope(messagebustypes.NewProtobufEnvelope(message), body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return queue
+}
+
+// runCompletedResearchArchivist runs the archivist until it's done, and
+// returns the number of errors that it reported.
+func runCompletedResearchArchivist(ctx context.Context, t *testing.T, queue messagebus.SenderReceiver, db datastore.DataStorer) int {
+	archivist := archivists.StartCompletedResearchArchivist(ctx, queue, db)
+	errs := 0
+	for {
+		select {
+		case err := <-archivist.Errors:
+			if err != nil {
+				errs++
+			}
+		case <-archivist.Done:
+			return errs
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the archivist")
+		}
+	}
+}
+
+func Test_CompletedResearchArchivistRenewsLeaseFromHeartbeat(t *testing.T) {
+	db, _, _, leaseID := newLeasedResearch(t, time.Now().Add(-time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	now := timestamppb.Now()
+	queue := newCompletedResearchQueue(ctx, t,
+		&contracts.ResearchHeartbeat{LeaseId: "not a lease id", HeartbeatDate: now},
+		&contracts.ResearchHeartbeat{LeaseId: leaseID.String(), HeartbeatDate: now},
+	)
+
+	if errs := runCompletedResearchArchivist(ctx, t, queue, db); errs != 1 {
+		t.Errorf("expected 1 error for the invalid lease id, got %v", errs)
+	}
+
+	reclaimed, err := db.ReapExpiredLeases(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reclaimed != 0 {
+		t.Fatalf("expected the lease to have been renewed, but %v items were reclaimed", reclaimed)
+	}
+}
+
+// leaseRenewalFailingDb fails to renew any lease.
+type leaseRenewalFailingDb struct {
+	datastore.DataStorer
+}
+
+func (leaseRenewalFailingDb) RenewResearchLease(uuid.UUID, time.Time) error {
+	return errors.New("lease not found")
+}
+
+func Test_CompletedResearchArchivistAcknowledgesUnrecordedHeartbeat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	researchHeartbeat := &contracts.ResearchHeartbeat{LeaseId: uuid.New().String(), HeartbeatDate: timestamppb.Now()}
+	body, err := proto.Marshal(researchHeartbeat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acknack := mock_acknowledger.NewMockAckNack(ctrl)
+	acknack.EXPECT().Ack().Times(1)
+	queue := mock_messagebus.NewMockSenderReceiver(ctrl)
+	gomock.InOrder(
+		queue.EXPECT().Inspect().Return(&messagebustypes.QueueInfo{Messages: 1}, nil),
+		queue.EXPECT().Receive().Return(&messagebustypes.Message{
+			Envelope:     *messagebustypes.NewProtobufEnvelope(researchHeartbeat),
+			Acknowledger: acknack,
+			Body:         body,
+		}, nil),
+		queue.EXPECT().Inspect().Return(&messagebustypes.QueueInfo{Messages: 0}, nil),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if errs := runCompletedResearchArchivist(ctx, t, queue, leaseRenewalFailingDb{memadapter.New()}); errs != 1 {
+		t.Errorf("expected 1 error for the unrecorded heartbeat, got %v", errs)
+	}
+}
+
+func Test_CompletedResearchArchivistRecordsResearchFailure(t *testing.T) {
+	db, episode, clip, leaseID := newLeasedResearch(t, time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	newResearchFailure := func(leaseID string) *contracts.ResearchFailure {
+		return &contracts.ResearchFailure{
+			FailureDate:  timestamppb.Now(),
+			LeaseId:      leaseID,
+			EpisodeInfo:  episode,
+			ClipInfos:    []*contracts.ClipInfo{clip},
+			FailureClass: contracts.FailureClass_PERMANENT,
+			Message:      "unable to decode clip",
+		}
+	}
+	queue := newCompletedResearchQueue(ctx, t,
+		newResearchFailure("not a lease id"),
+		newResearchFailure(leaseID.String()),
+	)
+
+	if errs := runCompletedResearchArchivist(ctx, t, queue, db); errs != 1 {
+		t.Errorf("expected 1 error for the invalid lease id, got %v", errs)
+	}
+
+	clips, err := db.GetHighestPriorityClipsForEpisode(episode, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clips) != 1 || clips[0].GetTitle() != clip.GetTitle() {
+		t.Fatalf("expected the failed clip to have been released from its lease, got %v", clips)
+	}
+}
+
+func Test_CompletedResearchArchivistRejectsInvalidLeaseID(t *testing.T) {
+	db, episode, clip, _ := newLeasedResearch(t, time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queue := newCompletedResearchQueue(ctx, t, &contracts.CompletedResearchItem{
+		ResearchDate: timestamppb.Now(),
+		LeaseId:      "not a lease id",
+		EpisodeInfo:  episode,
+		ClipInfo:     clip,
+	})
+
+	if errs := runCompletedResearchArchivist(ctx, t, queue, db); errs != 1 {
+		t.Errorf("expected 1 error for the invalid lease id, got %v", errs)
+	}
+}
@@ -0,0 +1,166 @@
+package archivists_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/memadapter"
+	membus "github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/adapters/memadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archivists"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_EpisodesArchivistWaitsForEpisodes(t *testing.T) {
+	db := memadapter.New()
+	broker := membus.NewBroker()
+	queue, err := broker.Initialize(context.Background(), "curated_episodes", messagebustypes.DirectionSendReceive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	archivist := archivists.StartEpisodesArchivist(ctx, queue, db)
+
+	// Give the archivist time to begin waiting on the empty queue before an
+	// episode is sent.
+	time.Sleep(10 * time.Millisecond)
+
+	now := timestamppb.Now()
+	body, err := proto.Marshal(&contracts.EpisodeInfo{
+		InitialDateCurated: now,
+		LastDateCurated:    now,
+		DateAired:          now,
+		Title:              "episode",
+		MediaUri:           "https://example.com/episode.mp3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Send(body); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		episodes, err := db.ListEpisodes(datastore.Page{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(episodes) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the episode to be archived")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	info, err := queue.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Messages != 0 {
+		t.Fatalf("expected the episode to be acknowledged, got %v", info)
+	}
+
+	cancel()
+	for {
+		select {
+		case err, open := <-archivist.Errors:
+			if open {
+				t.Fatal(err)
+			}
+		case <-archivist.Done:
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the archivist to stop")
+		}
+	}
+}
+
+func Test_EpisodesArchivistRejectsUnsupportedMessages(t *testing.T) {
+	db := memadapter.New()
+	broker := membus.NewBroker()
+	queue, err := broker.Initialize(context.Background(), "curated_episodes", messagebustypes.DirectionSendReceive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := proto.Marshal(&contracts.ClipInfo{Title: "clip"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := messagebustypes.NewProtobufEnvelope(new(contracts.ClipInfo))
+	if err := queue.SendEnvelope(envelope, body); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	archivist := archivists.StartEpisodesArchivist(ctx, queue, db)
+
+	select {
+	case err := <-archivist.Errors:
+		var unsupported *messagebustypes.UnsupportedMessageError
+		if !errors.As(err, &unsupported) {
+			t.Fatalf("expected an UnsupportedMessageError, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the archivist to reject the message")
+	}
+
+	cancel()
+	<-archivist.Done
+
+	info, err := queue.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Messages != 0 {
+		t.Fatalf("expected the message to be rejected rather than requeued, got %v", info)
+	}
+	episodes, err := db.ListEpisodes(datastore.Page{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 0 {
+		t.Fatalf("expected no episodes to be archived, got %v", episodes)
+	}
+}
+
+func Test_EpisodesArchivistStopsWhenQueueIsClosed(t *testing.T) {
+	db := memadapter.New()
+	broker := membus.NewBroker()
+	queue, err := broker.Initialize(context.Background(), "curated_episodes", messagebustypes.DirectionSendReceive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	archivist := archivists.StartEpisodesArchivist(ctx, queue, db)
+	if err := queue.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-archivist.Errors:
+		if !errors.Is(err, messagebustypes.ErrClosed) {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the archivist to report the closed queue")
+	}
+
+	select {
+	case <-archivist.Done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the archivist to stop once its queue is closed")
+	}
+}
@@ -0,0 +1,69 @@
+package archivists
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
+)
+
+// A LeaseReaperArchivist periodically releases research leases that have
+// expired. A lease expires if the researcher that held it stopped reporting
+// progress (for instance, if it crashed), and until the lease is released,
+// the leased work is never offered to another researcher.
+type LeaseReaperArchivist struct {
+	Errors    <-chan error
+	Reclaimed <-chan int
+	Done      <-chan struct{}
+}
+
+// StartLeaseReaperArchivist starts the archivist, which immediately releases
+// any expired leases, and then continues to do so once per interval. The
+// number of leased items that were reclaimed is reported via Reclaimed after
+// each pass. Unlike the other archivists, this archivist runs until ctx is
+// cancelled. The host must consume Errors and Reclaimed, or the archivist
+// will block.
+func StartLeaseReaperArchivist(ctx context.Context, db datastore.DataStorer, interval time.Duration) *LeaseReaperArchivist {
+	errorSource := make(chan error)
+	reclaimedSource := make(chan int)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(errorSource)
+		defer close(reclaimedSource)
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			reclaimed, err := db.ReapExpiredLeases(time.Now().UTC())
+			if err != nil {
+				select {
+				case errorSource <- fmt.Errorf("error reaping expired leases: %v", err):
+				case <-ctx.Done():
+					return
+				}
+			} else {
+				select {
+				case reclaimedSource <- reclaimed:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &LeaseReaperArchivist{
+		Errors:    errorSource,
+		Reclaimed: reclaimedSource,
+		Done:      done,
+	}
+}
@@ -0,0 +1,71 @@
+package archivists_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore/adapters/memadapter"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/archivists"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_LeaseReaperArchivistReclaimsExpiredLeases(t *testing.T) {
+	db := memadapter.New()
+	now := timestamppb.Now()
+	episode := &contracts.EpisodeInfo{
+		InitialDateCurated: now,
+		LastDateCurated:    now,
+		DateAired:          now,
+		Title:              "episode",
+		MediaUri:           "https://example.com/episode.mp3",
+	}
+	clip := &contracts.ClipInfo{
+		InitialDateCurated: now,
+		LastDateCurated:    now,
+		Title:              "clip",
+		MediaUri:           "https://example.com/clip.mp3",
+	}
+	if err := db.UpsertEpisodeInfo(episode); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpsertClipInfo(clip); err != nil {
+		t.Fatal(err)
+	}
+	leaseID := uuid.New()
+	err := db.CreateResearchLease(&leaseID, episode, []*contracts.ClipInfo{clip}, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reaper := archivists.StartLeaseReaperArchivist(ctx, db, time.Hour)
+
+	select {
+	case reclaimed := <-reaper.Reclaimed:
+		if reclaimed != 1 {
+			t.Fatalf("expected 1 lease to be reclaimed, got %v", reclaimed)
+		}
+	case err := <-reaper.Errors:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reaper")
+	}
+
+	got, err := db.GetHighestPriorityEpisode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Title != episode.Title {
+		t.Fatalf("expected the episode to be available for research, got %v", got)
+	}
+
+	cancel()
+	select {
+	case <-reaper.Done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reaper to stop")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/jecolasurdo/pacer"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/datastore"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/utils"
 	"google.golang.org/protobuf/proto"
@@ -107,7 +108,7 @@ func StartPendingResearchArchivist(ctx context.Context, messageBus messagebus.Se
 				return
 			}
 
-			err = messageBus.Send(messageBytes)
+			err = messageBus.SendEnvelope(messagebustypes.NewProtobufEnvelope(pendingResearchItem), messageBytes)
 			if err != nil {
 				errorSource <- fmt.Errorf("error while trying to push a pendingResearchItem to the message bus. %v %v", pendingResearchItem, err)
 				return
@@ -2,6 +2,7 @@ package curatorbase
 
 import (
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/curators/curatoriface"
 	"google.golang.org/protobuf/proto"
 )
@@ -40,7 +41,7 @@ poll:
 			if err != nil {
 				break poll
 			}
-			err = c.messageBus.Send(protoBytes)
+			err = c.messageBus.SendEnvelope(messagebustypes.NewProtobufEnvelope(result), protoBytes)
 			if err != nil {
 				break poll
 			}
@@ -2,16 +2,24 @@ package researcher
 
 import (
 	"context"
+	"errors"
 	"log"
-	"runtime"
+	"time"
 
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
 	"github.com/jecolasurdo/tbtlarchivist/go/internal/utils"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// DefaultHeartbeatInterval is how often a researcher reports that research is
+// underway, if not otherwise configured. It must be well within the duration
+// of a research lease.
+const DefaultHeartbeatInterval = 10 * time.Minute
+
 // A ResearchAgent is responsible for gathering a pending work item from the
 // pending work queue, spawning an Analyst sub-process, communicating with that
 // process, and reporting completed research results back to the completed work
@@ -47,57 +55,157 @@ func StartResearchAgent(ctx context.Context, pendingResearchQueue messagebus.Rec
 			return
 		}
 
-		pendingResearchItem := new(contracts.PendingResearchItem)
+		research(ctx, msg, completedWorkQueue, analyzer, DefaultHeartbeatInterval, errorSource)
+	}()
+
+	return &ResearchAgent{
+		Errors: errorSource,
+		Done:   done,
+	}
+}
+
+// research unmarshals a pending research item from msg, acknowledges it, and
+// runs analyzer against it, forwarding completed research to the completed
+// work queue, until the analyzer is done. While the analyzer runs, a
+// ResearchHeartbeat is sent to the completed work queue every
+// heartbeatInterval, so the item's lease is renewed even if no research is
+// completed for some time. Errors are sent to errorSource.
+//
+// If the analyzer reports that research of some clips failed (via an
+// analyst.ResearchError), a ResearchFailure is sent to the completed work
+// queue for those clips, so that they're released from the lease. Once the
+// analyzer is done, a transient ResearchFailure is sent for any clips that
+// were neither completed nor reported as failed.
+func research(ctx context.Context, msg *messagebustypes.Message, completedWorkQueue messagebus.Sender, analyzer analyst.Analyzer, heartbeatInterval time.Duration, errorSource chan<- error) {
+	pendingResearchItem := new(contracts.PendingResearchItem)
+	err := msg.CheckProtobuf(pendingResearchItem)
+	if err == nil {
 		err = proto.Unmarshal(msg.Body, pendingResearchItem)
+	}
+	if err != nil {
+		errorSource <- err
+		// A message that isn't understood or can't be unmarshalled
+		// will never succeed, so it's rejected (and dead-lettered)
+		// rather than requeued.
+		err := msg.Acknowledger.Nack(false)
 		if err != nil {
 			errorSource <- err
-			err := msg.Acknowledger.Nack(true)
-			if err != nil {
-				errorSource <- err
-			}
-			return
 		}
+		return
+	}
 
-		err = msg.Acknowledger.Ack()
-		if err != nil {
-			errorSource <- err
-			return
+	err = msg.Acknowledger.Ack()
+	if err != nil {
+		errorSource <- err
+		return
+	}
+
+	analyzer.Run(ctx, pendingResearchItem)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Clips are identified by title, as they are in the datastore. Each clip
+	// is resolved once it's completed or reported as failed.
+	resolved := make(map[string]bool)
+	unresolvedClips := func() []*contracts.ClipInfo {
+		clips := []*contracts.ClipInfo{}
+		for _, clip := range pendingResearchItem.GetClips() {
+			if !resolved[clip.GetTitle()] {
+				clips = append(clips, clip)
+			}
 		}
+		return clips
+	}
+	var unattributedErr error
+
+	// Each source is set to nil once it's closed, so the select no longer
+	// considers it.
+	completedWorkSrc, analystErrorSrc := analyzer.CompletedWorkItems(), analyzer.Errors()
+	for completedWorkSrc != nil || analystErrorSrc != nil {
+		select {
+		case completedWorkItem, open := <-completedWorkSrc:
+			if !open {
+				completedWorkSrc = nil
+				break
+			}
+			resolved[completedWorkItem.GetClipInfo().GetTitle()] = true
+			if err := sendProtobuf(completedWorkQueue, completedWorkItem); err != nil {
+				errorSource <- err
+			}
+		case analystErr, open := <-analystErrorSrc:
+			if !open {
+				analystErrorSrc = nil
+				break
+			}
+			if analystErr == nil {
+				break
+			}
+			errorSource <- analystErr
 
-		analyzer.Run(ctx, pendingResearchItem)
-
-		completedWorkSrcOpen, analystErrorSrcOpen := true, true
-		for completedWorkSrcOpen || analystErrorSrcOpen {
-			select {
-			case completedWorkItem, open := <-analyzer.CompletedWorkItems():
-				if !open {
-					completedWorkSrcOpen = false
-					break
-				}
-				cwiBytes, err := proto.Marshal(completedWorkItem)
-				if err != nil {
-					errorSource <- err
-					break
-				}
-				err = completedWorkQueue.Send(cwiBytes)
-				if err != nil {
-					errorSource <- err
-				}
-			case analystErr, open := <-analyzer.Errors():
-				if !open {
-					analystErrorSrcOpen = false
-					break
-				}
-				errorSource <- analystErr
-			default:
-				runtime.Gosched()
+			var researchErr *analyst.ResearchError
+			if !errors.As(analystErr, &researchErr) {
+				unattributedErr = analystErr
+				break
+			}
+			clips := researchErr.Clips
+			if clips == nil {
+				clips = unresolvedClips()
+			}
+			for _, clip := range clips {
+				resolved[clip.GetTitle()] = true
+			}
+			failureClass := contracts.FailureClass_TRANSIENT
+			if researchErr.Permanent() {
+				failureClass = contracts.FailureClass_PERMANENT
+			}
+			if err := sendResearchFailure(completedWorkQueue, pendingResearchItem, clips, failureClass, researchErr.FailedMedia(), researchErr.Error()); err != nil {
+				errorSource <- err
+			}
+		case <-heartbeat.C:
+			researchHeartbeat := &contracts.ResearchHeartbeat{
+				LeaseId:       pendingResearchItem.GetLeaseId(),
+				HeartbeatDate: timestamppb.Now(),
+			}
+			if err := sendProtobuf(completedWorkQueue, researchHeartbeat); err != nil {
+				errorSource <- err
 			}
 		}
+	}
 
-	}()
+	if clips := unresolvedClips(); len(clips) > 0 {
+		message := "the analyzer finished without researching the clips"
+		if unattributedErr != nil {
+			message = unattributedErr.Error()
+		}
+		if err := sendResearchFailure(completedWorkQueue, pendingResearchItem, clips, contracts.FailureClass_TRANSIENT, contracts.FailedMedia_UNATTRIBUTED, message); err != nil {
+			errorSource <- err
+		}
+	}
+}
 
-	return &ResearchAgent{
-		Errors: errorSource,
-		Done:   done,
+// sendResearchFailure reports that research of the supplied clips of
+// pendingResearchItem failed, attributing the failure to failedMedia.
+func sendResearchFailure(completedWorkQueue messagebus.Sender, pendingResearchItem *contracts.PendingResearchItem, clips []*contracts.ClipInfo, failureClass contracts.FailureClass, failedMedia contracts.FailedMedia, message string) error {
+	if len(clips) == 0 {
+		return nil
+	}
+	return sendProtobuf(completedWorkQueue, &contracts.ResearchFailure{
+		FailureDate:  timestamppb.Now(),
+		LeaseId:      pendingResearchItem.GetLeaseId(),
+		EpisodeInfo:  pendingResearchItem.GetEpisode(),
+		ClipInfos:    clips,
+		FailureClass: failureClass,
+		Message:      message,
+		FailedMedia:  failedMedia,
+	})
+}
+
+// sendProtobuf marshals m and sends it to queue in a protobuf envelope.
+func sendProtobuf(queue messagebus.Sender, m proto.Message) error {
+	body, err := proto.Marshal(m)
+	if err != nil {
+		return err
 	}
+	return queue.SendEnvelope(messagebustypes.NewProtobufEnvelope(m), body)
 }
@@ -60,7 +60,7 @@ func Test_AgentHappyPath(t *testing.T) {
 	close(doneSrc)
 
 	// completedQueue.Send behavior/expectations
-	completedQueue.EXPECT().Send(gomock.Any()).Return(nil).Times(0)
+	completedQueue.EXPECT().SendEnvelope(gomock.Any(), gomock.Any()).Return(nil).Times(0)
 
 	// Run SUT
 	researchAgent := researcher.StartResearchAgent(ctx, pendingQueue, completedQueue, analyst)
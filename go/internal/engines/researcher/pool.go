@@ -0,0 +1,115 @@
+package researcher
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/utils"
+)
+
+// receiveRetryInterval is how long a worker waits before trying to receive
+// again after the pending research queue returns an error.
+const receiveRetryInterval = 1 * time.Second
+
+// PoolConfig is a configuration for a ResearchPool.
+type PoolConfig struct {
+	// Concurrency is the number of pending research items that are researched
+	// at once. If zero, runtime.NumCPU() is used.
+	Concurrency int
+
+	// NewAnalyzer returns the Analyzer used to research a pending research
+	// item. It's called once for each item.
+	NewAnalyzer func() analyst.Analyzer
+
+	// HeartbeatInterval is how often each worker reports that its research is
+	// underway. If zero, DefaultHeartbeatInterval is used.
+	HeartbeatInterval time.Duration
+}
+
+// A ResearchPool runs a number of workers, each of which repeatedly consumes
+// a pending work item from the pending work queue, and researches it with its
+// own Analyzer, reporting the results to the completed work queue.
+type ResearchPool struct {
+	Errors <-chan error
+	Done   <-chan struct{}
+
+	drain context.CancelFunc
+}
+
+// StartResearchPool starts a pool of research workers. Each worker blocks
+// until a pending work item is available, researches it, then waits for
+// another, so the pool runs until it's drained, or until ctx is done.
+//
+// Drain stops the workers from consuming further work, but allows research
+// that is already underway to complete. If ctx is done, any research that is
+// underway is also abandoned. In either case, Done is closed once every
+// worker has stopped.
+func StartResearchPool(ctx context.Context, pendingResearchQueue messagebus.Receiver, completedWorkQueue messagebus.Sender, config *PoolConfig) *ResearchPool {
+	utils.PanicIfNil(pendingResearchQueue, completedWorkQueue, config.NewAnalyzer)
+
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	heartbeatInterval := config.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	receiveCtx, drain := context.WithCancel(ctx)
+	errorSource := make(chan error)
+	done := make(chan struct{})
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for receiveCtx.Err() == nil {
+				msg, err := pendingResearchQueue.ReceiveContext(receiveCtx)
+				if err != nil {
+					if receiveCtx.Err() != nil {
+						return
+					}
+					errorSource <- err
+					select {
+					case <-receiveCtx.Done():
+						return
+					case <-time.After(receiveRetryInterval):
+					}
+					continue
+				}
+
+				if msg == nil || len(msg.Body) == 0 {
+					continue
+				}
+
+				research(ctx, msg, completedWorkQueue, config.NewAnalyzer(), heartbeatInterval, errorSource)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		drain()
+		close(errorSource)
+		close(done)
+	}()
+
+	return &ResearchPool{
+		Errors: errorSource,
+		Done:   done,
+		drain:  drain,
+	}
+}
+
+// Drain stops the pool's workers from consuming further work. Research that
+// is already underway continues until it's complete.
+func (p *ResearchPool) Drain() {
+	p.drain()
+}
@@ -0,0 +1,335 @@
+package researcher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/analyst"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/contracts"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/engines/researcher"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/mocks/accessors/mock_messagebus"
+	"github.com/jecolasurdo/tbtlarchivist/go/internal/mocks/accessors/mock_messagebus/mock_acknowledger"
+	"google.golang.org/protobuf/proto"
+)
+
+// blockingAnalyzer signals when it starts running, then waits to be released
+// before completing one work item.
+type blockingAnalyzer struct {
+	started chan<- struct{}
+	release <-chan struct{}
+
+	completedWorkSrc chan *contracts.CompletedResearchItem
+	errSrc           chan error
+	doneSrc          chan struct{}
+}
+
+func (a *blockingAnalyzer) Run(ctx context.Context, pri *contracts.PendingResearchItem) {
+	a.completedWorkSrc = make(chan *contracts.CompletedResearchItem)
+	a.errSrc = make(chan error)
+	a.doneSrc = make(chan struct{})
+	go func() {
+		defer close(a.completedWorkSrc)
+		defer close(a.errSrc)
+		defer close(a.doneSrc)
+		a.started <- struct{}{}
+		<-a.release
+		a.completedWorkSrc <- &contracts.CompletedResearchItem{LeaseId: pri.LeaseId}
+	}()
+}
+
+func (a *blockingAnalyzer) Errors() <-chan error { return a.errSrc }
+
+func (a *blockingAnalyzer) CompletedWorkItems() <-chan *contracts.CompletedResearchItem {
+	return a.completedWorkSrc
+}
+
+func (a *blockingAnalyzer) Done() <-chan struct{} { return a.doneSrc }
+
+// newPendingQueue returns a Receiver that delivers each of the supplied
+// messages, then blocks until its context is done.
+func newPendingQueue(ctrl *gomock.Controller, msgs ...*messagebustypes.Message) *mock_messagebus.MockReceiver {
+	msgSrc := make(chan *messagebustypes.Message, len(msgs))
+	for _, msg := range msgs {
+		msgSrc <- msg
+	}
+
+	pendingQueue := mock_messagebus.NewMockReceiver(ctrl)
+	pendingQueue.EXPECT().ReceiveContext(gomock.Any()).DoAndReturn(func(ctx context.Context) (*messagebustypes.Message, error) {
+		select {
+		case msg := <-msgSrc:
+			return msg, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}).AnyTimes()
+	return pendingQueue
+}
+
+func newPendingMessage(ctrl *gomock.Controller, leaseID string) *messagebustypes.Message {
+	priBytes, err := proto.Marshal(&contracts.PendingResearchItem{LeaseId: leaseID})
+	if err != nil {
+		panic(err)
+	}
+	acknack := mock_acknowledger.NewMockAckNack(ctrl)
+	acknack.EXPECT().Ack().Times(1)
+	return &messagebustypes.Message{
+		Acknowledger: acknack,
+		Body:         priBytes,
+	}
+}
+
+func waitFor(t *testing.T, c <-chan struct{}, description string) {
+	t.Helper()
+	select {
+	case <-c:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %v", description)
+	}
+}
+
+func drainErrors(t *testing.T, pool *researcher.ResearchPool) {
+	go func() {
+		for err := range pool.Errors {
+			t.Error(err)
+		}
+	}()
+}
+
+func Test_PoolResearchesConcurrently(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pendingQueue := newPendingQueue(ctrl, newPendingMessage(ctrl, "a"), newPendingMessage(ctrl, "b"))
+	completedQueue := mock_messagebus.NewMockSender(ctrl)
+	completedQueue.EXPECT().SendEnvelope(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	pool := researcher.StartResearchPool(context.Background(), pendingQueue, completedQueue, &researcher.PoolConfig{
+		Concurrency: 2,
+		NewAnalyzer: func() analyst.Analyzer {
+			return &blockingAnalyzer{started: started, release: release}
+		},
+	})
+	drainErrors(t, pool)
+
+	// Neither analyzer is released until both have started, so this only
+	// succeeds if both items are researched at once.
+	waitFor(t, started, "the first analyzer to start")
+	waitFor(t, started, "the second analyzer to start")
+	close(release)
+
+	pool.Drain()
+	waitFor(t, pool.Done, "the pool to finish")
+}
+
+func Test_PoolDrainCompletesResearchUnderway(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pendingQueue := newPendingQueue(ctrl, newPendingMessage(ctrl, "a"))
+	completedQueue := mock_messagebus.NewMockSender(ctrl)
+	completedQueue.EXPECT().SendEnvelope(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	pool := researcher.StartResearchPool(context.Background(), pendingQueue, completedQueue, &researcher.PoolConfig{
+		Concurrency: 1,
+		NewAnalyzer: func() analyst.Analyzer {
+			return &blockingAnalyzer{started: started, release: release}
+		},
+	})
+	drainErrors(t, pool)
+
+	waitFor(t, started, "the analyzer to start")
+	pool.Drain()
+
+	select {
+	case <-pool.Done:
+		t.Fatal("the pool finished before the research underway was complete")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	waitFor(t, pool.Done, "the pool to finish")
+}
+
+func Test_PoolStopsWhenContextDone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pendingQueue := newPendingQueue(ctrl)
+	completedQueue := mock_messagebus.NewMockSender(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := researcher.StartResearchPool(ctx, pendingQueue, completedQueue, &researcher.PoolConfig{
+		NewAnalyzer: func() analyst.Analyzer { return nil },
+	})
+	drainErrors(t, pool)
+
+	cancel()
+	waitFor(t, pool.Done, "the pool to finish")
+}
+
+func Test_PoolSendsHeartbeatsWhileResearching(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pendingQueue := newPendingQueue(ctrl, newPendingMessage(ctrl, "a"))
+
+	heartbeats := make(chan *contracts.ResearchHeartbeat, 1)
+	completedQueue := mock_messagebus.NewMockSender(ctrl)
+	completedQueue.EXPECT().SendEnvelope(gomock.Any(), gomock.Any()).DoAndReturn(func(envelope *messagebustypes.Envelope, body []byte) error {
+		researchHeartbeat := new(contracts.ResearchHeartbeat)
+		if envelope.CheckProtobuf(researchHeartbeat) != nil {
+			return nil
+		}
+		if err := proto.Unmarshal(body, researchHeartbeat); err != nil {
+			t.Error(err)
+		}
+		select {
+		case heartbeats <- researchHeartbeat:
+		default:
+		}
+		return nil
+	}).MinTimes(2)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	pool := researcher.StartResearchPool(context.Background(), pendingQueue, completedQueue, &researcher.PoolConfig{
+		Concurrency:       1,
+		HeartbeatInterval: 10 * time.Millisecond,
+		NewAnalyzer: func() analyst.Analyzer {
+			return &blockingAnalyzer{started: started, release: release}
+		},
+	})
+	drainErrors(t, pool)
+
+	waitFor(t, started, "the analyzer to start")
+	select {
+	case researchHeartbeat := <-heartbeats:
+		if researchHeartbeat.GetLeaseId() != "a" {
+			t.Errorf("expected a heartbeat for lease a, got %v", researchHeartbeat.GetLeaseId())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a heartbeat")
+	}
+
+	close(release)
+	pool.Drain()
+	waitFor(t, pool.Done, "the pool to finish")
+}
+
+// failingAnalyzer reports a permanent failure of the first clip, then
+// finishes without researching the others.
+type failingAnalyzer struct {
+	completedWorkSrc chan *contracts.CompletedResearchItem
+	errSrc           chan error
+	doneSrc          chan struct{}
+}
+
+func (a *failingAnalyzer) Run(ctx context.Context, pri *contracts.PendingResearchItem) {
+	a.completedWorkSrc = make(chan *contracts.CompletedResearchItem)
+	a.errSrc = make(chan error)
+	a.doneSrc = make(chan struct{})
+	go func() {
+		defer close(a.completedWorkSrc)
+		defer close(a.errSrc)
+		defer close(a.doneSrc)
+		a.errSrc <- &analyst.ResearchError{
+			Clips:           pri.Clips[:1],
+			ClipMediaFailed: true,
+			Err:             analyst.Permanent(errors.New("unable to decode clip")),
+		}
+		a.errSrc <- errors.New("analyzer crashed")
+	}()
+}
+
+func (a *failingAnalyzer) Errors() <-chan error { return a.errSrc }
+
+func (a *failingAnalyzer) CompletedWorkItems() <-chan *contracts.CompletedResearchItem {
+	return a.completedWorkSrc
+}
+
+func (a *failingAnalyzer) Done() <-chan struct{} { return a.doneSrc }
+
+func Test_PoolReportsResearchFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	priBytes, err := proto.Marshal(&contracts.PendingResearchItem{
+		LeaseId: "a",
+		Episode: &contracts.EpisodeInfo{Title: "episode"},
+		Clips:   []*contracts.ClipInfo{{Title: "clip 1"}, {Title: "clip 2"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	acknack := mock_acknowledger.NewMockAckNack(ctrl)
+	acknack.EXPECT().Ack().Times(1)
+	pendingQueue := newPendingQueue(ctrl, &messagebustypes.Message{Acknowledger: acknack, Body: priBytes})
+
+	failures := make(chan *contracts.ResearchFailure, 2)
+	completedQueue := mock_messagebus.NewMockSender(ctrl)
+	completedQueue.EXPECT().SendEnvelope(gomock.Any(), gomock.Any()).DoAndReturn(func(envelope *messagebustypes.Envelope, body []byte) error {
+		researchFailure := new(contracts.ResearchFailure)
+		if err := envelope.CheckProtobuf(researchFailure); err != nil {
+			t.Error(err)
+		}
+		if err := proto.Unmarshal(body, researchFailure); err != nil {
+			t.Error(err)
+		}
+		failures <- researchFailure
+		return nil
+	}).Times(2)
+
+	pool := researcher.StartResearchPool(context.Background(), pendingQueue, completedQueue, &researcher.PoolConfig{
+		Concurrency: 1,
+		NewAnalyzer: func() analyst.Analyzer { return new(failingAnalyzer) },
+	})
+	var errs []error
+	errorsDrained := make(chan struct{})
+	go func() {
+		defer close(errorsDrained)
+		for err := range pool.Errors {
+			errs = append(errs, err)
+		}
+	}()
+
+	expectations := []struct {
+		clip         string
+		failureClass contracts.FailureClass
+		failedMedia  contracts.FailedMedia
+		message      string
+	}{
+		{"clip 1", contracts.FailureClass_PERMANENT, contracts.FailedMedia_CLIPS, "unable to decode clip"},
+		{"clip 2", contracts.FailureClass_TRANSIENT, contracts.FailedMedia_UNATTRIBUTED, "analyzer crashed"},
+	}
+	for _, expected := range expectations {
+		select {
+		case researchFailure := <-failures:
+			if researchFailure.GetLeaseId() != "a" || researchFailure.GetEpisodeInfo().GetTitle() != "episode" {
+				t.Errorf("expected a failure for lease a of episode, got %v", researchFailure)
+			}
+			if len(researchFailure.GetClipInfos()) != 1 || researchFailure.GetClipInfos()[0].GetTitle() != expected.clip {
+				t.Errorf("expected a failure of %v, got %v", expected.clip, researchFailure.GetClipInfos())
+			}
+			if researchFailure.GetFailureClass() != expected.failureClass || researchFailure.GetMessage() != expected.message {
+				t.Errorf("expected a %v failure %q, got %v %q", expected.failureClass, expected.message, researchFailure.GetFailureClass(), researchFailure.GetMessage())
+			}
+			if researchFailure.GetFailedMedia() != expected.failedMedia {
+				t.Errorf("expected the failure to be attributed to %v, got %v", expected.failedMedia, researchFailure.GetFailedMedia())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for the failure of %v", expected.clip)
+		}
+	}
+
+	pool.Drain()
+	waitFor(t, pool.Done, "the pool to finish")
+	waitFor(t, errorsDrained, "the pool's errors to be drained")
+	if len(errs) != 2 {
+		t.Errorf("expected both analyzer errors to be forwarded, got %v", errs)
+	}
+}
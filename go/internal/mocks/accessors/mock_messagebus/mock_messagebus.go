@@ -5,6 +5,7 @@
 package mock_messagebus
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	messagebustypes "github.com/jecolasurdo/tbtlarchivist/go/internal/accessors/messagebus/messagebustypes"
 	reflect "reflect"
@@ -47,6 +48,20 @@ func (mr *MockSenderMockRecorder) Send(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSender)(nil).Send), arg0)
 }
 
+// SendEnvelope mocks base method
+func (m *MockSender) SendEnvelope(arg0 *messagebustypes.Envelope, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEnvelope", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEnvelope indicates an expected call of SendEnvelope
+func (mr *MockSenderMockRecorder) SendEnvelope(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEnvelope", reflect.TypeOf((*MockSender)(nil).SendEnvelope), arg0, arg1)
+}
+
 // Inspect mocks base method
 func (m *MockSender) Inspect() (*messagebustypes.QueueInfo, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +115,21 @@ func (mr *MockReceiverMockRecorder) Receive() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receive", reflect.TypeOf((*MockReceiver)(nil).Receive))
 }
 
+// ReceiveContext mocks base method
+func (m *MockReceiver) ReceiveContext(ctx context.Context) (*messagebustypes.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveContext", ctx)
+	ret0, _ := ret[0].(*messagebustypes.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveContext indicates an expected call of ReceiveContext
+func (mr *MockReceiverMockRecorder) ReceiveContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveContext", reflect.TypeOf((*MockReceiver)(nil).ReceiveContext), ctx)
+}
+
 // MockSenderReceiver is a mock of SenderReceiver interface
 type MockSenderReceiver struct {
 	ctrl     *gomock.Controller
@@ -137,6 +167,20 @@ func (mr *MockSenderReceiverMockRecorder) Send(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSenderReceiver)(nil).Send), arg0)
 }
 
+// SendEnvelope mocks base method
+func (m *MockSenderReceiver) SendEnvelope(arg0 *messagebustypes.Envelope, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEnvelope", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEnvelope indicates an expected call of SendEnvelope
+func (mr *MockSenderReceiverMockRecorder) SendEnvelope(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEnvelope", reflect.TypeOf((*MockSenderReceiver)(nil).SendEnvelope), arg0, arg1)
+}
+
 // Inspect mocks base method
 func (m *MockSenderReceiver) Inspect() (*messagebustypes.QueueInfo, error) {
 	m.ctrl.T.Helper()
@@ -166,3 +210,18 @@ func (mr *MockSenderReceiverMockRecorder) Receive() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receive", reflect.TypeOf((*MockSenderReceiver)(nil).Receive))
 }
+
+// ReceiveContext mocks base method
+func (m *MockSenderReceiver) ReceiveContext(ctx context.Context) (*messagebustypes.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveContext", ctx)
+	ret0, _ := ret[0].(*messagebustypes.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveContext indicates an expected call of ReceiveContext
+func (mr *MockSenderReceiverMockRecorder) ReceiveContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveContext", reflect.TypeOf((*MockSenderReceiver)(nil).ReceiveContext), ctx)
+}